@@ -0,0 +1,227 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// Autosave keeps a checkpoint commit of your turn on the hidden ref refs/mob/autosave/<user>.
+// The checkpoint sits on top of the wip branch head but is never part of the wip branch, see mob restore.
+
+var autosaveSleep = time.Sleep
+
+func autosaveRef(runner GitRunner) string {
+	user := regexp.MustCompile(`[^a-z0-9._-]+`).ReplaceAllString(strings.ToLower(gitUserName(runner)), "-")
+	if user == "" {
+		user = "unknown"
+	}
+	return "refs/mob/autosave/" + user
+}
+
+func autosavePidPath(runner GitRunner) (string, error) {
+	gitDir, err := gitDir(runner)
+	return gitDir + "/mob-autosave", err
+}
+
+func startAutosave(runner GitRunner, configuration config.Configuration) {
+	if configuration.AutosaveInterval == "" {
+		return
+	}
+	if err := executeCommandsInBackgroundProcess(runner, configuration.Mob("autosave")); err != nil {
+		say.Warning("autosave couldn't be started: " + err.Error())
+	}
+}
+
+// autosave creates a checkpoint every MOB_AUTOSAVE_INTERVAL minutes until you leave the wip branch
+func autosave(runner GitRunner, configuration config.Configuration) error {
+	err, minutes := toMinutes(configuration.AutosaveInterval)
+	if err != nil {
+		return newMobError(ErrInvalidArgument, "autosave needs MOB_AUTOSAVE_INTERVAL in minutes",
+			Fix{"To autosave every 5 minutes, use", "export MOB_AUTOSAVE_INTERVAL=5"})
+	}
+	mobProgramming, err := isMobProgramming(runner, configuration)
+	if err != nil {
+		return err
+	}
+	if !mobProgramming {
+		return errNotMobProgramming(configuration)
+	}
+
+	// a newer autosave of the same repository takes over
+	pid := strconv.Itoa(os.Getpid())
+	pidPath, err := autosavePidPath(runner)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(pidPath, []byte(pid), 0644); err != nil {
+		return &MobError{Kind: err, Message: "autosave couldn't be started", Details: []string{err.Error()}}
+	}
+	wipBranch, err := gitCurrentBranch(runner)
+	if err != nil {
+		return err
+	}
+	say.Info("autosaving every " + strconv.Itoa(minutes) + " minutes to " + autosaveRef(runner))
+
+	for {
+		autosaveSleep(time.Duration(minutes) * time.Minute)
+		if owner, _ := os.ReadFile(pidPath); string(owner) != pid {
+			return nil
+		}
+		currentBranch, err := gitCurrentBranch(runner)
+		if err != nil {
+			return err
+		}
+		if currentBranch != wipBranch {
+			say.Info("stopped autosave, you left " + wipBranch.String())
+			os.Remove(pidPath)
+			return nil
+		}
+		if hasOperationInProgress(runner) {
+			continue
+		}
+		if _, err := checkpoint(runner, configuration); err != nil {
+			return err
+		}
+	}
+}
+
+// checkpoint commits the changes in the working tree to the autosave ref, staged like the wip commit. It uses a
+// separate index, so the index you see and the wip branch stay untouched. Returns the new checkpoint or "" if nothing changed.
+func checkpoint(runner GitRunner, configuration config.Configuration) (string, error) {
+	gitDir, err := gitDir(runner)
+	if err != nil {
+		return "", err
+	}
+	indexFile := gitDir + "/mob-autosave-index"
+	defer os.Remove(indexFile)
+	indexRunner := runner.WithEnv("GIT_INDEX_FILE=" + indexFile)
+	if _, err := silentgitChange(indexRunner, "read-tree", "HEAD"); err != nil {
+		return "", err
+	}
+	if err := stageChanges(indexRunner); err != nil {
+		return "", err
+	}
+	tree, err := silentgitChange(indexRunner, "write-tree")
+	if err != nil {
+		return "", err
+	}
+
+	head := gitCommitHash(runner)
+	if tree == gitRefHash(runner, "HEAD^{tree}") {
+		return "", nil
+	}
+	ref := autosaveRef(runner)
+	if lastCheckpoint := gitRefHash(runner, ref); lastCheckpoint != "" && gitRefHash(runner, lastCheckpoint+"^{tree}") == tree && gitRefHash(runner, lastCheckpoint+"^") == head {
+		return "", nil
+	}
+
+	if configuration.GitHooksEnabled && !preCommitHookPasses(runner, indexFile) {
+		return "", nil
+	}
+
+	currentBranch, err := gitCurrentBranch(runner)
+	if err != nil {
+		return "", err
+	}
+	message := "mob autosave on " + currentBranch.String() + " at " + time.Now().Format(time.RFC3339)
+	commit, err := silentgitChange(runner, "commit-tree", tree, "-p", head, "-m", message)
+	if err != nil {
+		return "", err
+	}
+	if _, err := silentgitChange(runner, "update-ref", ref, commit); err != nil {
+		return "", err
+	}
+	say.Debug("autosaved " + commit + " to " + ref)
+	if configuration.AutosavePush {
+		if _, err := silentgitChange(runner, deleteEmptyStrings([]string{"push", "--force", gitHooksOption(configuration), configuration.RemoteName, ref + ":" + ref})...); err != nil {
+			return "", err
+		}
+	}
+	return commit, nil
+}
+
+// preCommitHookPasses runs the pre-commit hook on the changes in indexFile, like the wip commit does with MOB_GIT_HOOKS_ENABLED
+func preCommitHookPasses(runner GitRunner, indexFile string) bool {
+	hooksDir, err := gitHooksDir(runner)
+	if err != nil {
+		return true
+	}
+	path := filepath.Join(hooksDir, "pre-commit")
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || (runtime.GOOS != "windows" && info.Mode()&0111 == 0) {
+		return true
+	}
+	if DryRun {
+		say.Indented("run the pre-commit hook <" + path + ">")
+		return true
+	}
+	output := ""
+	rootDir, err := gitRootDir(runner)
+	if err == nil {
+		_, output, err = runCommandSilent(rootDir, []string{"GIT_INDEX_FILE=" + indexFile}, path)
+	}
+	if err != nil {
+		say.Warning("skipped the autosave because the pre-commit hook failed: " + strings.TrimSpace(output))
+		return false
+	}
+	return true
+}
+
+// restore applies the last checkpoint as uncommitted changes, fetching it from the remote if it is not available locally
+func restore(runner GitRunner, configuration config.Configuration) error {
+	if err := failIfOperationInProgress(runner, configuration); err != nil {
+		return err
+	}
+	mobProgramming, err := isMobProgramming(runner, configuration)
+	if err != nil {
+		return err
+	}
+	if !mobProgramming {
+		return errNotMobProgramming(configuration)
+	}
+	uncommittedChanges, err := hasUncommittedChanges(runner)
+	if err != nil {
+		return err
+	}
+	if uncommittedChanges {
+		return newMobError(ErrDirtyWorkingTree, "cannot restore; clean working tree required",
+			Fix{"To keep your changes, commit or stash them and try again", "git stash"})
+	}
+
+	ref := autosaveRef(runner)
+	checkpointHash := gitRefHash(runner, ref)
+	if checkpointHash == "" {
+		if _, _, err := runner.Query("ls-remote", "--exit-code", configuration.RemoteName, ref); err != nil {
+			say.Info("nothing to restore, there is no autosave in " + ref)
+			return nil
+		}
+		if err := git(runner, "fetch", configuration.RemoteName, "+"+ref+":"+ref); err != nil {
+			return err
+		}
+		checkpointHash = gitRefHash(runner, ref)
+	}
+
+	if checkpointHash != "" { // a dry run didn't fetch the autosave, so there is nothing to describe
+		description, err := silentgit(runner, "log", "-1", "--pretty=format:%s (%cr)", checkpointHash)
+		if err != nil {
+			return err
+		}
+		say.Info("restoring '" + description + "'")
+	}
+	if err := git(runner, "cherry-pick", "--no-commit", ref); err != nil {
+		return err
+	}
+	if err := git(runner, "reset", "--quiet"); err != nil {
+		return err
+	}
+	say.Info("restored the autosave as uncommitted changes")
+	return nil
+}
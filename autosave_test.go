@@ -0,0 +1,175 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+)
+
+func TestCheckpointKeepsIndexAndWipBranch(t *testing.T) {
+	_, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "staged.txt", "contentIrrelevant")
+	git(gitRunner, "add", "staged.txt")
+	createFile(t, "untracked.txt", "contentIrrelevant")
+	wipHead := gitCommitHash(gitRunner)
+
+	commit := createCheckpoint(t, configuration)
+
+	equals(t, commit, gitRefHash(gitRunner, "refs/mob/autosave/local"))
+	equals(t, wipHead, gitCommitHash(gitRunner))
+	equals(t, wipHead, gitOutput(t, "rev-parse", commit+"^"))
+	equals(t, "staged.txt\nuntracked.txt", gitOutput(t, "diff", "--name-only", wipHead, commit))
+	assertGitStatus(t, GitStatus{
+		"staged.txt":    "A",
+		"untracked.txt": "??",
+	})
+}
+
+func TestCheckpointNothingChanged(t *testing.T) {
+	_, configuration := setup(t)
+	start(gitRunner, configuration)
+
+	equals(t, "", createCheckpoint(t, configuration))
+	createFile(t, "example.txt", "contentIrrelevant")
+	commit := createCheckpoint(t, configuration)
+	equals(t, "", createCheckpoint(t, configuration))
+
+	equals(t, commit, gitRefHash(gitRunner, "refs/mob/autosave/local"))
+}
+
+func TestCheckpointRunsPreCommitHookIfEnabled(t *testing.T) {
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+	os.MkdirAll(gitHooksDirectory(t), 0755)
+	os.WriteFile(gitHooksDirectory(t)+"/pre-commit", []byte("#!/bin/sh\necho not formatted\nexit 1\n"), 0755)
+	createFile(t, "example.txt", "contentIrrelevant")
+
+	configuration.GitHooksEnabled = true
+	equals(t, "", createCheckpoint(t, configuration))
+	assertOutputContains(t, output, "skipped the autosave because the pre-commit hook failed: not formatted")
+
+	configuration.GitHooksEnabled = false
+	commit := createCheckpoint(t, configuration)
+	equals(t, "example.txt", gitOutput(t, "diff", "--name-only", "HEAD", commit))
+}
+
+func TestRestoreOnAnotherMachine(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.AutosavePush = true
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	createFile(t, "test.txt", "changed")
+	createCheckpoint(t, configuration)
+
+	setWorkingDir(tempDir + "/localother")
+	git(gitRunner, "config", "--local", "user.name", "local")
+	start(gitRunner, configuration)
+	assertNoError(t, restore(gitRunner, configuration))
+
+	assertOnBranch(t, "mob-session")
+	assertGitStatus(t, GitStatus{
+		"example.txt": "??",
+		"test.txt":    "M",
+	})
+	assertOutputContains(t, output, "restored the autosave as uncommitted changes")
+}
+
+func TestRestoreOnAnotherMachineDryRun(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.AutosavePush = true
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	createCheckpoint(t, configuration)
+
+	setWorkingDir(tempDir + "/localother")
+	git(gitRunner, "config", "--local", "user.name", "local")
+	start(gitRunner, configuration)
+	enableDryRun(t)
+	assertNoError(t, restore(gitRunner, configuration))
+
+	assertOutputContains(t, output, "git fetch origin +refs/mob/autosave/local:refs/mob/autosave/local")
+	assertOutputContains(t, output, "git cherry-pick --no-commit refs/mob/autosave/local")
+	equals(t, "", gitRefHash(gitRunner, "refs/mob/autosave/local"))
+	assertGitStatus(t, GitStatus{})
+}
+
+func TestRestoreNothingToRestore(t *testing.T) {
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+
+	assertNoError(t, restore(gitRunner, configuration))
+
+	assertOutputContains(t, output, "nothing to restore, there is no autosave in refs/mob/autosave/local")
+}
+
+func TestRestoreRequiresCleanWorkingTree(t *testing.T) {
+	_, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	createCheckpoint(t, configuration)
+
+	err := restore(gitRunner, configuration)
+
+	assertErrorIs(t, err, ErrDirtyWorkingTree)
+}
+
+func TestAutosaveUntilLeavingWipBranch(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.AutosaveInterval = "5"
+	start(gitRunner, configuration)
+	sleeps := 0
+	originalAutosaveSleep := autosaveSleep
+	autosaveSleep = func(duration time.Duration) {
+		sleeps++
+		equals(t, 5*time.Minute, duration)
+		switch sleeps {
+		case 1:
+			createFile(t, "example.txt", "contentIrrelevant")
+		case 3:
+			next(gitRunner, configuration)
+		}
+	}
+	defer func() { autosaveSleep = originalAutosaveSleep }()
+
+	assertNoError(t, autosave(gitRunner, configuration))
+
+	equals(t, 3, sleeps)
+	assertOutputContains(t, output, "stopped autosave, you left mob-session")
+	equals(t, "example.txt", gitOutput(t, "diff", "--name-only", "refs/mob/autosave/local^", "refs/mob/autosave/local"))
+}
+
+func TestCheckpointDryRun(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.AutosavePush = true
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	enableDryRun(t)
+
+	commit := createCheckpoint(t, configuration)
+
+	equals(t, "<commit>", commit)
+	assertOutputContains(t, output, "git commit-tree <tree> -p "+gitRefHash(gitRunner, "HEAD")+" -m mob autosave on mob-session")
+	assertOutputContains(t, output, "git update-ref refs/mob/autosave/local <commit>")
+	assertOutputContains(t, output, "git push --force --no-verify origin refs/mob/autosave/local:refs/mob/autosave/local")
+	equals(t, "", gitRefHash(gitRunner, "refs/mob/autosave/local"))
+}
+
+func TestAutosaveNeedsInterval(t *testing.T) {
+	_, configuration := setup(t)
+	start(gitRunner, configuration)
+
+	err := autosave(gitRunner, configuration)
+
+	assertErrorIs(t, err, ErrInvalidArgument)
+	assertFix(t, err, "export MOB_AUTOSAVE_INTERVAL=5")
+}
+
+func createCheckpoint(t *testing.T, configuration config.Configuration) string {
+	t.Helper()
+	commit, err := checkpoint(gitRunner, configuration)
+	assertNoError(t, err)
+	return commit
+}
@@ -14,7 +14,7 @@ import (
 // Author is a coauthor "Full Name <email>"
 type Author = string
 
-func collectCoauthorsFromWipCommits(file *os.File) []Author {
+func collectCoauthorsFromWipCommits(runner GitRunner, file *os.File) ([]Author, error) {
 	// Here we parse the SQUASH_MSG file for the list of authors on
 	// the WIP branch.  If this technique later turns out to be
 	// problematic, an alternative would be to instead fetch the
@@ -28,7 +28,11 @@ func collectCoauthorsFromWipCommits(file *os.File) []Author {
 	say.Debug("Parsed coauthors")
 	say.Debug(strings.Join(coauthors, ","))
 
-	coauthors = removeElementsContaining(coauthors, gitUserEmail())
+	userEmail, err := gitUserEmail(runner)
+	if err != nil {
+		return nil, err
+	}
+	coauthors = removeElementsContaining(coauthors, userEmail)
 	say.Debug("Parsed coauthors without committer")
 	say.Debug(strings.Join(coauthors, ","))
 
@@ -40,7 +44,7 @@ func collectCoauthorsFromWipCommits(file *os.File) []Author {
 	say.Debug("Sorted unique coauthors without committer")
 	say.Debug(strings.Join(coauthors, ","))
 
-	return coauthors
+	return coauthors, nil
 }
 
 func parseCoauthors(file *os.File) []Author {
@@ -93,7 +97,7 @@ func removeDuplicateValues(slice []string) []string {
 	return result
 }
 
-func appendCoauthorsToSquashMsg(gitDir string) error {
+func appendCoauthorsToSquashMsg(runner GitRunner, gitDir string) error {
 	squashMsgPath := path.Join(gitDir, "SQUASH_MSG")
 	say.Debug("opening " + squashMsgPath)
 	file, err := os.OpenFile(squashMsgPath, os.O_APPEND|os.O_RDWR, 0644)
@@ -109,7 +113,10 @@ func appendCoauthorsToSquashMsg(gitDir string) error {
 	defer file.Close()
 
 	// read from repo/.git/SQUASH_MSG
-	coauthors := collectCoauthorsFromWipCommits(file)
+	coauthors, err := collectCoauthorsFromWipCommits(runner, file)
+	if err != nil {
+		return err
+	}
 
 	if len(coauthors) > 0 {
 		coauthorSuffix := createCommitMessage(coauthors)
@@ -123,12 +130,37 @@ func appendCoauthorsToSquashMsg(gitDir string) error {
 	return err
 }
 
+func hasSquashMsg(gitDir string) bool {
+	_, err := os.Stat(path.Join(gitDir, "SQUASH_MSG"))
+	return err == nil
+}
+
+func coauthorsFromSquashMsg(runner GitRunner, gitDir string) ([]Author, error) {
+	squashMsgPath := path.Join(gitDir, "SQUASH_MSG")
+	file, err := os.Open(squashMsgPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	return collectCoauthorsFromWipCommits(runner, file)
+}
+
 func createCommitMessage(coauthors []Author) string {
 	commitMessage := "\n\n"
 	commitMessage += "# automatically added all co-authors from WIP commits\n"
 	commitMessage += "# add missing co-authors manually\n"
+	commitMessage += coauthorTrailers(coauthors)
+	return commitMessage
+}
+
+func coauthorTrailers(coauthors []Author) string {
+	trailers := ""
 	for _, coauthor := range coauthors {
-		commitMessage += fmt.Sprintf("Co-authored-by: %s\n", coauthor)
+		trailers += fmt.Sprintf("Co-authored-by: %s\n", coauthor)
 	}
-	return commitMessage
+	return trailers
 }
@@ -9,33 +9,33 @@ func TestStartDoneCoAuthors(t *testing.T) {
 	_, configuration := setup(t)
 
 	setWorkingDir(tempDir + "/alice")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file3.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/local")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file1.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/localother")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file2.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/alice")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file4.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/bob")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file5.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/local")
-	start(configuration)
-	done(configuration)
+	start(gitRunner, configuration)
+	done(gitRunner, configuration)
 
 	output := readFile(t, filepath.Join(tempDir, "local", ".git", "SQUASH_MSG"))
 
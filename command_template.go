@@ -0,0 +1,152 @@
+package main
+
+import (
+	"runtime"
+	"strings"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+)
+
+// MOB_VOICE_COMMAND, MOB_NOTIFY_COMMAND and MOB_OPEN_COMMAND are templates with the named placeholders {message}, {file},
+// {line}, {column}, {user}, {room} and {branch}. The older %s, %l and %c still work. Templates are read like a POSIX shell
+// reads a command line, so a value is inserted as exactly one argument, whatever spaces or quotes it contains.
+
+var legacyPlaceholders = map[byte]string{'l': "line", 'c': "column"}
+
+var lazyPlaceholders = map[string]func(GitRunner, config.Configuration) string{
+	"user": func(runner GitRunner, _ config.Configuration) string { return gitUserName(runner) },
+	"room": getMobTimerRoom,
+	"branch": func(runner GitRunner, _ config.Configuration) string {
+		currentBranch, _ := gitCurrentBranch(runner)
+		return currentBranch.String()
+	},
+}
+
+// posixShell is false on Windows, where backslashes separate paths and voice and notify commands are PowerShell,
+// so values are inserted unchanged
+var posixShell = runtime.GOOS != "windows"
+
+// renderCommand replaces the placeholders and returns the command as arguments and as a command line for sh -c.
+// %s stands for mainPlaceholder, whose value is appended if the template doesn't use it.
+func renderCommand(runner GitRunner, template string, mainPlaceholder string, values map[string]string, configuration config.Configuration) (args []string, commandLine string, err error) {
+	value := func(name string) (string, bool) {
+		if v, ok := values[name]; ok {
+			return v, true
+		}
+		if lazy, ok := lazyPlaceholders[name]; ok {
+			values[name] = lazy(runner, configuration)
+			return values[name], true
+		}
+		return "", false
+	}
+
+	var line strings.Builder
+	var arg strings.Builder
+	inArg := false
+	usedMainPlaceholder := false
+	quote := byte(0) // the quote we are in: 0, '\'' or '"'
+	finishArg := func() {
+		if inArg {
+			args = append(args, arg.String())
+			arg.Reset()
+			inArg = false
+		}
+	}
+
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+
+		if name, length := placeholderAt(template, i, mainPlaceholder); length > 0 {
+			if v, ok := value(name); ok {
+				usedMainPlaceholder = usedMainPlaceholder || name == mainPlaceholder
+				arg.WriteString(v)
+				line.WriteString(escapeForShell(v, quote))
+				inArg = true
+				i += length - 1
+				continue
+			}
+		}
+
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				arg.WriteByte(c)
+			}
+			line.WriteByte(c)
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			} else if c == '\\' && i+1 < len(template) && strings.IndexByte("$`\"\\", template[i+1]) >= 0 {
+				line.WriteByte(c)
+				i++
+				c = template[i]
+				arg.WriteByte(c)
+			} else {
+				arg.WriteByte(c)
+			}
+			line.WriteByte(c)
+		case c == '\'' || c == '"':
+			quote = c
+			inArg = true
+			line.WriteByte(c)
+		case c == '\\' && posixShell && i+1 < len(template):
+			line.WriteByte(c)
+			i++
+			arg.WriteByte(template[i])
+			line.WriteByte(template[i])
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\n':
+			finishArg()
+			line.WriteByte(c)
+		default:
+			arg.WriteByte(c)
+			line.WriteByte(c)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, "", newMobError(ErrInvalidArgument, "the command '"+template+"' has an unterminated "+string(quote)+" quote")
+	}
+	finishArg()
+
+	if !usedMainPlaceholder {
+		v, _ := value(mainPlaceholder)
+		args = append(args, v)
+		line.WriteString(" " + escapeForShell(v, 0))
+	}
+	return args, line.String(), nil
+}
+
+// placeholderAt returns the name and the length of the placeholder starting at position i, or a length of 0
+func placeholderAt(template string, i int, mainPlaceholder string) (name string, length int) {
+	if template[i] == '%' && i+1 < len(template) {
+		if template[i+1] == 's' {
+			return mainPlaceholder, 2
+		}
+		if name, ok := legacyPlaceholders[template[i+1]]; ok {
+			return name, 2
+		}
+	}
+	if template[i] == '{' {
+		if end := strings.IndexByte(template[i:], '}'); end > 1 {
+			return template[i+1 : i+end], end + 1
+		}
+	}
+	return "", 0
+}
+
+func escapeForShell(value string, quote byte) string {
+	if !posixShell {
+		return value
+	}
+	switch quote {
+	case '\'':
+		return strings.ReplaceAll(value, "'", `'\''`)
+	case '"':
+		return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`").Replace(value)
+	default:
+		return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+	}
+}
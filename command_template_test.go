@@ -0,0 +1,91 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+)
+
+func TestRenderCommandWithNamedPlaceholders(t *testing.T) {
+	configuration := config.GetDefaultConfiguration()
+
+	args, _, _ := renderCommand(gitRunner, `code -g "{file}:{line}:{column}"`, "file", map[string]string{"file": "/a dir/it's.txt", "line": "3", "column": "7"}, configuration)
+
+	equals(t, []string{"code", "-g", "/a dir/it's.txt:3:7"}, args)
+}
+
+func TestRenderCommandWithQuotedArguments(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("templates are not read like a POSIX shell on Windows")
+	}
+	configuration := config.GetDefaultConfiguration()
+
+	args, _, _ := renderCommand(gitRunner, `/usr/bin/osascript -e 'display notification "{message}"' --flag\ with\ spaces "a \"b\""`, "message", map[string]string{"message": "mob next"}, configuration)
+
+	equals(t, []string{"/usr/bin/osascript", "-e", `display notification "mob next"`, "--flag with spaces", `a "b"`}, args)
+}
+
+func TestRenderCommandWithLegacyPlaceholders(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("templates are not read like a POSIX shell on Windows")
+	}
+	configuration := config.GetDefaultConfiguration()
+
+	args, commandLine, _ := renderCommand(gitRunner, `idea --line %l %s`, "file", map[string]string{"file": "/a dir/file.txt", "line": "12"}, configuration)
+
+	equals(t, []string{"idea", "--line", "12", "/a dir/file.txt"}, args)
+	equals(t, `idea --line '12' '/a dir/file.txt'`, commandLine)
+}
+
+func TestRenderCommandAppendsMainPlaceholder(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("templates are not read like a POSIX shell on Windows")
+	}
+	configuration := config.GetDefaultConfiguration()
+
+	args, commandLine, _ := renderCommand(gitRunner, `say`, "message", map[string]string{"message": "it's mob next"}, configuration)
+
+	equals(t, []string{"say", "it's mob next"}, args)
+	equals(t, `say 'it'\''s mob next'`, commandLine)
+}
+
+func TestRenderCommandEscapesValuesForTheShell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("templates are not read like a POSIX shell on Windows")
+	}
+	configuration := config.GetDefaultConfiguration()
+	message := map[string]string{"message": "$(rm -rf /) \"quoted\" 'single'"}
+
+	_, doubleQuoted, _ := renderCommand(gitRunner, `say "{message}"`, "message", message, configuration)
+	_, singleQuoted, _ := renderCommand(gitRunner, `say '{message}'`, "message", message, configuration)
+
+	equals(t, `say "\$(rm -rf /) \"quoted\" 'single'"`, doubleQuoted)
+	equals(t, `say '$(rm -rf /) "quoted" '\''single'\'''`, singleQuoted)
+	equals(t, "$(rm -rf /) \"quoted\" 'single'\n$(rm -rf /) \"quoted\" 'single'\n", runShell(t, "printf '%s\\n' "+doubleQuoted[4:]+" "+singleQuoted[4:]))
+}
+
+func TestRenderCommandWithUserRoomAndBranch(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.TimerRoom = "testroom"
+
+	args, _, _ := renderCommand(gitRunner, `notify {user}@{room} on {branch} {unknown} ${HOME}`, "message", map[string]string{"message": "mob next"}, configuration)
+
+	equals(t, []string{"notify", "local@testroom", "on", "master", "{unknown}", "${HOME}", "mob next"}, args)
+}
+
+func TestRenderCommandWithUnterminatedQuote(t *testing.T) {
+	configuration := config.GetDefaultConfiguration()
+
+	_, _, err := renderCommand(gitRunner, `say "{message}`, "message", map[string]string{"message": "mob next"}, configuration)
+
+	assertErrorIs(t, err, ErrInvalidArgument)
+}
+
+func runShell(t *testing.T, commandLine string) string {
+	_, output, err := runCommandSilent("", nil, "sh", "-c", commandLine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return output
+}
@@ -2,6 +2,7 @@ package configuration
 
 import (
 	"bufio"
+	"encoding/json"
 	"github.com/remotemobprogramming/mob/v5/say"
 	"os"
 	"runtime"
@@ -15,6 +16,17 @@ const (
 	SquashWip = "squash-wip"
 )
 
+const (
+	HandoverWipBranch = "wip-branch"
+	HandoverStash     = "stash"
+)
+
+const (
+	NotifyBackendAuto    = "auto"
+	NotifyBackendDBus    = "dbus"
+	NotifyBackendCommand = "command"
+)
+
 const (
 	IncludeChanges = "include-changes"
 	DiscardChanges = "discard-changes"
@@ -33,7 +45,13 @@ type Configuration struct {
 	VoiceMessage                   string // override with MOB_VOICE_MESSAGE
 	NotifyCommand                  string // override with MOB_NOTIFY_COMMAND
 	NotifyMessage                  string // override with MOB_NOTIFY_MESSAGE
+	NotifyBackend                  string // override with MOB_NOTIFY_BACKEND
 	NextStay                       bool   // override with MOB_NEXT_STAY
+	NextNote                       string
+	NextNoteEdit                   bool
+	NextVerifyCommand              string // override with MOB_NEXT_VERIFY_COMMAND
+	NextNoVerifyRun                bool
+	NextVerifyResult               string
 	HandleUncommittedChanges       string
 	StartCreate                    bool // override with MOB_START_CREATE variable
 	StartJoin                      bool
@@ -42,7 +60,29 @@ type Configuration struct {
 	WipBranchQualifierSeparator    string // override with MOB_WIP_BRANCH_QUALIFIER_SEPARATOR
 	WipBranchPrefix                string // override with MOB_WIP_BRANCH_PREFIX
 	DoneSquash                     string // override with MOB_DONE_SQUASH
+	Handover                       string // override with MOB_HANDOVER
+	HandoverState                  string // override with MOB_HANDOVER_STATE
+	DoneCommit                     bool
+	DoneCommitMessage              string
+	DonePush                       bool
+	DoneContinue                   bool
+	DoneAbort                      bool
+	DonePullRequest                bool   // override with MOB_DONE_PULL_REQUEST
+	PullRequestProvider            string // override with MOB_PULL_REQUEST_PROVIDER
+	PullRequestUrl                 string // override with MOB_PULL_REQUEST_URL
+	PullRequestToken               string // override with MOB_PULL_REQUEST_TOKEN
+	PullRequestCommand             string // override with MOB_PULL_REQUEST_COMMAND
+	PullRequestBranchPrefix        string // override with MOB_PULL_REQUEST_BRANCH_PREFIX
 	OpenCommand                    string // override with MOB_OPEN_COMMAND
+	HookPreStart                   string // override with MOB_HOOK_PRE_START
+	HookPostStart                  string // override with MOB_HOOK_POST_START
+	HookPreNext                    string // override with MOB_HOOK_PRE_NEXT
+	HookPostNext                   string // override with MOB_HOOK_POST_NEXT
+	HookPreDone                    string // override with MOB_HOOK_PRE_DONE
+	HookPostDone                   string // override with MOB_HOOK_POST_DONE
+	HookPreReset                   string // override with MOB_HOOK_PRE_RESET
+	HookPostReset                  string // override with MOB_HOOK_POST_RESET
+	HookTimer                      string // override with MOB_HOOK_TIMER
 	Timer                          string // override with MOB_TIMER
 	TimerRoom                      string // override with MOB_TIMER_ROOM
 	TimerLocal                     bool   // override with MOB_TIMER_LOCAL
@@ -51,6 +91,13 @@ type Configuration struct {
 	TimerUrl                       string // override with MOB_TIMER_URL
 	TimerInsecure                  bool   // override with MOB_TIMER_INSECURE
 	ResetDeleteRemoteWipBranch     bool   // override with MOB_RESET_DELETE_REMOTE_WIP_BRANCH
+	AutosaveInterval               string // override with MOB_AUTOSAVE_INTERVAL
+	AutosavePush                   bool   // override with MOB_AUTOSAVE_PUSH
+	WebhookUrl                     string // override with MOB_WEBHOOK_URL
+	WebhookFormat                  string // override with MOB_WEBHOOK_FORMAT
+	DryRun                         bool
+	OutputJson                     bool
+	WaitStart                      bool
 }
 
 func (c Configuration) Mob(command string) string {
@@ -77,14 +124,60 @@ func (c Configuration) IsOpenCommandGiven() bool {
 	return strings.TrimSpace(c.OpenCommand) != ""
 }
 
+// HookCommand returns the command configured for a hook like "pre-next", or "" if there is none
+func (c Configuration) HookCommand(hook string) string {
+	switch hook {
+	case "pre-start":
+		return c.HookPreStart
+	case "post-start":
+		return c.HookPostStart
+	case "pre-next":
+		return c.HookPreNext
+	case "post-next":
+		return c.HookPostNext
+	case "pre-done":
+		return c.HookPreDone
+	case "post-done":
+		return c.HookPostDone
+	case "pre-reset":
+		return c.HookPreReset
+	case "post-reset":
+		return c.HookPostReset
+	case "timer":
+		return c.HookTimer
+	}
+	return ""
+}
+
 func Config(c Configuration) {
+	say.Say("MOB_AUTOSAVE_INTERVAL" + "=" + quote(c.AutosaveInterval))
+	say.Say("MOB_AUTOSAVE_PUSH" + "=" + strconv.FormatBool(c.AutosavePush))
 	say.Say("MOB_CLI_NAME" + "=" + quote(c.CliName))
+	say.Say("MOB_DONE_PULL_REQUEST" + "=" + strconv.FormatBool(c.DonePullRequest))
 	say.Say("MOB_DONE_SQUASH" + "=" + string(c.DoneSquash))
 	say.Say("MOB_GIT_HOOKS_ENABLED" + "=" + strconv.FormatBool(c.GitHooksEnabled))
+	say.Say("MOB_HANDOVER" + "=" + c.Handover)
+	say.Say("MOB_HANDOVER_STATE" + "=" + quote(c.HandoverState))
+	say.Say("MOB_HOOK_POST_DONE" + "=" + quote(c.HookPostDone))
+	say.Say("MOB_HOOK_POST_NEXT" + "=" + quote(c.HookPostNext))
+	say.Say("MOB_HOOK_POST_RESET" + "=" + quote(c.HookPostReset))
+	say.Say("MOB_HOOK_POST_START" + "=" + quote(c.HookPostStart))
+	say.Say("MOB_HOOK_PRE_DONE" + "=" + quote(c.HookPreDone))
+	say.Say("MOB_HOOK_PRE_NEXT" + "=" + quote(c.HookPreNext))
+	say.Say("MOB_HOOK_PRE_RESET" + "=" + quote(c.HookPreReset))
+	say.Say("MOB_HOOK_PRE_START" + "=" + quote(c.HookPreStart))
+	say.Say("MOB_HOOK_TIMER" + "=" + quote(c.HookTimer))
 	say.Say("MOB_NEXT_STAY" + "=" + strconv.FormatBool(c.NextStay))
+	say.Say("MOB_NEXT_VERIFY_COMMAND" + "=" + quote(c.NextVerifyCommand))
+	say.Say("MOB_NOTIFY_BACKEND" + "=" + c.NotifyBackend)
 	say.Say("MOB_NOTIFY_COMMAND" + "=" + quote(c.NotifyCommand))
 	say.Say("MOB_NOTIFY_MESSAGE" + "=" + quote(c.NotifyMessage))
 	say.Say("MOB_OPEN_COMMAND" + "=" + quote(c.OpenCommand))
+	say.Say("MOB_PULL_REQUEST_BRANCH_PREFIX" + "=" + quote(c.PullRequestBranchPrefix))
+	say.Say("MOB_PULL_REQUEST_COMMAND" + "=" + quote(c.PullRequestCommand))
+	say.Say("MOB_PULL_REQUEST_PROVIDER" + "=" + quote(c.PullRequestProvider))
+	say.Say("MOB_PULL_REQUEST_TOKEN" + "=" + quote(mask(c.PullRequestToken)))
+	say.Say("MOB_PULL_REQUEST_URL" + "=" + quote(c.PullRequestUrl))
 	say.Say("MOB_REMOTE_NAME" + "=" + quote(c.RemoteName))
 	say.Say("MOB_REQUIRE_COMMIT_MESSAGE" + "=" + strconv.FormatBool(c.RequireCommitMessage))
 	say.Say("MOB_SKIP_CI_PUSH_OPTION_ENABLED" + "=" + strconv.FormatBool(c.SkipCiPushOptionEnabled))
@@ -99,12 +192,69 @@ func Config(c Configuration) {
 	say.Say("MOB_TIMER" + "=" + quote(c.Timer))
 	say.Say("MOB_VOICE_COMMAND" + "=" + quote(c.VoiceCommand))
 	say.Say("MOB_VOICE_MESSAGE" + "=" + quote(c.VoiceMessage))
+	say.Say("MOB_WEBHOOK_FORMAT" + "=" + quote(c.WebhookFormat))
+	say.Say("MOB_WEBHOOK_URL" + "=" + quote(mask(c.WebhookUrl)))
 	say.Say("MOB_WIP_BRANCH_PREFIX" + "=" + quote(c.WipBranchPrefix))
 	say.Say("MOB_WIP_BRANCH_QUALIFIER_SEPARATOR" + "=" + quote(c.WipBranchQualifierSeparator))
 	say.Say("MOB_WIP_BRANCH_QUALIFIER" + "=" + quote(c.WipBranchQualifier))
 	say.Say("MOB_WIP_COMMIT_MESSAGE" + "=" + quote(c.WipCommitMessage))
 }
 
+// ConfigJson prints the same settings as Config as one JSON object, booleans are JSON booleans.
+func ConfigJson(c Configuration) {
+	output, _ := json.MarshalIndent(map[string]interface{}{
+		"MOB_AUTOSAVE_INTERVAL":                   c.AutosaveInterval,
+		"MOB_AUTOSAVE_PUSH":                       c.AutosavePush,
+		"MOB_CLI_NAME":                            c.CliName,
+		"MOB_DONE_PULL_REQUEST":                   c.DonePullRequest,
+		"MOB_DONE_SQUASH":                         c.DoneSquash,
+		"MOB_GIT_HOOKS_ENABLED":                   c.GitHooksEnabled,
+		"MOB_HANDOVER":                            c.Handover,
+		"MOB_HANDOVER_STATE":                      c.HandoverState,
+		"MOB_HOOK_POST_DONE":                      c.HookPostDone,
+		"MOB_HOOK_POST_NEXT":                      c.HookPostNext,
+		"MOB_HOOK_POST_RESET":                     c.HookPostReset,
+		"MOB_HOOK_POST_START":                     c.HookPostStart,
+		"MOB_HOOK_PRE_DONE":                       c.HookPreDone,
+		"MOB_HOOK_PRE_NEXT":                       c.HookPreNext,
+		"MOB_HOOK_PRE_RESET":                      c.HookPreReset,
+		"MOB_HOOK_PRE_START":                      c.HookPreStart,
+		"MOB_HOOK_TIMER":                          c.HookTimer,
+		"MOB_NEXT_STAY":                           c.NextStay,
+		"MOB_NEXT_VERIFY_COMMAND":                 c.NextVerifyCommand,
+		"MOB_NOTIFY_BACKEND":                      c.NotifyBackend,
+		"MOB_NOTIFY_COMMAND":                      c.NotifyCommand,
+		"MOB_NOTIFY_MESSAGE":                      c.NotifyMessage,
+		"MOB_OPEN_COMMAND":                        c.OpenCommand,
+		"MOB_PULL_REQUEST_BRANCH_PREFIX":          c.PullRequestBranchPrefix,
+		"MOB_PULL_REQUEST_COMMAND":                c.PullRequestCommand,
+		"MOB_PULL_REQUEST_PROVIDER":               c.PullRequestProvider,
+		"MOB_PULL_REQUEST_TOKEN":                  mask(c.PullRequestToken),
+		"MOB_PULL_REQUEST_URL":                    c.PullRequestUrl,
+		"MOB_REMOTE_NAME":                         c.RemoteName,
+		"MOB_REQUIRE_COMMIT_MESSAGE":              c.RequireCommitMessage,
+		"MOB_SKIP_CI_PUSH_OPTION_ENABLED":         c.SkipCiPushOptionEnabled,
+		"MOB_START_COMMIT_MESSAGE":                c.StartCommitMessage,
+		"MOB_STASH_NAME":                          c.StashName,
+		"MOB_TIMER_INSECURE":                      c.TimerInsecure,
+		"MOB_TIMER_LOCAL":                         c.TimerLocal,
+		"MOB_TIMER_ROOM_USE_WIP_BRANCH_QUALIFIER": c.TimerRoomUseWipBranchQualifier,
+		"MOB_TIMER_ROOM":                          c.TimerRoom,
+		"MOB_TIMER_URL":                           c.TimerUrl,
+		"MOB_TIMER_USER":                          c.TimerUser,
+		"MOB_TIMER":                               c.Timer,
+		"MOB_VOICE_COMMAND":                       c.VoiceCommand,
+		"MOB_VOICE_MESSAGE":                       c.VoiceMessage,
+		"MOB_WEBHOOK_FORMAT":                      c.WebhookFormat,
+		"MOB_WEBHOOK_URL":                         mask(c.WebhookUrl),
+		"MOB_WIP_BRANCH_PREFIX":                   c.WipBranchPrefix,
+		"MOB_WIP_BRANCH_QUALIFIER_SEPARATOR":      c.WipBranchQualifierSeparator,
+		"MOB_WIP_BRANCH_QUALIFIER":                c.WipBranchQualifier,
+		"MOB_WIP_COMMIT_MESSAGE":                  c.WipCommitMessage,
+	}, "", "  ")
+	say.Say(string(output))
+}
+
 func ReadConfiguration(gitRootDir string) Configuration {
 	configuration := GetDefaultConfiguration()
 	configuration = parseEnvironmentVariables(configuration)
@@ -141,21 +291,53 @@ func ParseArgs(args []string, configuration Configuration) (command string, para
 			i++ // skip consumed parameter
 		case "--message", "-m":
 			if i+1 != len(args) {
-				newConfiguration.WipCommitMessage = args[i+1]
+				if isDoneCommand(command) {
+					newConfiguration.DoneCommitMessage = args[i+1]
+				} else {
+					newConfiguration.WipCommitMessage = args[i+1]
+				}
 			}
 			i++ // skip consumed parameter
+		case "--note":
+			if i+1 != len(args) && !strings.HasPrefix(args[i+1], "-") {
+				newConfiguration.NextNote = args[i+1]
+				i++ // skip consumed parameter
+			} else {
+				newConfiguration.NextNoteEdit = true
+			}
+		case "--no-verify-run":
+			newConfiguration.NextNoVerifyRun = true
 		case "--squash":
 			newConfiguration.DoneSquash = Squash
 		case "--no-squash":
 			newConfiguration.DoneSquash = NoSquash
 		case "--squash-wip":
 			newConfiguration.DoneSquash = SquashWip
+		case "--commit":
+			newConfiguration.DoneCommit = true
+		case "--push":
+			newConfiguration.DoneCommit = true
+			newConfiguration.DonePush = true
+		case "--pull-request":
+			newConfiguration.DonePullRequest = true
+		case "--continue":
+			newConfiguration.DoneContinue = true
+		case "--abort":
+			newConfiguration.DoneAbort = true
 		case "--create", "-c":
 			newConfiguration.StartCreate = true
 		case "--join", "-j":
 			newConfiguration.StartJoin = true
 		case "--delete-remote-wip-branch":
 			newConfiguration.ResetDeleteRemoteWipBranch = true
+		case "--dry-run":
+			newConfiguration.DryRun = true
+		case "--start":
+			newConfiguration.WaitStart = true
+		case "--json", "--output=json":
+			newConfiguration.OutputJson = true
+		case "--output=text":
+			newConfiguration.OutputJson = false
 		case "--room":
 			if i+1 != len(args) {
 				newConfiguration.TimerRoom = args[i+1]
@@ -174,6 +356,10 @@ func ParseArgs(args []string, configuration Configuration) (command string, para
 	return
 }
 
+func isDoneCommand(command string) bool {
+	return command == "d" || command == "done"
+}
+
 func GetDefaultConfiguration() Configuration {
 	voiceCommand := ""
 	notifyCommand := ""
@@ -199,6 +385,7 @@ func GetDefaultConfiguration() Configuration {
 		VoiceMessage:                "mob next",
 		NotifyCommand:               notifyCommand,
 		NotifyMessage:               "mob next",
+		NotifyBackend:               NotifyBackendAuto,
 		NextStay:                    true,
 		RequireCommitMessage:        false,
 		HandleUncommittedChanges:    FailWithError,
@@ -206,6 +393,9 @@ func GetDefaultConfiguration() Configuration {
 		WipBranchQualifier:          "",
 		WipBranchQualifierSeparator: "-",
 		DoneSquash:                  Squash,
+		Handover:                    HandoverWipBranch,
+		DonePullRequest:             false,
+		PullRequestBranchPrefix:     "feature/",
 		OpenCommand:                 "",
 		Timer:                       "",
 		TimerLocal:                  true,
@@ -214,6 +404,7 @@ func GetDefaultConfiguration() Configuration {
 		TimerUrl:                    "https://timer.mob.sh/",
 		WipBranchPrefix:             "mob/",
 		StashName:                   "mob-stash-name",
+		WebhookFormat:               "event",
 		ResetDeleteRemoteWipBranch:  false,
 	}
 }
@@ -264,8 +455,14 @@ func parseUserConfiguration(configuration Configuration, path string) Configurat
 			setUnquotedString(&configuration.NotifyCommand, key, value)
 		case "MOB_NOTIFY_MESSAGE":
 			setUnquotedString(&configuration.NotifyMessage, key, value)
+		case "MOB_HANDOVER_STATE":
+			setUnquotedString(&configuration.HandoverState, key, value)
 		case "MOB_NEXT_STAY":
 			setBoolean(&configuration.NextStay, key, value)
+		case "MOB_NOTIFY_BACKEND":
+			setMobNotifyBackend(&configuration, key, value)
+		case "MOB_NEXT_VERIFY_COMMAND":
+			setUnquotedString(&configuration.NextVerifyCommand, key, value)
 		case "MOB_START_CREATE":
 			setBoolean(&configuration.StartCreate, key, value)
 		case "MOB_WIP_BRANCH_QUALIFIER":
@@ -276,8 +473,40 @@ func parseUserConfiguration(configuration Configuration, path string) Configurat
 			setUnquotedString(&configuration.WipBranchPrefix, key, value)
 		case "MOB_DONE_SQUASH":
 			setMobDoneSquash(&configuration, key, value)
+		case "MOB_HANDOVER":
+			setMobHandover(&configuration, key, value)
+		case "MOB_DONE_PULL_REQUEST":
+			setBoolean(&configuration.DonePullRequest, key, value)
+		case "MOB_PULL_REQUEST_PROVIDER":
+			setUnquotedString(&configuration.PullRequestProvider, key, value)
+		case "MOB_PULL_REQUEST_URL":
+			setUnquotedString(&configuration.PullRequestUrl, key, value)
+		case "MOB_PULL_REQUEST_TOKEN":
+			setUnquotedString(&configuration.PullRequestToken, key, value)
+		case "MOB_PULL_REQUEST_COMMAND":
+			setUnquotedString(&configuration.PullRequestCommand, key, value)
+		case "MOB_PULL_REQUEST_BRANCH_PREFIX":
+			setUnquotedString(&configuration.PullRequestBranchPrefix, key, value)
 		case "MOB_OPEN_COMMAND":
 			setUnquotedString(&configuration.OpenCommand, key, value)
+		case "MOB_HOOK_PRE_START":
+			setUnquotedString(&configuration.HookPreStart, key, value)
+		case "MOB_HOOK_POST_START":
+			setUnquotedString(&configuration.HookPostStart, key, value)
+		case "MOB_HOOK_PRE_NEXT":
+			setUnquotedString(&configuration.HookPreNext, key, value)
+		case "MOB_HOOK_POST_NEXT":
+			setUnquotedString(&configuration.HookPostNext, key, value)
+		case "MOB_HOOK_PRE_DONE":
+			setUnquotedString(&configuration.HookPreDone, key, value)
+		case "MOB_HOOK_POST_DONE":
+			setUnquotedString(&configuration.HookPostDone, key, value)
+		case "MOB_HOOK_PRE_RESET":
+			setUnquotedString(&configuration.HookPreReset, key, value)
+		case "MOB_HOOK_POST_RESET":
+			setUnquotedString(&configuration.HookPostReset, key, value)
+		case "MOB_HOOK_TIMER":
+			setUnquotedString(&configuration.HookTimer, key, value)
 		case "MOB_TIMER":
 			setUnquotedString(&configuration.Timer, key, value)
 		case "MOB_TIMER_ROOM":
@@ -296,6 +525,14 @@ func parseUserConfiguration(configuration Configuration, path string) Configurat
 			setBoolean(&configuration.TimerInsecure, key, value)
 		case "MOB_RESET_DELETE_REMOTE_WIP_BRANCH":
 			setBoolean(&configuration.ResetDeleteRemoteWipBranch, key, value)
+		case "MOB_AUTOSAVE_INTERVAL":
+			setUnquotedString(&configuration.AutosaveInterval, key, value)
+		case "MOB_AUTOSAVE_PUSH":
+			setBoolean(&configuration.AutosavePush, key, value)
+		case "MOB_WEBHOOK_URL":
+			setUnquotedString(&configuration.WebhookUrl, key, value)
+		case "MOB_WEBHOOK_FORMAT":
+			setUnquotedString(&configuration.WebhookFormat, key, value)
 
 		default:
 			continue
@@ -333,7 +570,8 @@ func parseProjectConfiguration(configuration Configuration, path string) Configu
 		say.Debug("Key is " + key)
 		say.Debug("Value is " + value)
 		switch key {
-		case "MOB_VOICE_COMMAND", "MOB_VOICE_MESSAGE", "MOB_NOTIFY_COMMAND", "MOB_NOTIFY_MESSAGE", "MOB_OPEN_COMMAND":
+		case "MOB_VOICE_COMMAND", "MOB_VOICE_MESSAGE", "MOB_NOTIFY_COMMAND", "MOB_NOTIFY_MESSAGE", "MOB_OPEN_COMMAND", "MOB_PULL_REQUEST_TOKEN", "MOB_PULL_REQUEST_COMMAND", "MOB_PULL_REQUEST_PROVIDER", "MOB_PULL_REQUEST_URL", "MOB_HANDOVER_STATE",
+			"MOB_HOOK_PRE_START", "MOB_HOOK_POST_START", "MOB_HOOK_PRE_NEXT", "MOB_HOOK_POST_NEXT", "MOB_HOOK_PRE_DONE", "MOB_HOOK_POST_DONE", "MOB_HOOK_PRE_RESET", "MOB_HOOK_POST_RESET", "MOB_HOOK_TIMER", "MOB_NEXT_VERIFY_COMMAND", "MOB_WEBHOOK_URL":
 			say.Warning("Skipped overwriting key " + key + " from project/.mob file out of security reasons!")
 		case "MOB_CLI_NAME":
 			setUnquotedString(&configuration.CliName, key, value)
@@ -351,6 +589,8 @@ func parseProjectConfiguration(configuration Configuration, path string) Configu
 			setBoolean(&configuration.RequireCommitMessage, key, value)
 		case "MOB_NEXT_STAY":
 			setBoolean(&configuration.NextStay, key, value)
+		case "MOB_NOTIFY_BACKEND":
+			setMobNotifyBackend(&configuration, key, value)
 		case "MOB_START_CREATE":
 			setBoolean(&configuration.StartCreate, key, value)
 		case "MOB_WIP_BRANCH_QUALIFIER":
@@ -361,6 +601,12 @@ func parseProjectConfiguration(configuration Configuration, path string) Configu
 			setUnquotedString(&configuration.WipBranchPrefix, key, value)
 		case "MOB_DONE_SQUASH":
 			setMobDoneSquash(&configuration, key, value)
+		case "MOB_HANDOVER":
+			setMobHandover(&configuration, key, value)
+		case "MOB_DONE_PULL_REQUEST":
+			setBoolean(&configuration.DonePullRequest, key, value)
+		case "MOB_PULL_REQUEST_BRANCH_PREFIX":
+			setUnquotedString(&configuration.PullRequestBranchPrefix, key, value)
 		case "MOB_TIMER":
 			setUnquotedString(&configuration.Timer, key, value)
 		case "MOB_TIMER_ROOM":
@@ -379,6 +625,12 @@ func parseProjectConfiguration(configuration Configuration, path string) Configu
 			setBoolean(&configuration.TimerInsecure, key, value)
 		case "MOB_RESET_DELETE_REMOTE_WIP_BRANCH":
 			setBoolean(&configuration.ResetDeleteRemoteWipBranch, key, value)
+		case "MOB_AUTOSAVE_INTERVAL":
+			setUnquotedString(&configuration.AutosaveInterval, key, value)
+		case "MOB_AUTOSAVE_PUSH":
+			setBoolean(&configuration.AutosavePush, key, value)
+		case "MOB_WEBHOOK_FORMAT":
+			setUnquotedString(&configuration.WebhookFormat, key, value)
 
 		default:
 			continue
@@ -425,6 +677,32 @@ func setMobDoneSquash(configuration *Configuration, key string, value string) {
 	say.Debug("Overwriting " + key + " =" + configuration.DoneSquash)
 }
 
+func setMobHandover(configuration *Configuration, key string, value string) {
+	if strings.HasPrefix(value, "\"") {
+		unquotedValue, err := strconv.Unquote(value)
+		if err != nil {
+			say.Warning("Could not set key from configuration file because value is not parseable (" + key + "=" + value + ")")
+			return
+		}
+		value = unquotedValue
+	}
+	configuration.Handover = handover(value)
+	say.Debug("Overwriting " + key + " =" + configuration.Handover)
+}
+
+func setMobNotifyBackend(configuration *Configuration, key string, value string) {
+	if strings.HasPrefix(value, "\"") {
+		unquotedValue, err := strconv.Unquote(value)
+		if err != nil {
+			say.Warning("Could not set key from configuration file because value is not parseable (" + key + "=" + value + ")")
+			return
+		}
+		value = unquotedValue
+	}
+	configuration.NotifyBackend = notifyBackend(value)
+	say.Debug("Overwriting " + key + " =" + configuration.NotifyBackend)
+}
+
 func parseEnvironmentVariables(configuration Configuration) Configuration {
 	setStringFromEnvVariable(&configuration.CliName, "MOB_CLI_NAME")
 	if configuration.CliName != GetDefaultConfiguration().CliName {
@@ -449,19 +727,40 @@ func parseEnvironmentVariables(configuration Configuration) Configuration {
 	setStringFromEnvVariable(&configuration.VoiceMessage, "MOB_VOICE_MESSAGE")
 	setOptionalStringFromEnvVariable(&configuration.NotifyCommand, "MOB_NOTIFY_COMMAND")
 	setStringFromEnvVariable(&configuration.NotifyMessage, "MOB_NOTIFY_MESSAGE")
+	setNotifyBackendFromEnvVariable(&configuration, "MOB_NOTIFY_BACKEND")
 	setStringFromEnvVariable(&configuration.WipBranchQualifierSeparator, "MOB_WIP_BRANCH_QUALIFIER_SEPARATOR")
 
 	setStringFromEnvVariable(&configuration.WipBranchQualifier, "MOB_WIP_BRANCH_QUALIFIER")
 	setStringFromEnvVariable(&configuration.WipBranchPrefix, "MOB_WIP_BRANCH_PREFIX")
 
 	setBoolFromEnvVariable(&configuration.NextStay, "MOB_NEXT_STAY")
+	setStringFromEnvVariable(&configuration.NextVerifyCommand, "MOB_NEXT_VERIFY_COMMAND")
 
 	setBoolFromEnvVariable(&configuration.StartCreate, "MOB_START_CREATE")
 
 	setDoneSquashFromEnvVariable(&configuration, "MOB_DONE_SQUASH")
+	setBoolFromEnvVariable(&configuration.DonePullRequest, "MOB_DONE_PULL_REQUEST")
+	setHandoverFromEnvVariable(&configuration, "MOB_HANDOVER")
+	setStringFromEnvVariable(&configuration.HandoverState, "MOB_HANDOVER_STATE")
+
+	setStringFromEnvVariable(&configuration.PullRequestProvider, "MOB_PULL_REQUEST_PROVIDER")
+	setStringFromEnvVariable(&configuration.PullRequestUrl, "MOB_PULL_REQUEST_URL")
+	setStringFromEnvVariable(&configuration.PullRequestToken, "MOB_PULL_REQUEST_TOKEN")
+	setStringFromEnvVariable(&configuration.PullRequestCommand, "MOB_PULL_REQUEST_COMMAND")
+	setStringFromEnvVariable(&configuration.PullRequestBranchPrefix, "MOB_PULL_REQUEST_BRANCH_PREFIX")
 
 	setStringFromEnvVariable(&configuration.OpenCommand, "MOB_OPEN_COMMAND")
 
+	setStringFromEnvVariable(&configuration.HookPreStart, "MOB_HOOK_PRE_START")
+	setStringFromEnvVariable(&configuration.HookPostStart, "MOB_HOOK_POST_START")
+	setStringFromEnvVariable(&configuration.HookPreNext, "MOB_HOOK_PRE_NEXT")
+	setStringFromEnvVariable(&configuration.HookPostNext, "MOB_HOOK_POST_NEXT")
+	setStringFromEnvVariable(&configuration.HookPreDone, "MOB_HOOK_PRE_DONE")
+	setStringFromEnvVariable(&configuration.HookPostDone, "MOB_HOOK_POST_DONE")
+	setStringFromEnvVariable(&configuration.HookPreReset, "MOB_HOOK_PRE_RESET")
+	setStringFromEnvVariable(&configuration.HookPostReset, "MOB_HOOK_POST_RESET")
+	setStringFromEnvVariable(&configuration.HookTimer, "MOB_HOOK_TIMER")
+
 	setStringFromEnvVariable(&configuration.Timer, "MOB_TIMER")
 	setStringFromEnvVariable(&configuration.TimerRoom, "MOB_TIMER_ROOM")
 	setBoolFromEnvVariable(&configuration.TimerRoomUseWipBranchQualifier, "MOB_TIMER_ROOM_USE_WIP_BRANCH_QUALIFIER")
@@ -472,6 +771,12 @@ func parseEnvironmentVariables(configuration Configuration) Configuration {
 
 	setBoolFromEnvVariable(&configuration.ResetDeleteRemoteWipBranch, "MOB_RESET_DELETE_REMOTE_WIP_BRANCH")
 
+	setStringFromEnvVariable(&configuration.AutosaveInterval, "MOB_AUTOSAVE_INTERVAL")
+	setBoolFromEnvVariable(&configuration.AutosavePush, "MOB_AUTOSAVE_PUSH")
+
+	setStringFromEnvVariable(&configuration.WebhookUrl, "MOB_WEBHOOK_URL")
+	setStringFromEnvVariable(&configuration.WebhookFormat, "MOB_WEBHOOK_FORMAT")
+
 	return configuration
 }
 
@@ -527,6 +832,26 @@ func setDoneSquashFromEnvVariable(configuration *Configuration, key string) {
 	say.Debug("overriding " + key + "=" + configuration.DoneSquash)
 }
 
+func setHandoverFromEnvVariable(configuration *Configuration, key string) {
+	value, set := os.LookupEnv(key)
+	if !set || value == "" {
+		return
+	}
+
+	configuration.Handover = handover(value)
+	say.Debug("overriding " + key + "=" + configuration.Handover)
+}
+
+func setNotifyBackendFromEnvVariable(configuration *Configuration, key string) {
+	value, set := os.LookupEnv(key)
+	if !set || value == "" {
+		return
+	}
+
+	configuration.NotifyBackend = notifyBackend(value)
+	say.Debug("overriding " + key + "=" + configuration.NotifyBackend)
+}
+
 func removed(key string, message string) {
 	if _, set := os.LookupEnv(key); set {
 		say.Say("Configuration option '" + key + "' is no longer used.")
@@ -558,6 +883,33 @@ func doneSquash(value string) string {
 	}
 }
 
+func handover(value string) string {
+	switch value {
+	case HandoverStash:
+		return HandoverStash
+	default:
+		return HandoverWipBranch
+	}
+}
+
+func notifyBackend(value string) string {
+	switch value {
+	case NotifyBackendDBus:
+		return NotifyBackendDBus
+	case NotifyBackendCommand:
+		return NotifyBackendCommand
+	default:
+		return NotifyBackendAuto
+	}
+}
+
 func quote(value string) string {
 	return strconv.Quote(value)
 }
+
+func mask(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return "********"
+}
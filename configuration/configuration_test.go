@@ -1,6 +1,7 @@
 package configuration
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/remotemobprogramming/mob/v5/say"
 	"github.com/remotemobprogramming/mob/v5/test"
@@ -102,6 +103,109 @@ func TestParseArgsMessage(t *testing.T) {
 	test.Equals(t, "ci-skip", configuration.WipCommitMessage)
 }
 
+func TestParseArgsNote(t *testing.T) {
+	command, parameters, configuration := ParseArgs([]string{"mob", "next", "--note", "I was about to rename X", "--stay"}, GetDefaultConfiguration())
+
+	test.Equals(t, "next", command)
+	test.Equals(t, "", strings.Join(parameters, ""))
+	test.Equals(t, "I was about to rename X", configuration.NextNote)
+	test.Equals(t, false, configuration.NextNoteEdit)
+	test.Equals(t, true, configuration.NextStay)
+}
+
+func TestParseArgsNoteWithoutText(t *testing.T) {
+	_, _, configuration := ParseArgs([]string{"mob", "next", "--note", "--stay"}, GetDefaultConfiguration())
+
+	test.Equals(t, "", configuration.NextNote)
+	test.Equals(t, true, configuration.NextNoteEdit)
+	test.Equals(t, true, configuration.NextStay)
+}
+
+func TestParseArgsDoneCommitMessage(t *testing.T) {
+	configuration := GetDefaultConfiguration()
+
+	command, parameters, configuration := ParseArgs([]string{"mob", "done", "--commit", "--message", "finish feature"}, configuration)
+
+	test.Equals(t, "done", command)
+	test.Equals(t, "", strings.Join(parameters, ""))
+	test.Equals(t, true, configuration.DoneCommit)
+	test.Equals(t, false, configuration.DonePush)
+	test.Equals(t, "finish feature", configuration.DoneCommitMessage)
+	test.Equals(t, GetDefaultConfiguration().WipCommitMessage, configuration.WipCommitMessage)
+}
+
+func TestParseArgsDonePush(t *testing.T) {
+	configuration := GetDefaultConfiguration()
+
+	command, parameters, configuration := ParseArgs([]string{"mob", "done", "--push"}, configuration)
+
+	test.Equals(t, "done", command)
+	test.Equals(t, "", strings.Join(parameters, ""))
+	test.Equals(t, true, configuration.DoneCommit)
+	test.Equals(t, true, configuration.DonePush)
+}
+
+func TestParseArgsDryRun(t *testing.T) {
+	configuration := GetDefaultConfiguration()
+
+	command, parameters, configuration := ParseArgs([]string{"mob", "done", "--dry-run"}, configuration)
+
+	test.Equals(t, "done", command)
+	test.Equals(t, "", strings.Join(parameters, ""))
+	test.Equals(t, true, configuration.DryRun)
+}
+
+func TestParseArgsWaitStart(t *testing.T) {
+	configuration := GetDefaultConfiguration()
+
+	command, parameters, configuration := ParseArgs([]string{"mob", "wait", "--start", "10"}, configuration)
+
+	test.Equals(t, "wait", command)
+	test.Equals(t, "10", strings.Join(parameters, ""))
+	test.Equals(t, true, configuration.WaitStart)
+}
+
+func TestParseArgsJson(t *testing.T) {
+	configuration := GetDefaultConfiguration()
+
+	command, _, configuration := ParseArgs([]string{"mob", "status", "--json"}, configuration)
+
+	test.Equals(t, "status", command)
+	test.Equals(t, true, configuration.OutputJson)
+}
+
+func TestParseArgsOutputJson(t *testing.T) {
+	configuration := GetDefaultConfiguration()
+
+	command, parameters, configuration := ParseArgs([]string{"mob", "config", "--output=json"}, configuration)
+
+	test.Equals(t, "config", command)
+	test.Equals(t, "", strings.Join(parameters, ""))
+	test.Equals(t, true, configuration.OutputJson)
+}
+
+func TestConfigJsonContainsAllSettingsOfConfig(t *testing.T) {
+	output := test.CaptureOutput(t)
+	configuration := GetDefaultConfiguration()
+	configuration.PullRequestToken = "secret"
+	Config(configuration)
+	lines := strings.Split(strings.TrimSpace(*output), "\n")
+	*output = ""
+
+	ConfigJson(configuration)
+
+	var settings map[string]interface{}
+	test.Equals(t, nil, json.Unmarshal([]byte(*output), &settings))
+	test.Equals(t, len(lines), len(settings))
+	for _, line := range lines {
+		if _, ok := settings[strings.SplitN(line, "=", 2)[0]]; !ok {
+			t.Errorf("%s is missing in the JSON output", line)
+		}
+	}
+	test.Equals(t, "********", settings["MOB_PULL_REQUEST_TOKEN"])
+	test.Equals(t, true, settings["MOB_SKIP_CI_PUSH_OPTION_ENABLED"])
+}
+
 func TestParseArgsStartRoom(t *testing.T) {
 	configuration := GetDefaultConfiguration()
 	test.Equals(t, configuration.WipBranchQualifier, "")
@@ -204,6 +308,18 @@ func TestMobDoneSquashEnvironmentVariable(t *testing.T) {
 	assertMobDoneSquashValue(t, "squash-wip", SquashWip)
 }
 
+func TestMobHandoverEnvironmentVariable(t *testing.T) {
+	assertMobHandoverValue(t, "", HandoverWipBranch)
+	assertMobHandoverValue(t, "garbage", HandoverWipBranch)
+	assertMobHandoverValue(t, "wip-branch", HandoverWipBranch)
+	assertMobHandoverValue(t, "stash", HandoverStash)
+}
+
+func assertMobHandoverValue(t *testing.T, value string, expected string) {
+	configuration := setEnvVarAndParse("MOB_HANDOVER", value)
+	test.Equals(t, expected, configuration.Handover)
+}
+
 func assertMobDoneSquashValue(t *testing.T, value string, expected string) {
 	configuration := setEnvVarAndParse("MOB_DONE_SQUASH", value)
 	test.Equals(t, expected, configuration.DoneSquash)
@@ -305,6 +421,8 @@ func TestReadUserConfigurationFromFileOverrideEverything(t *testing.T) {
 		MOB_TIMER_USER="Mona"
 		MOB_TIMER_URL="https://timer.innoq.io/"
 		MOB_STASH_NAME="team-stash-name"
+		MOB_AUTOSAVE_INTERVAL="5"
+		MOB_AUTOSAVE_PUSH=true
 	`)
 	actualConfiguration := parseUserConfiguration(GetDefaultConfiguration(), tempDir+"/.mob")
 	test.Equals(t, "team", actualConfiguration.CliName)
@@ -331,6 +449,8 @@ func TestReadUserConfigurationFromFileOverrideEverything(t *testing.T) {
 	test.Equals(t, "Mona", actualConfiguration.TimerUser)
 	test.Equals(t, "https://timer.innoq.io/", actualConfiguration.TimerUrl)
 	test.Equals(t, "team-stash-name", actualConfiguration.StashName)
+	test.Equals(t, "5", actualConfiguration.AutosaveInterval)
+	test.Equals(t, true, actualConfiguration.AutosavePush)
 
 	test.CreateFile(t, ".mob", "\nMOB_TIMER_ROOM=\"Room\\\"\\\"_42\"\n")
 	actualConfiguration1 := parseUserConfiguration(GetDefaultConfiguration(), tempDir+"/.mob")
@@ -359,6 +479,7 @@ func TestReadProjectConfigurationFromFileOverrideEverything(t *testing.T) {
 		MOB_WIP_BRANCH_QUALIFIER_SEPARATOR="---"
 		MOB_WIP_BRANCH_PREFIX="ensemble/"
 		MOB_DONE_SQUASH=no-squash
+		MOB_HANDOVER=stash
 		MOB_OPEN_COMMAND="idea %s"
 		MOB_TIMER="123"
 		MOB_TIMER_ROOM="Room_42"
@@ -385,6 +506,7 @@ func TestReadProjectConfigurationFromFileOverrideEverything(t *testing.T) {
 	test.Equals(t, "---", actualConfiguration.WipBranchQualifierSeparator)
 	test.Equals(t, "ensemble/", actualConfiguration.WipBranchPrefix)
 	test.Equals(t, NoSquash, actualConfiguration.DoneSquash)
+	test.Equals(t, HandoverStash, actualConfiguration.Handover)
 	test.NotEquals(t, "idea %s", actualConfiguration.OpenCommand)
 	test.Equals(t, "123", actualConfiguration.Timer)
 	test.Equals(t, "Room_42", actualConfiguration.TimerRoom)
@@ -404,6 +526,43 @@ func TestReadProjectConfigurationFromFileOverrideEverything(t *testing.T) {
 	test.AssertOutputContains(t, output, "Skipped overwriting key MOB_OPEN_COMMAND from project/.mob file out of security reasons!")
 }
 
+func TestReadProjectConfigurationFromFileSkipsPullRequestSecrets(t *testing.T) {
+	output := test.CaptureOutput(t)
+	tempDir = t.TempDir()
+	test.SetWorkingDir(tempDir)
+
+	test.CreateFile(t, ".mob", `
+		MOB_DONE_PULL_REQUEST=true
+		MOB_PULL_REQUEST_PROVIDER="gitlab"
+		MOB_PULL_REQUEST_URL="https://git.example.com/api/v4"
+		MOB_PULL_REQUEST_BRANCH_PREFIX="review/"
+		MOB_PULL_REQUEST_TOKEN="secret"
+		MOB_PULL_REQUEST_COMMAND="rm -rf /"
+	`)
+	actualConfiguration := parseProjectConfiguration(GetDefaultConfiguration(), tempDir+"/.mob")
+	test.Equals(t, true, actualConfiguration.DonePullRequest)
+	test.Equals(t, "", actualConfiguration.PullRequestProvider)
+	test.Equals(t, "", actualConfiguration.PullRequestUrl)
+	test.Equals(t, "review/", actualConfiguration.PullRequestBranchPrefix)
+	test.Equals(t, "", actualConfiguration.PullRequestToken)
+	test.Equals(t, "", actualConfiguration.PullRequestCommand)
+	test.AssertOutputContains(t, output, "Skipped overwriting key MOB_PULL_REQUEST_TOKEN from project/.mob file out of security reasons!")
+	test.AssertOutputContains(t, output, "Skipped overwriting key MOB_PULL_REQUEST_COMMAND from project/.mob file out of security reasons!")
+	test.AssertOutputContains(t, output, "Skipped overwriting key MOB_PULL_REQUEST_PROVIDER from project/.mob file out of security reasons!")
+	test.AssertOutputContains(t, output, "Skipped overwriting key MOB_PULL_REQUEST_URL from project/.mob file out of security reasons!")
+}
+
+func TestReadHandoverStateOnlyFromUserConfiguration(t *testing.T) {
+	output := test.CaptureOutput(t)
+	tempDir = t.TempDir()
+	test.SetWorkingDir(tempDir)
+
+	test.CreateFile(t, ".mob", "\nMOB_HANDOVER_STATE=\"/tmp/state.json\"\n")
+	test.Equals(t, "/tmp/state.json", parseUserConfiguration(GetDefaultConfiguration(), tempDir+"/.mob").HandoverState)
+	test.Equals(t, "", parseProjectConfiguration(GetDefaultConfiguration(), tempDir+"/.mob").HandoverState)
+	test.AssertOutputContains(t, output, "Skipped overwriting key MOB_HANDOVER_STATE from project/.mob file out of security reasons!")
+}
+
 func TestReadConfigurationFromFileWithNonBooleanQuotedDoneSquashValue(t *testing.T) {
 	say.TurnOnDebugging()
 	tempDir = t.TempDir()
@@ -462,3 +621,72 @@ func TestSetMobDoneSquashEmptyStringValue(t *testing.T) {
 	setMobDoneSquash(&configuration, "", "")
 	test.Equals(t, Squash, configuration.DoneSquash)
 }
+
+func TestReadHooksOnlyFromUserConfiguration(t *testing.T) {
+	output := test.CaptureOutput(t)
+	tempDir = t.TempDir()
+	test.SetWorkingDir(tempDir)
+
+	test.CreateFile(t, ".mob", "\nMOB_HOOK_PRE_NEXT=\"go fmt ./...\"\nMOB_HOOK_TIMER=\"echo timer\"\n")
+	userConfiguration := parseUserConfiguration(GetDefaultConfiguration(), tempDir+"/.mob")
+	test.Equals(t, "go fmt ./...", userConfiguration.HookCommand("pre-next"))
+	test.Equals(t, "echo timer", userConfiguration.HookCommand("timer"))
+	test.Equals(t, "", userConfiguration.HookCommand("post-next"))
+	projectConfiguration := parseProjectConfiguration(GetDefaultConfiguration(), tempDir+"/.mob")
+	test.Equals(t, "", projectConfiguration.HookCommand("pre-next"))
+	test.AssertOutputContains(t, output, "Skipped overwriting key MOB_HOOK_PRE_NEXT from project/.mob file out of security reasons!")
+}
+
+func TestReadNextVerifyCommandOnlyFromUserConfiguration(t *testing.T) {
+	output := test.CaptureOutput(t)
+	tempDir = t.TempDir()
+	test.SetWorkingDir(tempDir)
+
+	test.CreateFile(t, ".mob", "\nMOB_NEXT_VERIFY_COMMAND=\"go test ./...\"\n")
+	test.Equals(t, "go test ./...", parseUserConfiguration(GetDefaultConfiguration(), tempDir+"/.mob").NextVerifyCommand)
+	test.Equals(t, "", parseProjectConfiguration(GetDefaultConfiguration(), tempDir+"/.mob").NextVerifyCommand)
+	test.AssertOutputContains(t, output, "Skipped overwriting key MOB_NEXT_VERIFY_COMMAND from project/.mob file out of security reasons!")
+}
+
+func TestParseArgsNoVerifyRun(t *testing.T) {
+	configuration := GetDefaultConfiguration()
+
+	command, parameters, configuration := ParseArgs([]string{"mob", "next", "--no-verify-run"}, configuration)
+
+	test.Equals(t, "next", command)
+	test.Equals(t, "", strings.Join(parameters, ""))
+	test.Equals(t, true, configuration.NextNoVerifyRun)
+}
+
+func TestReadWebhookUrlOnlyFromUserConfiguration(t *testing.T) {
+	output := test.CaptureOutput(t)
+	tempDir = t.TempDir()
+	test.SetWorkingDir(tempDir)
+
+	test.CreateFile(t, ".mob", "\nMOB_WEBHOOK_URL=\"https://chat.example.com/hooks/secret\"\nMOB_WEBHOOK_FORMAT=\"slack\"\n")
+	userConfiguration := parseUserConfiguration(GetDefaultConfiguration(), tempDir+"/.mob")
+	test.Equals(t, "https://chat.example.com/hooks/secret", userConfiguration.WebhookUrl)
+	test.Equals(t, "slack", userConfiguration.WebhookFormat)
+	projectConfiguration := parseProjectConfiguration(GetDefaultConfiguration(), tempDir+"/.mob")
+	test.Equals(t, "", projectConfiguration.WebhookUrl)
+	test.Equals(t, "slack", projectConfiguration.WebhookFormat)
+	test.AssertOutputContains(t, output, "Skipped overwriting key MOB_WEBHOOK_URL from project/.mob file out of security reasons!")
+
+	Config(userConfiguration)
+	test.AssertOutputContains(t, output, "MOB_WEBHOOK_URL=\"********\"")
+}
+
+func TestReadNotifyBackend(t *testing.T) {
+	tempDir = t.TempDir()
+	test.SetWorkingDir(tempDir)
+
+	test.CreateFile(t, ".mob", "\nMOB_NOTIFY_BACKEND=\"dbus\"\n")
+	test.Equals(t, NotifyBackendDBus, parseUserConfiguration(GetDefaultConfiguration(), tempDir+"/.mob").NotifyBackend)
+	test.Equals(t, NotifyBackendDBus, parseProjectConfiguration(GetDefaultConfiguration(), tempDir+"/.mob").NotifyBackend)
+
+	test.CreateFile(t, ".mob", "\nMOB_NOTIFY_BACKEND=\"growl\"\n")
+	test.Equals(t, NotifyBackendAuto, parseUserConfiguration(GetDefaultConfiguration(), tempDir+"/.mob").NotifyBackend)
+
+	t.Setenv("MOB_NOTIFY_BACKEND", "command")
+	test.Equals(t, NotifyBackendCommand, parseEnvironmentVariables(GetDefaultConfiguration()).NotifyBackend)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"github.com/remotemobprogramming/mob/v5/goal"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// The kinds of errors a command can fail with, check them with errors.Is
+var (
+	ErrGitFailed               = errors.New("git command failed")
+	ErrNotGitRepository        = errors.New("not a git repository")
+	ErrDirtyWorkingTree        = errors.New("clean working tree required")
+	ErrRemoteBranchMissing     = errors.New("remote branch is missing")
+	ErrUnpushedCommits         = errors.New("unpushed commits")
+	ErrPushRejected            = errors.New("push rejected")
+	ErrMergeConflict           = errors.New("merge conflict")
+	ErrNotMobProgramming       = errors.New("not in a mob session")
+	ErrCommitMessageRequired   = errors.New("commit message required")
+	ErrOperationInProgress     = errors.New("operation in progress")
+	ErrUndoNotPossible         = errors.New("undo not possible")
+	ErrTimerNotConfigured      = errors.New("timer not configured")
+	ErrTimerServiceUnavailable = goal.ErrServiceUnavailable
+	ErrInvalidArgument         = errors.New("invalid argument")
+	ErrHookFailed              = errors.New("hook failed")
+	ErrVerificationFailed      = errors.New("verification failed")
+)
+
+type Fix struct {
+	Instruction string `json:"instruction"`
+	Command     string `json:"command"`
+}
+
+// MobError tells the user what went wrong and how to fix it. It is rendered once by the top-level handler in run.
+type MobError struct {
+	Kind    error
+	Message string
+	Details []string
+	Fixes   []Fix
+}
+
+func newMobError(kind error, message string, fixes ...Fix) *MobError {
+	return &MobError{Kind: kind, Message: message, Fixes: fixes}
+}
+
+func (e *MobError) Error() string {
+	return e.Message
+}
+
+func (e *MobError) Unwrap() error {
+	return e.Kind
+}
+
+func sayError(err error) {
+	var mobError *MobError
+	if !errors.As(err, &mobError) {
+		say.Error(err.Error())
+		return
+	}
+	say.Error(mobError.Message)
+	for _, detail := range mobError.Details {
+		if detail != "" {
+			say.Error(detail)
+		}
+	}
+	for _, fix := range mobError.Fixes {
+		say.Fix(fix.Instruction, fix.Command)
+	}
+}
+
+// exitCodes is the stable table of exit codes for scripts wrapping mob, see README.md.
+// Any other error exits with 1, success with 0. Only append new entries, never renumber.
+var exitCodes = []struct {
+	kind error
+	code int
+}{
+	{ErrDirtyWorkingTree, 2},
+	{ErrRemoteBranchMissing, 3},
+	{ErrPushRejected, 4},
+	{ErrMergeConflict, 5},
+	{ErrTimerServiceUnavailable, 6},
+	{ErrNotMobProgramming, 7},
+	{ErrCommitMessageRequired, 8},
+	{ErrHookFailed, 9},
+	{ErrVerificationFailed, 10},
+}
+
+func exitCode(err error) int {
+	for _, exitCode := range exitCodes {
+		if errors.Is(err, exitCode.kind) {
+			return exitCode.code
+		}
+	}
+	return 1
+}
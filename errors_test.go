@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSayErrorRendersDetailsAndFixes(t *testing.T) {
+	output := captureOutput(t)
+
+	sayError(&MobError{Kind: ErrDirtyWorkingTree, Message: "cannot start", Details: []string{"", "test.txt"}, Fixes: []Fix{{"To start anyway, use", "mob start --include-uncommitted-changes"}}})
+
+	assertOutputContains(t, output, "ERROR cannot start")
+	assertOutputContains(t, output, "ERROR test.txt")
+	assertOutputContains(t, output, "To start anyway, use")
+	assertOutputContains(t, output, "mob start --include-uncommitted-changes")
+}
+
+func TestSayErrorPlainError(t *testing.T) {
+	output := captureOutput(t)
+
+	sayError(errors.New("something failed"))
+
+	assertOutputContains(t, output, "ERROR something failed")
+}
+
+func TestExitCodeDependsOnKind(t *testing.T) {
+	equals(t, 1, exitCode(errors.New("something failed")))
+	equals(t, 1, exitCode(newMobError(ErrGitFailed, "git failed")))
+	equals(t, 2, exitCode(&MobError{Kind: ErrDirtyWorkingTree}))
+	equals(t, 3, exitCode(&MobError{Kind: ErrRemoteBranchMissing}))
+	equals(t, 4, exitCode(newMobError(ErrPushRejected, "rejected")))
+	equals(t, 5, exitCode(newMobError(ErrMergeConflict, "conflict")))
+	equals(t, 6, exitCode(&MobError{Kind: ErrTimerServiceUnavailable}))
+	equals(t, 7, exitCode(&MobError{Kind: ErrNotMobProgramming}))
+	equals(t, 8, exitCode(&MobError{Kind: ErrCommitMessageRequired}))
+}
+
+func TestRunExitsWithExitCodeOfError(t *testing.T) {
+	output, _ := setup(t)
+	exitCodes := captureExitCodes(t)
+
+	runMob(t, tempDir+"/local", "next")
+	createFile(t, "example.txt", "contentIrrelevant")
+	runMob(t, tempDir+"/local", "start")
+
+	equals(t, []int{7, 2}, *exitCodes)
+	assertOutputContains(t, output, "you are not in a mob session")
+	assertOutputContains(t, output, "mob start --include-uncommitted-changes")
+}
+
+func TestRunDoesNotExitOnSuccess(t *testing.T) {
+	setup(t)
+	t.Setenv("MOB_SKIP_CI_PUSH_OPTION_ENABLED", "false")
+	exitCodes := captureExitCodes(t)
+
+	runMob(t, tempDir+"/local", "start")
+	runMob(t, tempDir+"/local", "next")
+
+	equals(t, 0, len(*exitCodes))
+}
+
+func captureExitCodes(t *testing.T) *[]int {
+	var exitCodes []int
+	originalExit := Exit
+	Exit = func(code int) {
+		exitCodes = append(exitCodes, code)
+	}
+	t.Cleanup(func() { Exit = originalExit })
+	return &exitCodes
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+)
+
+// GitRunner runs git commands. Run is used for commands that change the repository, Query for read-only ones.
+type GitRunner interface {
+	Run(args ...string) (commandString string, output string, err error)
+	Query(args ...string) (commandString string, output string, err error)
+	// Dir is the working directory of the git commands, empty for the current directory
+	Dir() string
+	// In returns a GitRunner for the working directory dir
+	In(dir string) GitRunner
+	// WithEnv returns a GitRunner that adds env, e.g. "GIT_INDEX_FILE=...", to the environment of the git commands
+	WithEnv(env ...string) GitRunner
+}
+
+type execGitRunner struct {
+	dir string
+	env []string
+}
+
+func newExecGitRunner(dir string) GitRunner {
+	return execGitRunner{dir: dir}
+}
+
+func (runner execGitRunner) Run(args ...string) (string, string, error) {
+	if GitPassthroughStderrStdout {
+		return runCommand(runner.dir, runner.env, "git", args...)
+	}
+	return runCommandSilent(runner.dir, runner.env, "git", args...)
+}
+
+func (runner execGitRunner) Query(args ...string) (string, string, error) {
+	return runCommandSilent(runner.dir, runner.env, "git", args...)
+}
+
+func (runner execGitRunner) Dir() string {
+	return runner.dir
+}
+
+func (runner execGitRunner) In(dir string) GitRunner {
+	return execGitRunner{dir: dir, env: runner.env}
+}
+
+func (runner execGitRunner) WithEnv(env ...string) GitRunner {
+	return execGitRunner{dir: runner.dir, env: append(append([]string{}, runner.env...), env...)}
+}
+
+// dryRunGitRunner only runs the read-only git commands, so that the printed plan of a dry run is accurate
+type dryRunGitRunner struct {
+	GitRunner
+}
+
+// dryRunPlaceholders stand in for the hashes the skipped commands would print, so the following commands show where they go
+var dryRunPlaceholders = map[string]string{
+	"write-tree":  "<tree>",
+	"commit-tree": "<commit>",
+}
+
+func (runner dryRunGitRunner) Run(args ...string) (string, string, error) {
+	output := ""
+	if len(args) > 0 {
+		output = dryRunPlaceholders[args[0]]
+	}
+	return "git " + strings.Join(args, " "), output, nil
+}
+
+func (runner dryRunGitRunner) In(dir string) GitRunner {
+	return dryRunGitRunner{runner.GitRunner.In(dir)}
+}
+
+func (runner dryRunGitRunner) WithEnv(env ...string) GitRunner {
+	return dryRunGitRunner{runner.GitRunner.WithEnv(env...)}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"strings"
+	"testing"
+)
+
+// recordingGitRunner records the git commands instead of running them and answers with canned outputs
+type recordingGitRunner struct {
+	dir      string
+	commands []string
+	outputs  map[string]string
+	failures map[string]bool
+}
+
+func newRecordingGitRunner() *recordingGitRunner {
+	return &recordingGitRunner{outputs: map[string]string{}, failures: map[string]bool{}}
+}
+
+func (runner *recordingGitRunner) Run(args ...string) (string, string, error) {
+	commandString := "git " + strings.Join(args, " ")
+	runner.commands = append(runner.commands, commandString)
+	return runner.answer(commandString)
+}
+
+func (runner *recordingGitRunner) Query(args ...string) (string, string, error) {
+	return runner.answer("git " + strings.Join(args, " "))
+}
+
+func (runner *recordingGitRunner) answer(commandString string) (string, string, error) {
+	if runner.failures[commandString] {
+		return commandString, runner.outputs[commandString], errors.New("exit status 1")
+	}
+	return commandString, runner.outputs[commandString], nil
+}
+
+func (runner *recordingGitRunner) Dir() string {
+	return runner.dir
+}
+
+func (runner *recordingGitRunner) In(dir string) GitRunner {
+	return runner
+}
+
+func (runner *recordingGitRunner) WithEnv(env ...string) GitRunner {
+	return runner
+}
+
+func useRecordingGitRunner(t *testing.T) *recordingGitRunner {
+	originalGitRunner := gitRunner
+	runner := newRecordingGitRunner()
+	gitRunner = runner
+	t.Cleanup(func() {
+		gitRunner = originalGitRunner
+	})
+	return runner
+}
+
+func TestFetchRecorded(t *testing.T) {
+	configuration := config.GetDefaultConfiguration()
+	runner := useRecordingGitRunner(t)
+
+	fetch(gitRunner, configuration)
+
+	equals(t, []string{"git fetch origin --prune"}, runner.commands)
+}
+
+func TestGitIgnoreFailureRecorded(t *testing.T) {
+	output := captureOutput(t)
+	runner := useRecordingGitRunner(t)
+	runner.outputs["git rev-parse"] = ""
+	runner.failures["git merge --squash --ff mob-session"] = true
+	runner.outputs["git merge --squash --ff mob-session"] = "CONFLICT (add/add): Merge conflict in example.txt"
+
+	err := gitIgnoreFailure(gitRunner, "merge", "--squash", "--ff", "mob-session")
+
+	assertError(t, err, "exit status 1")
+	assertOutputContains(t, output, "CONFLICT (add/add): Merge conflict in example.txt")
+}
+
+func TestDeleteRemoteWipBranchRecorded(t *testing.T) {
+	captureOutput(t)
+	configuration := config.GetDefaultConfiguration()
+	runner := useRecordingGitRunner(t)
+	runner.outputs["git rev-parse --absolute-git-dir"] = t.TempDir()
+	runner.outputs["git rev-parse --abbrev-ref HEAD"] = "mob-session"
+	runner.outputs["git branch --format=%(refname:short)"] = "master\nmob-session"
+	runner.outputs["git branch --remotes --format=%(refname:short)"] = "origin/master\norigin/mob-session"
+
+	configuration.ResetDeleteRemoteWipBranch = true
+	reset(gitRunner, configuration)
+
+	equals(t, []string{
+		"git fetch origin",
+		"git checkout master",
+		"git branch --delete --force mob-session",
+		"git push --no-verify origin --delete mob-session",
+	}, runner.commands)
+}
+
+func TestDryRunGitRunnerOnlyRunsQueries(t *testing.T) {
+	_, configuration := setup(t)
+	runner := dryRunGitRunner{gitRunner}
+
+	commandString, _, err := runner.Run("checkout", "-b", "mob-session")
+	_, currentBranch, _ := runner.Query("rev-parse", "--abbrev-ref", "HEAD")
+
+	assertNoError(t, err)
+	equals(t, "git checkout -b mob-session", commandString)
+	equals(t, "master\n", currentBranch)
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+}
+
+func TestStageChangesRecorded(t *testing.T) {
+	captureOutput(t)
+	runner := useRecordingGitRunner(t)
+	rootDir := t.TempDir()
+	runner.outputs["git rev-parse --show-toplevel"] = rootDir
+	writeFile(t, rootDir+"/.mobignore", "notes.txt\n")
+
+	err := stageChanges(gitRunner)
+
+	assertNoError(t, err)
+	equals(t, []string{
+		"git add --all",
+		"git reset --quiet -- :(top,glob)**/notes.txt :(top,glob)**/notes.txt/**",
+	}, runner.commands)
+}
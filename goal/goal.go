@@ -8,7 +8,6 @@ import (
 	"github.com/remotemobprogramming/mob/v5/httpclient"
 	"github.com/remotemobprogramming/mob/v5/say"
 	"io"
-	"os"
 	"strings"
 )
 
@@ -25,14 +24,22 @@ type PutGoalRequest struct {
 	User string `json:"user"`
 }
 
-func Goal(configuration config.Configuration, parameter []string) {
-	if err := goal(configuration, parameter); err != nil {
-		say.Error(err.Error())
-		exit(1)
-	}
+// ErrServiceUnavailable is the kind of error returned when timer.mob.sh could not be reached
+var ErrServiceUnavailable = errors.New("timer service unavailable")
+
+type serviceError struct {
+	message string
+}
+
+func (e serviceError) Error() string {
+	return e.message
 }
 
-func goal(configuration config.Configuration, parameter []string) error {
+func (e serviceError) Unwrap() error {
+	return ErrServiceUnavailable
+}
+
+func Goal(configuration config.Configuration, parameter []string) error {
 	if configuration.TimerRoom == "" {
 		return errors.New("No room specified. Set MOB_TIMER_ROOM to your timer.mob.sh room in .mob file.")
 	}
@@ -53,7 +60,7 @@ func goal(configuration config.Configuration, parameter []string) error {
 func setNewGoal(configuration config.Configuration, goal string) error {
 	if err := putGoalHttp(goal, configuration); err != nil {
 		say.Debug(err.Error())
-		return errors.New("Could not set new goal. An error occurred while sending the request.")
+		return serviceError{"Could not set new goal. An error occurred while sending the request."}
 	}
 	say.Info(fmt.Sprintf("Set new goal to \"%s\"", goal))
 	return nil
@@ -77,7 +84,7 @@ func deleteCurrentGoal(configuration config.Configuration) error {
 	err := deleteGoalHttp(configuration.TimerRoom, configuration.TimerUser, configuration.TimerUrl, configuration.TimerInsecure)
 	if err != nil {
 		say.Debug(err.Error())
-		return errors.New("Could not delete goal. An error occurred while sending the request.")
+		return serviceError{"Could not delete goal. An error occurred while sending the request."}
 	}
 	say.Info("Current goal has been deleted!")
 	return nil
@@ -97,7 +104,7 @@ func showGoal(configuration config.Configuration) error {
 	goal, err := getGoalHttp(configuration.TimerRoom, configuration.TimerUrl, configuration.TimerInsecure)
 	if err != nil {
 		say.Debug(err.Error())
-		return errors.New("Could not get goal. An error occurred while sending the request.")
+		return serviceError{"Could not get goal. An error occurred while sending the request."}
 	}
 	if goal == "" {
 		say.Fix("No goal set. To set a goal, use", configuration.Mob("goal <your awesome goal>"))
@@ -106,6 +113,15 @@ func showGoal(configuration config.Configuration) error {
 	say.Info(goal)
 	return nil
 }
+
+// CurrentGoal returns the goal of the configured timer.mob.sh room or an empty string if no room is configured.
+func CurrentGoal(configuration config.Configuration) (string, error) {
+	if configuration.TimerRoom == "" {
+		return "", nil
+	}
+	return getGoalHttp(configuration.TimerRoom, configuration.TimerUrl, configuration.TimerInsecure)
+}
+
 func getGoalHttp(room string, timerService string, disableSslVerification bool) (string, error) {
 	url := timerService + room + "/goal"
 	response, err := httpclient.GetNetHttpClient(disableSslVerification).Get(url)
@@ -131,7 +147,3 @@ func getGoalHttp(room string, timerService string, disableSslVerification bool)
 	}
 	return goalResponse.Goal, nil
 }
-
-var exit = func(code int) {
-	os.Exit(code)
-}
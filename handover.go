@@ -0,0 +1,271 @@
+package main
+
+import (
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// With MOB_HANDOVER=stash there is no wip branch and there are no wip commits. next packages your changes with
+// git stash and pushes the stash commit to refs/mob/handover/<base-branch>, start applies it on the base branch
+// and done applies the last handover for the final commit.
+
+func handoverRef(baseBranch Branch) string {
+	return "refs/mob/handover/" + baseBranch.Name
+}
+
+func hasRemoteHandover(runner GitRunner, configuration config.Configuration, baseBranch Branch) bool {
+	_, _, err := runner.Query("ls-remote", "--exit-code", configuration.RemoteName, handoverRef(baseBranch))
+	return err == nil
+}
+
+func handoverBaseBranch(runner GitRunner, configuration config.Configuration) (Branch, error) {
+	baseBranch, _, err := currentBranches(runner, configuration)
+	return baseBranch, err
+}
+
+// applyHandover applies the last handover to the working tree and returns false if there is none
+func applyHandover(runner GitRunner, configuration config.Configuration, baseBranch Branch) (bool, error) {
+	if !hasRemoteHandover(runner, configuration, baseBranch) {
+		return false, nil
+	}
+	ref := handoverRef(baseBranch)
+	if err := git(runner, "fetch", configuration.RemoteName, "+"+ref+":"+ref); err != nil {
+		return false, err
+	}
+	if err := git(runner, "stash", "apply", gitRefHash(runner, ref)); err != nil {
+		return false, err
+	}
+	author, err := silentgit(runner, "log", "-1", "--pretty=format:%an (%cr)", ref)
+	if err != nil {
+		return false, err
+	}
+	say.Info("applied the handover of " + author)
+	return true, nil
+}
+
+// checkoutBaseBranch switches to the base branch and fast-forwards it to its remote branch
+func checkoutBaseBranch(runner GitRunner, configuration config.Configuration, baseBranch Branch) error {
+	currentBranch, err := gitCurrentBranch(runner)
+	if err != nil {
+		return err
+	}
+	if currentBranch != baseBranch {
+		if err := git(runner, "checkout", baseBranch.Name); err != nil {
+			return err
+		}
+	}
+	return git(runner, "merge", baseBranch.remote(configuration).Name, "--ff-only")
+}
+
+func startHandover(runner GitRunner, configuration config.Configuration) error {
+	if err := failIfOperationInProgress(runner, configuration); err != nil {
+		return err
+	}
+
+	uncommittedChanges, err := hasUncommittedChanges(runner)
+	if err != nil {
+		return err
+	}
+	if uncommittedChanges && configuration.HandleUncommittedChanges == config.FailWithError {
+		if err := sayUnstagedChangesInfo(runner); err != nil {
+			return err
+		}
+		if err := sayUntrackedFilesInfo(runner); err != nil {
+			return err
+		}
+		return newMobError(ErrDirtyWorkingTree, "cannot start; clean working tree required", fixUncommittedChanges(configuration)...)
+	}
+
+	if err := git(runner, "fetch", configuration.RemoteName, "--prune"); err != nil {
+		return err
+	}
+	baseBranch, err := handoverBaseBranch(runner, configuration)
+	if err != nil {
+		return err
+	}
+	hasRemoteBaseBranch, err := baseBranch.hasRemoteBranch(runner, configuration)
+	if err != nil {
+		return err
+	}
+	if !hasRemoteBaseBranch && !configuration.StartCreate {
+		return newMobError(ErrRemoteBranchMissing, "Remote branch "+baseBranch.remote(configuration).String()+" is missing",
+			Fix{"To start and create the remote branch", "mob start --create"})
+	}
+	// stash apply would mix the handover into your changes half-way on a conflict, with nothing to roll back to
+	if uncommittedChanges && configuration.HandleUncommittedChanges == config.IncludeChanges && hasRemoteHandover(runner, configuration, baseBranch) {
+		return newMobError(ErrDirtyWorkingTree, "cannot start; the handover can't be applied on top of uncommitted changes",
+			Fix{"To keep your changes, stash them and try again", "git stash push --include-untracked"},
+			Fix{"To start, discarding uncommitted changes, use", configuration.CliName + " start --discard-uncommitted-changes"})
+	}
+	if err := createRemoteBranch(runner, configuration, baseBranch); err != nil {
+		return err
+	}
+	unpushedCommits, err := baseBranch.hasUnpushedCommits(runner, configuration)
+	if err != nil {
+		return err
+	}
+	if unpushedCommits {
+		return newMobError(ErrUnpushedCommits, "cannot start; unpushed changes on base branch must be pushed upstream",
+			Fix{"to fix this, push those commits and try again", "git push " + configuration.RemoteName + " " + baseBranch.String()})
+	}
+
+	if uncommittedChanges && configuration.HandleUncommittedChanges == config.DiscardChanges {
+		if err := git(runner, "reset", "--hard"); err != nil {
+			return err
+		}
+	}
+	if err := checkoutBaseBranch(runner, configuration, baseBranch); err != nil {
+		return err
+	}
+
+	applied, err := applyHandover(runner, configuration, baseBranch)
+	if err != nil {
+		return err
+	}
+	if applied {
+		message, err := silentgit(runner, "log", "-1", "--pretty=format:%B", handoverRef(baseBranch))
+		if err != nil {
+			return err
+		}
+		author, err := silentgit(runner, "log", "-1", "--pretty=format:%an", handoverRef(baseBranch))
+		if err != nil {
+			return err
+		}
+		sayHandoverNote(author, handoverNoteFromCommitMessage(message))
+		sayVerifyRun(message)
+		if _, err := showHandoverState(runner, configuration, message); err != nil {
+			return err
+		}
+	} else {
+		say.Info("nothing was handed over yet")
+	}
+	say.Info("you are on base branch '" + baseBranch.String() + "' (handover via " + handoverRef(baseBranch) + ")")
+	return nil
+}
+
+func nextHandover(runner GitRunner, configuration config.Configuration) error {
+	if err := failIfOperationInProgress(runner, configuration); err != nil {
+		return err
+	}
+	configuration, err := withHandoverNote(runner, configuration)
+	if err != nil {
+		return err
+	}
+	nothingToCommit, err := isNothingToCommit(runner)
+	if err != nil {
+		return err
+	}
+	if nothingToCommit {
+		say.Info("nothing was done, so nothing to hand over")
+		return nil
+	}
+	if !configuration.HasCustomCommitMessage() && configuration.RequireCommitMessage {
+		return newMobError(ErrCommitMessageRequired, "commit message required",
+			Fix{"To hand over with a commit message, use", configuration.Mob("next --message \"<message>\"")})
+	}
+	configuration, err = withVerifyRun(runner, configuration)
+	if err != nil {
+		return err
+	}
+
+	baseBranch, err := handoverBaseBranch(runner, configuration)
+	if err != nil {
+		return err
+	}
+	ref := handoverRef(baseBranch)
+	message, err := createWipCommitMessage(runner, configuration)
+	if err != nil {
+		return err
+	}
+	pathspec, err := mobIgnorePathspec(runner)
+	if err != nil {
+		return err
+	}
+	if err := git(runner, append([]string{"stash", "push", "--include-untracked", "--message", message}, pathspec...)...); err != nil {
+		return err
+	}
+	if err := git(runner, "update-ref", ref, gitRefHash(runner, "refs/stash")); err != nil {
+		return err
+	}
+	if err := git(runner, "stash", "drop", "--quiet"); err != nil {
+		return err
+	}
+	consumeHandoverState(runner, configuration)
+	if err := gitWithoutEmptyStrings(runner, "push", "--force", gitHooksOption(configuration), configuration.RemoteName, ref+":"+ref); err != nil {
+		return err
+	}
+	say.Info("handed over your changes via " + ref)
+	return nil
+}
+
+func doneHandover(runner GitRunner, configuration config.Configuration) error {
+	if err := failIfOperationInProgress(runner, configuration); err != nil {
+		return err
+	}
+
+	if err := git(runner, "fetch", configuration.RemoteName, "--prune"); err != nil {
+		return err
+	}
+	baseBranch, err := handoverBaseBranch(runner, configuration)
+	if err != nil {
+		return err
+	}
+	if err := checkoutBaseBranch(runner, configuration, baseBranch); err != nil {
+		return err
+	}
+
+	// the typist's working tree already contains the last handover
+	uncommittedChanges, err := hasUncommittedChanges(runner)
+	if err != nil {
+		return err
+	}
+	if !uncommittedChanges {
+		applied, err := applyHandover(runner, configuration, baseBranch)
+		if err != nil {
+			return err
+		}
+		if !applied {
+			say.Info("nothing was handed over, so nothing to finish")
+		}
+	}
+
+	ref := handoverRef(baseBranch)
+	if hasRemoteHandover(runner, configuration, baseBranch) {
+		if err := gitWithoutEmptyStrings(runner, "push", gitHooksOption(configuration), configuration.RemoteName, "--delete", ref); err != nil {
+			return err
+		}
+	}
+	if gitRefHash(runner, ref) != "" {
+		if err := git(runner, "update-ref", "-d", ref); err != nil {
+			return err
+		}
+	}
+
+	headBeforeCommit := gitCommitHash(runner)
+	uncommittedChanges, err = hasUncommittedChanges(runner)
+	if err != nil {
+		return err
+	}
+	if uncommittedChanges {
+		if err := stageChanges(runner); err != nil {
+			return err
+		}
+		cachedChanges, err := getCachedChanges(runner)
+		if err != nil {
+			return err
+		}
+		say.InfoIndented(cachedChanges)
+		if !configuration.DoneCommit {
+			say.Next("To finish, use", "git commit")
+			return nil
+		}
+		if err := commitDone(runner, configuration); err != nil {
+			return err
+		}
+	}
+
+	if configuration.DonePush {
+		return pushDone(runner, configuration, baseBranch, headBeforeCommit)
+	}
+	return nil
+}
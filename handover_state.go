@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// The handover state describes where the typist was: the open files with cursor positions and the last terminal command.
+// An editor plugin writes it as JSON to MOB_HANDOVER_STATE (or sends it with next via mob serve), next stores it in the
+// wip commit message and start reopens the files for the next typist.
+
+const handoverStatePrefix = "handoverState:"
+
+type handoverState struct {
+	Files    []handoverFile `json:"files"`
+	Terminal string         `json:"terminal,omitempty"`
+}
+
+type handoverFile struct {
+	Path   string `json:"path"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+func (f handoverFile) String() string {
+	position := f.Path
+	if f.Line > 0 {
+		position += ":" + strconv.Itoa(f.Line)
+		if f.Column > 0 {
+			position += ":" + strconv.Itoa(f.Column)
+		}
+	}
+	return position
+}
+
+func handoverStatePath(runner GitRunner, configuration config.Configuration) (string, error) {
+	if configuration.HandoverState != "" {
+		return configuration.HandoverState, nil
+	}
+	gitDir, err := gitDir(runner)
+	return gitDir + "/mob-handover-state.json", err
+}
+
+// readHandoverState returns the state written by the editor, with all paths relative to the root directory
+func readHandoverState(runner GitRunner, configuration config.Configuration) (state handoverState, ok bool, err error) {
+	path, err := handoverStatePath(runner, configuration)
+	if err != nil {
+		return handoverState{}, false, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return handoverState{}, false, nil
+	}
+	if err := json.Unmarshal(content, &state); err != nil {
+		say.Warning("ignoring the handover state in " + path + ": " + err.Error())
+		return handoverState{}, false, nil
+	}
+
+	rootDir, err := gitRootDir(runner)
+	if err != nil {
+		return handoverState{}, false, err
+	}
+	var files []handoverFile
+	for _, file := range state.Files {
+		if filepath.IsAbs(file.Path) {
+			relativePath, err := filepath.Rel(rootDir, file.Path)
+			if err != nil || strings.HasPrefix(relativePath, "..") {
+				say.Debug("ignoring " + file.Path + " outside of " + rootDir)
+				continue
+			}
+			file.Path = filepath.ToSlash(relativePath)
+		}
+		files = append(files, file)
+	}
+	state.Files = files
+	return state, len(state.Files) > 0 || state.Terminal != "", nil
+}
+
+func writeHandoverState(runner GitRunner, configuration config.Configuration, state handoverState) error {
+	content, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	path, err := handoverStatePath(runner, configuration)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+// consumeHandoverState removes the state after the handover, so it is not handed over twice
+func consumeHandoverState(runner GitRunner, configuration config.Configuration) {
+	if path, err := handoverStatePath(runner, configuration); err == nil {
+		os.Remove(path)
+	}
+}
+
+func handoverStateCommitMessage(runner GitRunner, configuration config.Configuration) (string, error) {
+	state, ok, err := readHandoverState(runner, configuration)
+	if err != nil || !ok {
+		return "", err
+	}
+	content, _ := json.Marshal(state)
+	return handoverStatePrefix + string(content), nil
+}
+
+func handoverStateFromCommitMessage(message string) (handoverState, bool) {
+	for _, line := range strings.Split(message, "\n") {
+		if !strings.HasPrefix(line, handoverStatePrefix) {
+			continue
+		}
+		var state handoverState
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, handoverStatePrefix)), &state); err != nil {
+			say.Warning("Could not read the handover state from commit message: " + err.Error())
+			return handoverState{}, false
+		}
+		return state, true
+	}
+	return handoverState{}, false
+}
+
+// showHandoverState tells where the previous typist was and reopens their files, returns false if there is no state
+func showHandoverState(runner GitRunner, configuration config.Configuration, message string) (bool, error) {
+	state, ok := handoverStateFromCommitMessage(message)
+	if !ok {
+		return false, nil
+	}
+	for _, file := range state.Files {
+		say.Info("the previous typist had " + file.String() + " open")
+	}
+	if state.Terminal != "" {
+		say.Info("the previous typist last ran '" + state.Terminal + "'")
+	}
+	if !configuration.IsOpenCommandGiven() {
+		return true, nil
+	}
+	rootDir, err := gitRootDir(runner)
+	if err != nil {
+		return true, err
+	}
+	for _, file := range state.Files {
+		// the state comes from a commit anyone can push, so only open files inside the repository
+		relativePath := filepath.Clean(filepath.FromSlash(file.Path))
+		if !filepath.IsLocal(relativePath) {
+			say.Warning("not opening " + file.Path + ", it is outside of the repository")
+			continue
+		}
+		path := filepath.Join(rootDir, relativePath)
+		if _, err := os.Stat(path); err != nil {
+			say.Debug("not opening " + path + ", it doesn't exist")
+			continue
+		}
+		openInEditor(runner, configuration, path, file.Line, file.Column)
+	}
+	return true, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+)
+
+func TestNextHandsOverEditorState(t *testing.T) {
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "file.txt", "contentIrrelevant")
+	writeFile(t, gitOutput(t, "rev-parse", "--absolute-git-dir")+"/mob-handover-state.json",
+		`{"files":[{"path":"file.txt","line":12,"column":4},{"path":"`+tempDir+`/local/README.md","line":1}],"terminal":"go test ./..."}`)
+
+	next(gitRunner, configuration)
+
+	assertCommitMessageContains(t, "origin/mob-session", `handoverState:{"files":[{"path":"file.txt","line":12,"column":4},{"path":"README.md","line":1}],"terminal":"go test ./..."}`)
+	assertCommitMessageContains(t, "origin/mob-session", "lastFile:file.txt")
+	if _, err := os.Stat(tempDir + "/local/.git/mob-handover-state.json"); err == nil {
+		t.Error("the handover state must be removed after the handover")
+	}
+
+	setWorkingDir(tempDir + "/localother")
+	start(gitRunner, configuration)
+
+	assertOutputContains(t, output, "the previous typist had file.txt:12:4 open")
+	assertOutputContains(t, output, "the previous typist had README.md:1 open")
+	assertOutputContains(t, output, "the previous typist last ran 'go test ./...'")
+}
+
+func TestNextIgnoresInvalidEditorState(t *testing.T) {
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "file.txt", "contentIrrelevant")
+	writeFile(t, gitOutput(t, "rev-parse", "--absolute-git-dir")+"/mob-handover-state.json", `not json`)
+
+	next(gitRunner, configuration)
+
+	assertOutputContains(t, output, "ignoring the handover state in")
+	equals(t, configuration.WipCommitMessage+"\n\nlastLine:1\nlastFile:file.txt", gitOutput(t, "log", "-1", "--pretty=format:%B", "origin/mob-session"))
+}
+
+func TestStashHandoverHandsOverEditorState(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.Handover = config.HandoverStash
+	configuration.HandoverState = tempDir + "/state.json"
+	start(gitRunner, configuration)
+	createFile(t, "file.txt", "contentIrrelevant")
+	writeFile(t, tempDir+"/state.json", `{"files":[{"path":"file.txt","line":3}]}`)
+	next(gitRunner, configuration)
+
+	setWorkingDir(tempDir + "/localother")
+	start(gitRunner, configuration)
+
+	assertOutputContains(t, output, "the previous typist had file.txt:3 open")
+}
+
+func TestServeNextWithEditorState(t *testing.T) {
+	_, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "file.txt", "contentIrrelevant")
+
+	responses := serveRequests(t, newServer(gitRunner, configuration, &bytes.Buffer{}),
+		`{"jsonrpc":"2.0","id":1,"method":"next","params":{"state":{"files":[{"path":"file.txt","line":7}]}}}`)
+
+	equals(t, (*rpcError)(nil), responses[0].Error)
+	assertCommitMessageContains(t, "origin/mob-session", `handoverState:{"files":[{"path":"file.txt","line":7}]}`)
+}
+
+func TestShowHandoverStateOpensOnlyFilesInsideTheRepository(t *testing.T) {
+	output, configuration := setup(t)
+	createFile(t, "file.txt", "contentIrrelevant")
+	writeFile(t, tempDir+"/secret.txt", "contentIrrelevant")
+	configuration.OpenCommand = "code -g %s:%l"
+	enableDryRun(t)
+
+	shown, err := showHandoverState(gitRunner, configuration,
+		`handoverState:{"files":[{"path":"../secret.txt","line":1},{"path":"`+tempDir+`/secret.txt","line":1},{"path":"sub/../file.txt","line":2}]}`)
+
+	assertNoError(t, err)
+	equals(t, true, shown)
+	assertOutputContains(t, output, "not opening ../secret.txt, it is outside of the repository")
+	assertOutputContains(t, output, "not opening "+tempDir+"/secret.txt, it is outside of the repository")
+	assertOutputContains(t, output, "code -g "+tempDir+"/local/file.txt:2")
+	assertOutputNotContains(t, output, "code -g "+tempDir+"/secret.txt")
+}
+
+func assertCommitMessageContains(t *testing.T, commit string, contains string) {
+	message := gitOutput(t, "log", "-1", "--pretty=format:%B", commit)
+	if !strings.Contains(message, contains) {
+		t.Errorf("expected commit message of %s to contain %s, but was %s", commit, contains, message)
+	}
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+)
+
+func setupStashHandover(t *testing.T) (*string, config.Configuration) {
+	output, configuration := setup(t)
+	configuration.Handover = config.HandoverStash
+	return output, configuration
+}
+
+func TestStashHandoverToNextTypist(t *testing.T) {
+	output, configuration := setupStashHandover(t)
+	assertNoError(t, start(gitRunner, configuration))
+	createFile(t, "example.txt", "contentIrrelevant")
+	createFile(t, "test.txt", "changed")
+
+	assertNoError(t, next(gitRunner, configuration))
+
+	assertOnBranch(t, "master")
+	assertCleanGitStatus(t)
+	equals(t, "", gitOutput(t, "stash", "list"))
+	equals(t, []string{"origin/master"}, remoteBranches(t))
+	assertOutputContains(t, output, "handed over your changes via refs/mob/handover/master")
+
+	setWorkingDir(tempDir + "/localother")
+	assertNoError(t, start(gitRunner, configuration))
+
+	assertOnBranch(t, "master")
+	equals(t, []string{"master"}, localBranches(t))
+	assertGitStatus(t, GitStatus{
+		"example.txt": "??",
+		"test.txt":    "M",
+	})
+	assertOutputContains(t, output, "applied the handover of local")
+}
+
+func TestStashHandoverStartIncludeUncommittedChangesRefusesToApplyTheHandover(t *testing.T) {
+	_, configuration := setupStashHandover(t)
+	assertNoError(t, start(gitRunner, configuration))
+	createFile(t, "example.txt", "handed over")
+	assertNoError(t, next(gitRunner, configuration))
+
+	setWorkingDir(tempDir + "/localother")
+	createFile(t, "example.txt", "local edit")
+	configuration.HandleUncommittedChanges = config.IncludeChanges
+	err := start(gitRunner, configuration)
+
+	assertErrorIs(t, err, ErrDirtyWorkingTree)
+	assertFix(t, err, "git stash push --include-untracked")
+	assertGitStatus(t, GitStatus{"example.txt": "??"})
+	content, _ := os.ReadFile(tempDir + "/localother/example.txt")
+	equals(t, "local edit", string(content))
+}
+
+func TestStashHandoverIsCumulative(t *testing.T) {
+	_, configuration := setupStashHandover(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+
+	setWorkingDir(tempDir + "/localother")
+	start(gitRunner, configuration)
+	createFile(t, "other.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+
+	setWorkingDir(tempDir + "/local")
+	start(gitRunner, configuration)
+
+	assertGitStatus(t, GitStatus{
+		"example.txt": "??",
+		"other.txt":   "??",
+	})
+}
+
+func TestStashHandoverNothingToHandOver(t *testing.T) {
+	output, configuration := setupStashHandover(t)
+	start(gitRunner, configuration)
+
+	assertNoError(t, next(gitRunner, configuration))
+
+	assertOutputContains(t, output, "nothing was done, so nothing to hand over")
+	remoteRef, _ := silentgitignorefailure(gitRunner, "ls-remote", "origin", "refs/mob/handover/master")
+	equals(t, "", remoteRef)
+}
+
+func TestStashHandoverDone(t *testing.T) {
+	_, configuration := setupStashHandover(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+
+	setWorkingDir(tempDir + "/localother")
+	assertNoError(t, done(gitRunner, configuration))
+
+	assertOnBranch(t, "master")
+	assertGitStatus(t, GitStatus{
+		"example.txt": "A",
+	})
+	remoteRef, _ := silentgitignorefailure(gitRunner, "ls-remote", "origin", "refs/mob/handover/master")
+	equals(t, "", remoteRef)
+}
+
+func TestStashHandoverDoneByTypist(t *testing.T) {
+	_, configuration := setupStashHandover(t)
+	configuration.DoneCommit = true
+	configuration.DoneCommitMessage = "final"
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+
+	setWorkingDir(tempDir + "/localother")
+	start(gitRunner, configuration)
+	createFile(t, "other.txt", "contentIrrelevant")
+	assertNoError(t, done(gitRunner, configuration))
+
+	assertCleanGitStatus(t)
+	equals(t, "final", gitOutput(t, "log", "-1", "--pretty=format:%s"))
+	equals(t, "example.txt\nother.txt", gitOutput(t, "diff", "--name-only", "HEAD^", "HEAD"))
+}
+
+func TestStashHandoverStartViaExecute(t *testing.T) {
+	output, configuration := setupStashHandover(t)
+
+	assertNoError(t, execute(gitRunner, "start", []string{}, configuration))
+
+	assertOnBranch(t, "master")
+	assertOutputContains(t, output, "Happy collaborating!")
+}
@@ -14,6 +14,10 @@ Basic Commands:
   done               Squash all changes in wip branch to index in base branch
   reset              Remove local and remote wip branch
   clean              Removes all orphan wip branches
+  recover            Shows the interrupted start, next, done or reset
+  undo               Restores the state before the last start, next, done or reset
+  wait               Waits until someone else handed over and you are (probably) next
+  restore            Restores the last autosave of your turn as uncommitted changes
 
 Basic Commands with Options:
   start [<minutes>]                      Start <minutes> minutes timer
@@ -27,12 +31,25 @@ Basic Commands with Options:
     [--stay|-s]                          Stay on wip branch (default)
     [--return-to-base-branch|-r]         Return to base branch
     [--message|-m <commit-message>]      Override commit message
+    [--note [<note>]]                    Leave a note for the next typist (opens the git editor without <note>)
+    [--no-verify-run]                    Hand over without running MOB_NEXT_VERIFY_COMMAND
   done
     [--no-squash]                        Squash no commits from wip branch, only merge wip branch
     [--squash]                           Squash all commits from wip branch
     [--squash-wip]                       Squash wip commits from wip branch, maintaining manual commits
+    [--commit]                           Commit the changes in base branch, including co-authors
+    [--message|-m <commit-message>]      Use <commit-message> for the final commit
+    [--push]                             Commit the changes and push the base branch
+    [--pull-request]                     Push changes to a feature branch and open a pull request instead
+    [--continue]                         Finish done after resolving merge conflicts
+    [--abort]                            Undo done after merge conflicts and return to the wip branch
   reset
     [--branch|-b <branch-postfix>]       Set wip branch to 'mob/<base-branch>` + configuration.WipBranchQualifierSeparator + `<branch-postfix>'
+  recover
+    [--resume]                           Run the remaining steps of the interrupted command
+    [--rollback]                         Restore the state from before the interrupted command
+  wait
+    [--start [<minutes>]]                Start the session when it's your turn
 
 Timer Commands:
   timer <minutes>           Start a <minutes> timer
@@ -62,9 +79,14 @@ Get more information:
   help               Show help
 
 Other
+  serve --stdio      Serve start, next, done, status, timer and goal as JSON-RPC for editors
+  webhook <event>    Post the start, next, done or timer event to MOB_WEBHOOK_URL
+  notify <message>   Show a desktop notification, '--start' adds the button "Start my turn"
   moo                Moo!
 
 Add '--debug' to any option to enable verbose logging.
+Add '--dry-run' to any command except 'wait' and 'serve' to show the git commands that change something instead of running them.
+Add '--json' or '--output=json' to 'status', 'branch', 'config' or 'version' to get the output as JSON.
 Need more help? Join the community at slack.mob.sh
 `
 	say.Say(output)
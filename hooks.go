@@ -0,0 +1,177 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// Hooks run your own commands before and after start, next, done and reset, and when the timer ends, e.g. a formatter
+// before next. A hook is the command in MOB_HOOK_<HOOK> or an executable mob-<hook> in the git hooks directory, which a
+// team can share with core.hooksPath. The .mob file of a project can't configure hooks, like it can't configure
+// MOB_VOICE_COMMAND, because cloning a repository shouldn't make mob run its commands.
+
+// hookEvent describes what happened, the hook gets it as MOB_EVENT_* environment variables
+type hookEvent struct {
+	BaseBranch Branch
+	WipBranch  Branch
+	User       string
+	NextTypist string
+	Commit     string
+}
+
+func newHookEvent(runner GitRunner, command string, configuration config.Configuration) (hookEvent, error) {
+	if !isGit(runner) {
+		return hookEvent{User: gitUserName(runner)}, nil
+	}
+	baseBranch, wipBranch, err := currentBranches(runner, configuration)
+	if err != nil {
+		return hookEvent{}, err
+	}
+	event := hookEvent{BaseBranch: baseBranch, WipBranch: wipBranch, User: gitUserName(runner)}
+	event.update(runner, command)
+	return event, nil
+}
+
+// update reads the commit and the next typist again, after the command changed them. The event is only information,
+// so what can't be read stays empty.
+func (event *hookEvent) update(runner GitRunner, command string) {
+	event.Commit = gitCommitHash(runner)
+	if hasLocalBranch, err := event.WipBranch.hasLocalBranch(runner); command != "next" || err != nil || !hasLocalBranch {
+		return
+	}
+	// next may have returned to the base branch, but the handover is the last commit on the wip branch
+	event.Commit = gitRefHash(runner, event.WipBranch.Name)
+	if event.User != "" {
+		event.NextTypist, _, _ = determineNextTypist(runner, event.BaseBranch, event.WipBranch, event.User)
+	}
+}
+
+func (event hookEvent) environment(hook string) []string {
+	return []string{
+		"MOB_EVENT=" + hook,
+		"MOB_EVENT_BASE_BRANCH=" + event.BaseBranch.Name,
+		"MOB_EVENT_WIP_BRANCH=" + event.WipBranch.Name,
+		"MOB_EVENT_USER=" + event.User,
+		"MOB_EVENT_NEXT_TYPIST=" + event.NextTypist,
+		"MOB_EVENT_COMMIT=" + event.Commit,
+	}
+}
+
+// hookCommand returns the command to run for the hook, ok is false if there is no hook
+func hookCommand(runner GitRunner, hook string, configuration config.Configuration) (name string, args []string, ok bool) {
+	if command := strings.TrimSpace(configuration.HookCommand(hook)); command != "" {
+		name, args = shellCommand(command)
+		return name, args, true
+	}
+	if !isGit(runner) {
+		return "", nil, false
+	}
+	hooksDir, err := gitHooksDir(runner)
+	if err != nil {
+		say.Debug("no " + hook + " hook, the git hooks directory is unknown: " + err.Error())
+		return "", nil, false
+	}
+	path := filepath.Join(hooksDir, "mob-"+hook)
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || (runtime.GOOS != "windows" && info.Mode()&0111 == 0) {
+		return "", nil, false
+	}
+	return path, nil, true
+}
+
+// shellCommand runs a configured command line with sh, or with PowerShell on Windows
+func shellCommand(command string) (name string, args []string) {
+	if runtime.GOOS == "windows" {
+		return "powershell", []string{"-command", command}
+	}
+	return "sh", []string{"-c", command}
+}
+
+func gitHooksDir(runner GitRunner) (string, error) {
+	dir, err := silentgit(runner, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(runner.Dir(), dir)
+	}
+	return dir, nil
+}
+
+// runHook runs the hook in the root directory and passes its output through
+func runHook(runner GitRunner, hook string, event hookEvent, configuration config.Configuration) *MobError {
+	name, args, ok := hookCommand(runner, hook, configuration)
+	if !ok {
+		return nil
+	}
+	commandLine := strings.Join(append([]string{name}, args...), " ")
+	if DryRun {
+		say.Indented("run the " + hook + " hook <" + commandLine + ">")
+		return nil
+	}
+	say.Info("running the " + hook + " hook")
+	rootDir, err := gitRootDir(runner)
+	if err == nil {
+		_, _, err = runCommand(rootDir, event.environment(hook), name, args...)
+	}
+	if err != nil {
+		return &MobError{Kind: ErrHookFailed, Message: "the " + hook + " hook failed", Details: []string{err.Error()},
+			Fixes: []Fix{{"To see what failed, run the hook yourself", commandLine}}}
+	}
+	return nil
+}
+
+// runWithHooks runs the pre hook, the command, the post hook and sends the webhook. A failing pre hook aborts the
+// command, a failing post hook only warns because the command already did its job.
+func runWithHooks(runner GitRunner, command string, configuration config.Configuration, run func() error) error {
+	event, err := newHookEvent(runner, command, configuration)
+	if err != nil {
+		return err
+	}
+	if err := runHook(runner, "pre-"+command, event, configuration); err != nil {
+		err.Message = "aborted '" + configuration.Mob(command) + "' because " + err.Message
+		return err
+	}
+	if err := run(); err != nil {
+		return err
+	}
+	event.update(runner, command)
+	if err := runHook(runner, "post-"+command, event, configuration); err != nil {
+		say.Warning(err.Message + " (" + strings.Join(err.Details, ", ") + ")")
+	}
+	sendWebhook(runner, command, event, configuration)
+	return nil
+}
+
+// timerHookCommand returns the timer hook as one of the background commands of the local timer, or "" if there is none.
+// The environment variables of the event aren't set on Windows.
+func timerHookCommand(runner GitRunner, configuration config.Configuration) (string, error) {
+	name, args, ok := hookCommand(runner, "timer", configuration)
+	if !ok {
+		return "", nil
+	}
+	if runtime.GOOS == "windows" {
+		if len(args) > 0 {
+			return args[len(args)-1], nil
+		}
+		return name, nil
+	}
+	event, err := newHookEvent(runner, "timer", configuration)
+	if err != nil {
+		return "", err
+	}
+	var words []string
+	for _, variable := range event.environment("timer") {
+		key, value, _ := strings.Cut(variable, "=")
+		words = append(words, key+"="+escapeForShell(value, 0))
+	}
+	for _, word := range append([]string{name}, args...) {
+		words = append(words, escapeForShell(word, 0))
+	}
+	return strings.Join(words, " "), nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPreAndPostNextHooksGetTheEvent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks in the tests use sh")
+	}
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	configuration.HookPreNext = `echo "$MOB_EVENT on $MOB_EVENT_WIP_BRANCH from $MOB_EVENT_BASE_BRANCH by $MOB_EVENT_USER"`
+	configuration.HookPostNext = `echo "$MOB_EVENT at $MOB_EVENT_COMMIT"`
+
+	err := execute(gitRunner, "next", []string{}, configuration)
+
+	assertNoError(t, err)
+	assertOutputContains(t, output, "running the pre-next hook")
+	assertOutputContains(t, output, "pre-next on mob-session from master by local")
+	assertOutputContains(t, output, "post-next at "+gitRefHash(gitRunner, "mob-session"))
+}
+
+func TestPreNextHookChangesAreCommitted(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks in the tests use sh")
+	}
+	_, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	configuration.HookPreNext = "echo formatted > formatted.txt"
+
+	err := execute(gitRunner, "next", []string{}, configuration)
+
+	assertNoError(t, err)
+	equals(t, "formatted.txt", gitOutput(t, "show", "--name-only", "--pretty=format:", "origin/mob-session", "--", "formatted.txt"))
+}
+
+func TestFailingPreNextHookAbortsNext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks in the tests use sh")
+	}
+	_, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	configuration.HookPreNext = "exit 1"
+
+	err := execute(gitRunner, "next", []string{}, configuration)
+
+	assertErrorIs(t, err, ErrHookFailed)
+	equals(t, "aborted 'mob next' because the pre-next hook failed", err.Error())
+	equals(t, 9, exitCode(err))
+	assertOnBranch(t, "mob-session")
+	assertGitStatus(t, GitStatus{"example.txt": "??"})
+}
+
+func TestFailingPostNextHookOnlyWarns(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks in the tests use sh")
+	}
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	configuration.HookPostNext = "exit 1"
+
+	err := execute(gitRunner, "next", []string{}, configuration)
+
+	assertNoError(t, err)
+	assertOnBranch(t, "master")
+	assertOutputContains(t, output, "the post-next hook failed")
+}
+
+func TestPostNextHookGetsNextTypist(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks in the tests use sh")
+	}
+	output, configuration := setup(t)
+	setWorkingDir(tempDir + "/alice")
+	start(gitRunner, configuration)
+	createFile(t, "alice.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+	setWorkingDir(tempDir + "/local")
+	start(gitRunner, configuration)
+	createFile(t, "local.txt", "contentIrrelevant")
+	configuration.HookPostNext = `echo "next is $MOB_EVENT_NEXT_TYPIST"`
+
+	err := execute(gitRunner, "next", []string{}, configuration)
+
+	assertNoError(t, err)
+	assertOutputContains(t, output, "next is alice")
+}
+
+func TestStartAndDoneRunHookScriptsFromGitHooksDirectory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks in the tests use sh")
+	}
+	output, configuration := setup(t)
+	writeFile(t, gitHooksDirectory(t)+"/mob-post-start", "#!/bin/sh\necho \"script $MOB_EVENT\"\n")
+	writeFile(t, gitHooksDirectory(t)+"/mob-pre-done", "#!/bin/sh\necho \"script $MOB_EVENT\"\n")
+	os.Chmod(gitHooksDirectory(t)+"/mob-post-start", 0755)
+	os.Chmod(gitHooksDirectory(t)+"/mob-pre-done", 0755)
+
+	assertNoError(t, execute(gitRunner, "start", []string{}, configuration))
+	assertNoError(t, execute(gitRunner, "done", []string{}, configuration))
+
+	assertOutputContains(t, output, "script post-start")
+	assertOutputContains(t, output, "script pre-done")
+}
+
+func TestHookScriptMustBeExecutable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("windows has no executable bit")
+	}
+	output, configuration := setup(t)
+	writeFile(t, gitHooksDirectory(t)+"/mob-pre-start", "#!/bin/sh\nexit 1\n")
+
+	assertNoError(t, execute(gitRunner, "start", []string{}, configuration))
+
+	assertOutputNotContains(t, output, "running the pre-start hook")
+}
+
+func TestResetRunsHooksOnlyWhenDeletingTheWipBranch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks in the tests use sh")
+	}
+	output, configuration := setup(t)
+	configuration.HookPreReset = "echo resetting"
+
+	assertNoError(t, execute(gitRunner, "reset", []string{}, configuration))
+	assertOutputNotContains(t, output, "resetting")
+
+	configuration.ResetDeleteRemoteWipBranch = true
+	assertNoError(t, execute(gitRunner, "reset", []string{}, configuration))
+	assertOutputContains(t, output, "resetting")
+}
+
+func TestHooksDontRunInDryRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks in the tests use sh")
+	}
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	configuration.HookPreNext = "echo formatting"
+	enableDryRun(t)
+
+	err := execute(gitRunner, "next", []string{}, configuration)
+
+	assertNoError(t, err)
+	assertOutputContains(t, output, "run the pre-next hook <sh -c echo formatting>")
+	assertOutputNotContains(t, output, "running the pre-next hook")
+}
+
+func TestTimerHookCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the timer runs the hook via powershell")
+	}
+	_, configuration := setup(t)
+	configuration.HookTimer = "echo 'time is up'"
+
+	command, err := timerHookCommand(gitRunner, configuration)
+	assertNoError(t, err)
+
+	if !strings.HasPrefix(command, "MOB_EVENT='timer' MOB_EVENT_BASE_BRANCH='master'") {
+		t.Error("expected the event as environment variables, got " + command)
+	}
+	if !strings.HasSuffix(command, ` 'sh' '-c' 'echo '\''time is up'\'''`) {
+		t.Error("expected the hook command, got " + command)
+	}
+	equals(t, "time is up\n", runShell(t, command))
+	configuration.HookTimer = ""
+	command, err = timerHookCommand(gitRunner, configuration)
+	assertNoError(t, err)
+	equals(t, "", command)
+}
+
+func gitHooksDirectory(t *testing.T) string {
+	t.Helper()
+	dir, err := gitHooksDir(gitRunner)
+	assertNoError(t, err)
+	return dir
+}
@@ -18,6 +18,7 @@ type Client interface {
 
 type HttpClient struct {
 	netHttpClient *http.Client
+	silent        bool
 }
 
 func CreateHttpClient(disableSSLVerification bool) HttpClient {
@@ -36,8 +37,22 @@ func GetNetHttpClient(disableSSLVerification bool) *http.Client {
 	return http.DefaultClient
 }
 
+// Silent returns a client that doesn't print requests and responses, for URLs that contain a secret
+func (c HttpClient) Silent() HttpClient {
+	c.silent = true
+	return c
+}
+
+func (c HttpClient) say(s string) {
+	if c.silent {
+		say.Debug(s)
+	} else {
+		say.Info(s)
+	}
+}
+
 func (c HttpClient) SendRequest(requestBody []byte, requestMethod string, requestUrl string) (string, error) {
-	say.Info(requestMethod + " " + requestUrl + " " + string(requestBody))
+	c.say(requestMethod + " " + requestUrl + " " + string(requestBody))
 
 	responseBody := bytes.NewBuffer(requestBody)
 	request, requestCreationError := http.NewRequest(requestMethod, requestUrl, responseBody)
@@ -75,7 +90,7 @@ func (c HttpClient) SendRequest(requestBody []byte, requestMethod string, reques
 		return "", fmt.Errorf("failed to read the http response: %w", responseReadingErr)
 	}
 	if string(body) != "" {
-		say.Info(body)
+		c.say(body)
 	}
 	return body, nil
 }
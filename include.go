@@ -0,0 +1,148 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// Git-ignored files that match a pattern in .mobinclude, e.g. a local .env, are handed over as well.
+// next pushes them as the only content of the side ref refs/mob/include/<wip-branch>, so the wip branch stays clean.
+// start writes them to the working tree, done and reset delete the side ref.
+
+const mobIncludeFile = ".mobinclude"
+
+func includeRef(wipBranch Branch) string {
+	return "refs/mob/include/" + wipBranch.Name
+}
+
+func hasMobIncludeFile(runner GitRunner) bool {
+	rootDir, err := gitRootDir(runner)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(rootDir + "/" + mobIncludeFile)
+	return err == nil
+}
+
+// includedFiles returns the git-ignored files matching .mobinclude, relative to the root directory
+func includedFiles(runner GitRunner, rootDir string) ([]string, error) {
+	rootRunner := runner.In(rootDir)
+	ignored, err := silentgit(rootRunner, "ls-files", "-z", "--others", "--ignored", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	matching, err := silentgit(rootRunner, "ls-files", "-z", "--others", "--ignored", "--exclude-from="+mobIncludeFile)
+	if err != nil {
+		return nil, err
+	}
+
+	ignoredFiles := splitNulTerminated(ignored)
+	var files []string
+	for _, file := range splitNulTerminated(matching) {
+		if contains(ignoredFiles, file) {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+func splitNulTerminated(output string) []string {
+	var result []string
+	for _, entry := range strings.Split(output, "\x00") {
+		if entry != "" {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+func pushIncludedFiles(runner GitRunner, configuration config.Configuration, wipBranch Branch) error {
+	rootDir, err := gitRootDir(runner)
+	if err != nil {
+		return err
+	}
+	files, err := includedFiles(runner, rootDir)
+	if err != nil || len(files) == 0 {
+		return err
+	}
+
+	gitDir, err := gitDir(runner)
+	if err != nil {
+		return err
+	}
+	indexFile := gitDir + "/mob-include-index"
+	defer os.Remove(indexFile)
+	indexRunner := runner.In(rootDir).WithEnv("GIT_INDEX_FILE=" + indexFile)
+	if _, err := silentgitChange(indexRunner, append([]string{"add", "--force", "--"}, files...)...); err != nil {
+		return err
+	}
+	tree, err := silentgitChange(indexRunner, "write-tree")
+	if err != nil {
+		return err
+	}
+	commit, err := silentgitChange(runner, "commit-tree", tree, "-m", "mob include for "+wipBranch.Name)
+	if err != nil {
+		return err
+	}
+
+	ref := includeRef(wipBranch)
+	if err := git(runner, "update-ref", ref, commit); err != nil {
+		return err
+	}
+	if err := gitWithoutEmptyStrings(runner, "push", "--force", gitHooksOption(configuration), configuration.RemoteName, ref+":"+ref); err != nil {
+		return err
+	}
+	say.Info("handed over " + strconv.Itoa(len(files)) + " files listed in " + mobIncludeFile)
+	return nil
+}
+
+// restoreIncludedFiles overwrites the included files in the working tree, the index stays untouched
+func restoreIncludedFiles(runner GitRunner, configuration config.Configuration, wipBranch Branch) error {
+	ref := includeRef(wipBranch)
+	if _, _, err := runner.Query("ls-remote", "--exit-code", configuration.RemoteName, ref); err != nil {
+		return nil
+	}
+	if err := git(runner, "fetch", configuration.RemoteName, "+"+ref+":"+ref); err != nil {
+		return err
+	}
+
+	gitDir, err := gitDir(runner)
+	if err != nil {
+		return err
+	}
+	rootDir, err := gitRootDir(runner)
+	if err != nil {
+		return err
+	}
+	indexFile := gitDir + "/mob-include-index"
+	defer os.Remove(indexFile)
+	indexRunner := runner.In(rootDir).WithEnv("GIT_INDEX_FILE=" + indexFile)
+	if err := git(indexRunner, "read-tree", ref); err != nil {
+		return err
+	}
+	if err := git(indexRunner, "checkout-index", "--all", "--force"); err != nil {
+		return err
+	}
+	say.Info("restored the files listed in " + mobIncludeFile)
+	return nil
+}
+
+func deleteIncludedFiles(runner GitRunner, configuration config.Configuration, wipBranch Branch) error {
+	ref := includeRef(wipBranch)
+	if !hasMobIncludeFile(runner) && gitRefHash(runner, ref) == "" {
+		return nil
+	}
+	if _, _, err := runner.Query("ls-remote", "--exit-code", configuration.RemoteName, ref); err == nil {
+		if err := gitWithoutEmptyStrings(runner, "push", gitHooksOption(configuration), configuration.RemoteName, "--delete", ref); err != nil {
+			return err
+		}
+	}
+	if gitRefHash(runner, ref) != "" {
+		return git(runner, "update-ref", "-d", ref)
+	}
+	return nil
+}
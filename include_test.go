@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func setupMobInclude(t *testing.T) {
+	createFile(t, ".gitignore", ".env\nnotes.txt\n")
+	createFile(t, ".mobinclude", ".env\n")
+	git(gitRunner, "add", ".gitignore", ".mobinclude")
+	git(gitRunner, "commit", "-m", "add .mobinclude")
+	git(gitRunner, "push", "origin", "master")
+	setWorkingDir(tempDir + "/localother")
+	git(gitRunner, "pull")
+	setWorkingDir(tempDir + "/local")
+}
+
+func TestNextHandsOverIncludedFiles(t *testing.T) {
+	output, configuration := setup(t)
+	setupMobInclude(t)
+	start(gitRunner, configuration)
+	createFile(t, ".env", "SECRET=42")
+	createFile(t, "notes.txt", "private")
+	next(gitRunner, configuration)
+	assertOutputContains(t, output, "handed over 1 files listed in .mobinclude")
+	equals(t, "", gitOutput(t, "ls-tree", "--name-only", "origin/mob-session", ".env"))
+
+	setWorkingDir(tempDir + "/localother")
+	start(gitRunner, configuration)
+
+	assertOnBranch(t, "mob-session")
+	assertFileExist(t, ".env")
+	content, _ := os.ReadFile(tempDir + "/localother/.env")
+	equals(t, "SECRET=42", string(content))
+	if _, err := os.Stat(tempDir + "/localother/notes.txt"); err == nil {
+		t.Error("notes.txt is not listed in .mobinclude and must not be handed over")
+	}
+	assertCleanGitStatus(t)
+}
+
+func TestNextHandsOverIncludedFilesDryRun(t *testing.T) {
+	output, configuration := setup(t)
+	setupMobInclude(t)
+	start(gitRunner, configuration)
+	createFile(t, ".env", "SECRET=42")
+	enableDryRun(t)
+
+	assertNoError(t, pushIncludedFiles(gitRunner, configuration, newBranch("mob-session")))
+
+	assertOutputContains(t, output, "git add --force -- .env")
+	assertOutputContains(t, output, "git commit-tree <tree> -m mob include for mob-session")
+	assertOutputContains(t, output, "git update-ref refs/mob/include/mob-session <commit>")
+	equals(t, "", gitRefHash(gitRunner, "refs/mob/include/mob-session"))
+}
+
+func TestDoneDeletesIncludedFiles(t *testing.T) {
+	_, configuration := setup(t)
+	setupMobInclude(t)
+	start(gitRunner, configuration)
+	createFile(t, ".env", "SECRET=42")
+	next(gitRunner, configuration)
+
+	start(gitRunner, configuration)
+	done(gitRunner, configuration)
+
+	equals(t, "", gitRefHash(gitRunner, "refs/mob/include/mob-session"))
+	remoteRef, _ := silentgitignorefailure(gitRunner, "ls-remote", "origin", "refs/mob/include/mob-session")
+	equals(t, "", remoteRef)
+}
+
+func TestIncludedFilesWithoutMobInclude(t *testing.T) {
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+
+	assertOutputNotContains(t, output, ".mobinclude")
+	remoteRef, _ := silentgitignorefailure(gitRunner, "ls-remote", "origin", "refs/mob/include/mob-session")
+	equals(t, "", remoteRef)
+}
+
+func TestNextRunsGitHooksForIncludedFilesIfEnabled(t *testing.T) {
+	output, configuration := setup(t)
+	setupMobInclude(t)
+	start(gitRunner, configuration)
+	createFile(t, ".env", "SECRET=42")
+	configuration.GitHooksEnabled = true
+
+	next(gitRunner, configuration)
+
+	assertOutputContains(t, output, "git push --force origin refs/mob/include/mob-session:refs/mob/include/mob-session")
+	assertOutputNotContains(t, output, "git push --force --no-verify origin refs/mob/include")
+}
@@ -0,0 +1,440 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/say"
+	"os"
+	"path"
+	"strings"
+)
+
+// operation is the journal of a running start, next, done or reset. It is written to the git dir after every
+// completed step, so that an interrupted command can be resumed or rolled back with 'mob recover'.
+// When the command finishes, the journal is kept for 'mob undo'.
+type operation struct {
+	Command            string   `json:"command"`
+	BaseBranch         string   `json:"baseBranch"`
+	WipBranch          string   `json:"wipBranch"`
+	Before             refs     `json:"before"`
+	After              refs     `json:"after"`
+	LastWipHead        string   `json:"lastWipHead"`
+	UncommittedChanges bool     `json:"uncommittedChanges"`
+	HandleChanges      string   `json:"handleChanges,omitempty"`
+	StashName          string   `json:"stashName,omitempty"`
+	NextStay           bool     `json:"nextStay,omitempty"`
+	DoneSquash         string   `json:"doneSquash,omitempty"`
+	MadeWipCommit      bool     `json:"madeWipCommit"`
+	WipCommit          string   `json:"wipCommit,omitempty"`
+	MergeConflict      bool     `json:"mergeConflict"`
+	Completed          []string `json:"completed"`
+}
+
+// refs is a snapshot of the refs an operation may change
+type refs struct {
+	Branch        string `json:"branch"`
+	Head          string `json:"head"`
+	BaseHead      string `json:"baseHead"`
+	WipHead       string `json:"wipHead"`
+	RemoteWipHead string `json:"remoteWipHead"`
+	WorkingTree   string `json:"workingTree,omitempty"`
+}
+
+func journalPath(runner GitRunner) (string, error) {
+	gitDir, err := gitDir(runner)
+	return path.Join(gitDir, "mob-journal"), err
+}
+
+func undoPath(runner GitRunner) (string, error) {
+	gitDir, err := gitDir(runner)
+	return path.Join(gitDir, "mob-undo"), err
+}
+
+func hasOperationInProgress(runner GitRunner) bool {
+	journalPath, err := journalPath(runner)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(journalPath)
+	return err == nil
+}
+
+func loadOperation(runner GitRunner) (*operation, error) {
+	journalPath, err := journalPath(runner)
+	if err != nil {
+		return nil, err
+	}
+	return readOperation(journalPath)
+}
+
+func readOperation(path string) (*operation, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	op := &operation{}
+	err = json.Unmarshal(content, op)
+	return op, err
+}
+
+func beginOperation(runner GitRunner, command string, baseBranch Branch, wipBranch Branch, configuration config.Configuration) (*operation, error) {
+	before, err := currentRefs(runner, baseBranch, wipBranch, configuration)
+	if err != nil {
+		return nil, err
+	}
+	uncommittedChanges, err := hasUncommittedChanges(runner)
+	if err != nil {
+		return nil, err
+	}
+	op := &operation{
+		Command:            command,
+		BaseBranch:         baseBranch.Name,
+		WipBranch:          wipBranch.Name,
+		Before:             before,
+		UncommittedChanges: uncommittedChanges,
+	}
+	op.LastWipHead = op.Before.WipHead
+	op.save(runner)
+	return op, nil
+}
+
+func currentRefs(runner GitRunner, baseBranch Branch, wipBranch Branch, configuration config.Configuration) (refs, error) {
+	currentBranch, err := gitCurrentBranch(runner)
+	if err != nil {
+		return refs{}, err
+	}
+	workingTree, err := workingTreeState(runner)
+	if err != nil {
+		return refs{}, err
+	}
+	return refs{
+		Branch:        currentBranch.Name,
+		Head:          gitCommitHash(runner),
+		BaseHead:      gitRefHash(runner, "refs/heads/"+baseBranch.Name),
+		WipHead:       gitRefHash(runner, "refs/heads/"+wipBranch.Name),
+		RemoteWipHead: gitRefHash(runner, "refs/remotes/"+wipBranch.remote(configuration).Name),
+		WorkingTree:   workingTree,
+	}, nil
+}
+
+// workingTreeState is a fingerprint of the uncommitted changes, so that undo notices changes made since the operation
+func workingTreeState(runner GitRunner) (string, error) {
+	status, err := silentgit(runner, "status", "--porcelain", "--untracked-files=all")
+	if err != nil {
+		return "", err
+	}
+	diff, err := silentgit(runner, "diff", "HEAD", "--binary")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha1.Sum([]byte(status+"\n"+diff))), nil
+}
+
+func (op *operation) branches() (Branch, Branch) {
+	return newBranch(op.BaseBranch), newBranch(op.WipBranch)
+}
+
+func (op *operation) completed(step string) bool {
+	for _, completedStep := range op.Completed {
+		if completedStep == step {
+			return true
+		}
+	}
+	return false
+}
+
+// step runs action unless it already completed in an earlier, interrupted run and journals it afterwards.
+// A failing action stays incomplete, so that 'mob recover --resume' runs it again.
+func (op *operation) step(runner GitRunner, name string, action func() error) error {
+	if op.completed(name) {
+		say.Debug("skipping completed step " + name)
+		return nil
+	}
+	if err := action(); err != nil {
+		return err
+	}
+	op.complete(runner, name)
+	return nil
+}
+
+func (op *operation) complete(runner GitRunner, name string) {
+	op.Completed = append(op.Completed, name)
+	if wipHead := gitRefHash(runner, "refs/heads/"+op.WipBranch); wipHead != "" {
+		op.LastWipHead = wipHead
+	}
+	op.save(runner)
+}
+
+func (op *operation) save(runner GitRunner) {
+	if journalPath, err := journalPath(runner); err != nil {
+		say.Warning("could not write the journal: " + err.Error())
+	} else {
+		op.writeTo(journalPath)
+	}
+}
+
+func (op *operation) writeTo(path string) {
+	if DryRun {
+		return
+	}
+	content, err := json.Marshal(op)
+	if err == nil {
+		err = os.WriteFile(path, content, 0644)
+	}
+	if err != nil {
+		say.Warning("could not write " + path + ": " + err.Error())
+	}
+}
+
+// finish ends the journal and keeps it together with the resulting refs for 'mob undo'
+func (op *operation) finish(runner GitRunner, configuration config.Configuration) error {
+	baseBranch, wipBranch := op.branches()
+	after, err := currentRefs(runner, baseBranch, wipBranch, configuration)
+	if err != nil {
+		return err
+	}
+	op.After = after
+	undoPath, err := undoPath(runner)
+	if err != nil {
+		return err
+	}
+	op.writeTo(undoPath)
+	journalPath, err := journalPath(runner)
+	if err != nil {
+		return err
+	}
+	removeJournalFile(journalPath)
+	return nil
+}
+
+// discardUndo forgets the last operation, a later command may have changed what undo would restore
+func discardUndo(runner GitRunner) error {
+	undoPath, err := undoPath(runner)
+	if err != nil {
+		return err
+	}
+	removeJournalFile(undoPath)
+	return nil
+}
+
+func removeJournalFile(path string) {
+	if DryRun {
+		return
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		say.Warning("could not remove " + path + ": " + err.Error())
+	}
+}
+
+// changesWereCommitted tells whether the uncommitted changes from before the operation ended up in a commit
+func (op *operation) changesWereCommitted() bool {
+	return op.UncommittedChanges && (op.Command == "next" || op.Command == "done")
+}
+
+func doneMergeConflictFixes(configuration config.Configuration) []Fix {
+	return []Fix{
+		{"To continue, resolve the merge conflicts, stage them with 'git add' and use", configuration.Mob("done --continue")},
+		{"To return to the wip branch as it was before done, use", configuration.Mob("done --abort")},
+	}
+}
+
+// failIfOperationInProgress prevents starting a new operation that would overwrite the journal
+func failIfOperationInProgress(runner GitRunner, configuration config.Configuration) error {
+	if !hasOperationInProgress(runner) {
+		return nil
+	}
+	op, err := loadOperation(runner)
+	if err != nil {
+		return errReadJournal(err)
+	}
+	if op.Command == "done" && op.MergeConflict {
+		return newMobError(ErrOperationInProgress, "'mob done' stopped because of merge conflicts", doneMergeConflictFixes(configuration)...)
+	}
+	return newMobError(ErrOperationInProgress, "'mob "+op.Command+"' was interrupted",
+		Fix{"To see what was already done, use", configuration.Mob("recover")})
+}
+
+func errReadJournal(err error) *MobError {
+	return newMobError(ErrOperationInProgress, "could not read the journal: "+err.Error())
+}
+
+func recoverOperation(runner GitRunner, configuration config.Configuration, parameter []string) error {
+	if !hasOperationInProgress(runner) {
+		say.Info("nothing to recover")
+		return nil
+	}
+	op, err := loadOperation(runner)
+	if err != nil {
+		return errReadJournal(err)
+	}
+
+	if len(parameter) > 0 && parameter[0] == "--resume" {
+		return resumeOperation(runner, configuration, op)
+	}
+	if len(parameter) > 0 && parameter[0] == "--rollback" {
+		return rollbackOperation(runner, configuration, op)
+	}
+
+	say.Info("'mob " + op.Command + "' on wip branch '" + op.WipBranch + "' (base branch '" + op.BaseBranch + "') was interrupted")
+	if len(op.Completed) > 0 {
+		say.Info("completed steps: " + strings.Join(op.Completed, ", "))
+	} else {
+		say.Info("no steps were completed")
+	}
+	say.Fix("To resume with the remaining steps, use", configuration.Mob("recover --resume"))
+	say.Fix("To roll back to the state before 'mob "+op.Command+"', use", configuration.Mob("recover --rollback"))
+	return nil
+}
+
+func resumeOperation(runner GitRunner, configuration config.Configuration, op *operation) error {
+	say.Info("resuming 'mob " + op.Command + "'")
+	switch op.Command {
+	case "start":
+		return startSteps(runner, configuration, op)
+	case "next":
+		return nextSteps(runner, configuration, op)
+	case "done":
+		return doneContinue(runner, configuration, op)
+	case "reset":
+		return deleteRemoteWipBranchSteps(runner, configuration, op)
+	}
+	return newMobError(ErrInvalidArgument, "cannot resume unknown command '"+op.Command+"'")
+}
+
+func doneContinue(runner GitRunner, configuration config.Configuration, op *operation) error {
+	unmergedFiles, err := silentgit(runner, "diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return err
+	}
+	if unmergedFiles != "" {
+		mobError := newMobError(ErrMergeConflict, "cannot continue; there are unresolved merge conflicts in", doneMergeConflictFixes(configuration)...)
+		mobError.Details = strings.Split(unmergedFiles, "\n")
+		return mobError
+	}
+
+	if gitRefHash(runner, "MERGE_HEAD") != "" {
+		if err := gitWithoutEmptyStrings(runner, "commit", "--no-edit", gitHooksOption(configuration)); err != nil {
+			return err
+		}
+	}
+	if op.MergeConflict {
+		op.MergeConflict = false
+		op.complete(runner, "merge-wip")
+	}
+	return doneSteps(runner, configuration, op)
+}
+
+func rollbackOperation(runner GitRunner, configuration config.Configuration, op *operation) error {
+	if err := restoreBefore(runner, configuration, op); err != nil {
+		return err
+	}
+	journalPath, err := journalPath(runner)
+	if err != nil {
+		return err
+	}
+	removeJournalFile(journalPath)
+	say.Info("rolled back 'mob " + op.Command + "', you are on branch '" + op.Before.Branch + "' again")
+	return nil
+}
+
+// restoreBefore restores the refs, the working tree and the remote wip branch from before the operation
+func restoreBefore(runner GitRunner, configuration config.Configuration, op *operation) error {
+	baseBranch, wipBranch := op.branches()
+
+	if gitRefHash(runner, "MERGE_HEAD") != "" {
+		if err := git(runner, "merge", "--abort"); err != nil {
+			return err
+		}
+	}
+	currentBranch, err := gitCurrentBranch(runner)
+	if err != nil {
+		return err
+	}
+	if op.Command == "done" && currentBranch.Is(baseBranch.Name) {
+		if err := git(runner, "reset", "--hard"); err != nil { // drops the result of merge --squash
+			return err
+		}
+	}
+
+	hasLocalWipBranch, err := wipBranch.hasLocalBranch(runner)
+	if err != nil {
+		return err
+	}
+	if op.Before.WipHead != "" && !hasLocalWipBranch {
+		if err := git(runner, "branch", wipBranch.Name, op.LastWipHead); err != nil {
+			return err
+		}
+	}
+	if op.Before.BaseHead != "" && gitRefHash(runner, "refs/heads/"+baseBranch.Name) != op.Before.BaseHead {
+		if currentBranch.Is(baseBranch.Name) {
+			err = git(runner, "reset", "--keep", op.Before.BaseHead)
+		} else {
+			err = git(runner, "branch", "--force", baseBranch.Name, op.Before.BaseHead)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if !currentBranch.Is(op.Before.Branch) {
+		if err := git(runner, "checkout", op.Before.Branch); err != nil {
+			return err
+		}
+	}
+	if gitCommitHash(runner) != op.Before.Head {
+		if op.changesWereCommitted() {
+			err = git(runner, "reset", "--mixed", op.Before.Head)
+		} else {
+			err = git(runner, "reset", "--keep", op.Before.Head)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if op.Before.WipHead == "" && op.Before.Branch != wipBranch.Name { // after the checkout, you are on the branch from before
+		if hasLocalWipBranch, err := wipBranch.hasLocalBranch(runner); err != nil {
+			return err
+		} else if hasLocalWipBranch {
+			if err := git(runner, "branch", "-D", wipBranch.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	remoteWipHead := gitRefHash(runner, "refs/remotes/"+wipBranch.remote(configuration).Name)
+	if remoteWipHead != op.Before.RemoteWipHead {
+		// the lease rejects the push if someone else pushed to the wip branch since we last saw it
+		lease := "--force-with-lease=refs/heads/" + wipBranch.Name + ":" + remoteWipHead
+		if op.Before.RemoteWipHead == "" {
+			err = gitWithoutEmptyStrings(runner, "push", lease, gitHooksOption(configuration), configuration.RemoteName, "--delete", wipBranch.Name)
+		} else if isCommitAvailable(runner, op.Before.RemoteWipHead) {
+			err = gitWithoutEmptyStrings(runner, "push", lease, gitHooksOption(configuration), configuration.RemoteName, op.Before.RemoteWipHead+":refs/heads/"+wipBranch.Name)
+		} else {
+			say.Warning("cannot restore " + wipBranch.remote(configuration).Name + " because commit " + op.Before.RemoteWipHead + " is not available locally")
+		}
+		if errors.Is(err, ErrPushRejected) {
+			say.Warning("did not restore " + wipBranch.remote(configuration).Name + " because someone else pushed to it in the meantime")
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if op.completed("stash") && !op.completed("stash-pop") {
+		stashes, err := silentgit(runner, "stash", "list")
+		if err != nil {
+			return err
+		}
+		return git(runner, "stash", "pop", findStashByName(stashes, op.StashName))
+	}
+	return nil
+}
+
+func isCommitAvailable(runner GitRunner, hash string) bool {
+	_, err := silentgitignorefailure(runner, "cat-file", "-e", hash+"^{commit}")
+	return err == nil
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"bufio"
-	"errors"
 	"fmt"
 	config "github.com/remotemobprogramming/mob/v5/configuration"
 	"github.com/remotemobprogramming/mob/v5/goal"
@@ -26,21 +25,25 @@ const (
 )
 
 var (
-	workingDir                 = ""
 	args                       []string
 	GitPassthroughStderrStdout = false // hack to get git hooks to print to stdout/stderr
+	DryRun                     = false // print git commands that change something instead of running them
 )
 
-func openCommandFor(c config.Configuration, filepath string) (string, []string) {
+// openCommandFor renders MOB_OPEN_COMMAND for the file, line and column (1 if unknown)
+func openCommandFor(runner GitRunner, c config.Configuration, filepath string, line int, column int) (string, []string, error) {
 	if !c.IsOpenCommandGiven() {
-		return "", []string{}
+		return "", []string{}, nil
 	}
-	filepathWithoutSpaces := strings.ReplaceAll(filepath, " ", "&spc&")
-	split := strings.Split(injectCommandWithMessage(c.OpenCommand, filepathWithoutSpaces), " ")
-	for i := 0; i < len(split); i++ {
-		split[i] = strings.ReplaceAll(split[i], "&spc&", " ")
+	args, _, err := renderCommand(runner, c.OpenCommand, "file", map[string]string{
+		"file":   filepath,
+		"line":   strconv.Itoa(max(line, 1)),
+		"column": strconv.Itoa(max(column, 1)),
+	}, c)
+	if err != nil {
+		return "", nil, err
 	}
-	return split[0], split[1:]
+	return args[0], args[1:], nil
 }
 
 type GitVersion struct {
@@ -107,33 +110,39 @@ func (branch Branch) remote(configuration config.Configuration) Branch {
 	return newBranch(configuration.RemoteName + "/" + branch.Name)
 }
 
-func (branch Branch) hasRemoteBranch(configuration config.Configuration) bool {
-	remoteBranches := gitRemoteBranches()
+func (branch Branch) hasRemoteBranch(runner GitRunner, configuration config.Configuration) (bool, error) {
+	remoteBranches, err := gitRemoteBranches(runner)
+	if err != nil {
+		return false, err
+	}
 	remoteBranch := branch.remote(configuration).Name
 	say.Debug("Remote Branches: " + strings.Join(remoteBranches, "\n"))
 	say.Debug("Remote Branch: " + remoteBranch)
 
 	for i := 0; i < len(remoteBranches); i++ {
 		if remoteBranches[i] == remoteBranch {
-			return true
+			return true, nil
 		}
 	}
 
-	return false
+	return false, nil
 }
 
-func (branch Branch) hasLocalBranch() bool {
-	localBranches := gitBranches()
+func (branch Branch) hasLocalBranch(runner GitRunner) (bool, error) {
+	localBranches, err := gitBranches(runner)
+	if err != nil {
+		return false, err
+	}
 	say.Debug("Local Branches: " + strings.Join(localBranches, "\n"))
 	say.Debug("Local Branch: " + branch.Name)
 
 	for i := 0; i < len(localBranches); i++ {
 		if localBranches[i] == branch.Name {
-			return true
+			return true, nil
 		}
 	}
 
-	return false
+	return false, nil
 }
 
 func (branch Branch) IsWipBranch(configuration config.Configuration) bool {
@@ -210,26 +219,35 @@ func (branch Branch) hasWipBranchQualifierSeparator(configuration config.Configu
 	return strings.Contains(branch.Name, configuration.WipBranchQualifierSeparator)
 }
 
-func (branch Branch) hasLocalCommits(configuration config.Configuration) bool {
-	local := silentgit("for-each-ref", "--format=%(objectname)", "refs/heads/"+branch.Name)
-	remote := silentgit("for-each-ref", "--format=%(objectname)", "refs/remotes/"+branch.remote(configuration).Name)
-	return local != remote
+func (branch Branch) hasLocalCommits(runner GitRunner, configuration config.Configuration) (bool, error) {
+	local, err := silentgit(runner, "for-each-ref", "--format=%(objectname)", "refs/heads/"+branch.Name)
+	if err != nil {
+		return false, err
+	}
+	remote, err := silentgit(runner, "for-each-ref", "--format=%(objectname)", "refs/remotes/"+branch.remote(configuration).Name)
+	if err != nil {
+		return false, err
+	}
+	return local != remote, nil
 }
 
-func (branch Branch) hasUnpushedCommits(configuration config.Configuration) bool {
-	countOutput := silentgit(
+func (branch Branch) hasUnpushedCommits(runner GitRunner, configuration config.Configuration) (bool, error) {
+	countOutput, err := silentgit(runner,
 		"rev-list", "--count", "--left-only",
 		"refs/heads/"+branch.Name+"..."+"refs/remotes/"+branch.remote(configuration).Name,
 	)
+	if err != nil {
+		return false, err
+	}
 	unpushedCount, err := strconv.Atoi(countOutput)
 	if err != nil {
-		panic(err)
+		return false, err
 	}
 	unpushedCommits := unpushedCount != 0
 	if unpushedCommits {
 		say.Info(fmt.Sprintf("there are %d unpushed commits on local base branch <%s>", unpushedCount, branch.Name))
 	}
-	return unpushedCommits
+	return unpushedCommits, nil
 }
 
 func stringContains(list []string, element string) bool {
@@ -243,34 +261,43 @@ func stringContains(list []string, element string) bool {
 }
 
 func main() {
-	run(os.Args)
+	run(newExecGitRunner(""), os.Args)
+}
+
+func run(runner GitRunner, osArgs []string) {
+	if err := parseArgsAndExecute(runner, osArgs); err != nil {
+		sayError(err)
+		Exit(exitCode(err))
+	}
 }
 
-func run(osArgs []string) {
+func parseArgsAndExecute(runner GitRunner, osArgs []string) error {
 	args = osArgs
 	say.TurnOnDebuggingByArgs(args)
 	say.Debug(runtime.Version())
 
-	versionString := gitVersion()
+	versionString := gitVersion(runner)
 	if versionString == "" {
-		say.Error("'git' command was not found in PATH. It may be not installed. " +
+		return newMobError(ErrGitFailed, "'git' command was not found in PATH. It may be not installed. "+
 			"To learn how to install 'git' refer to https://git-scm.com/book/en/v2/Getting-Started-Installing-Git.")
-		Exit(1)
 	}
 
 	currentVersion := parseGitVersion(versionString)
 	if currentVersion.Less(parseGitVersion(minimumGitVersion)) {
-		say.Error(fmt.Sprintf("'git' command version '%s' is lower than the required minimum version (%s). "+
+		return newMobError(ErrGitFailed, fmt.Sprintf("'git' command version '%s' is lower than the required minimum version (%s). "+
 			"Please update your 'git' installation!", versionString, minimumGitVersion))
-		Exit(1)
 	}
 
 	projectRootDir := ""
-	if isGit() {
-		projectRootDir = gitRootDir()
-		if !hasCommits() {
-			say.Error("Git repository does not have any commits yet. Please create an initial commit.")
-			Exit(1)
+	if isGit(runner) {
+		var err error
+		if projectRootDir, err = gitRootDir(runner); err != nil {
+			return err
+		}
+		if commits, err := hasCommits(runner); err != nil {
+			return err
+		} else if !commits {
+			return newMobError(ErrGitFailed, "Git repository does not have any commits yet. Please create an initial commit.")
 		}
 	}
 
@@ -286,84 +313,135 @@ func run(osArgs []string) {
 	say.Debug("command '" + command + "'")
 	say.Debug("parameters '" + strings.Join(parameters, " ") + "'")
 	say.Debug("version " + versionNumber)
-	say.Debug("workingDir '" + workingDir + "'")
+	say.Debug("workingDir '" + runner.Dir() + "'")
 
 	// workaround until we have a better design
 	if configuration.GitHooksEnabled {
 		GitPassthroughStderrStdout = true
 	}
+	if configuration.DryRun {
+		DryRun = true
+		runner = dryRunGitRunner{runner}
+		say.Info("dry run, nothing will be changed. These are the commands 'mob " + command + "' would run:")
+	}
 
-	execute(command, parameters, configuration)
+	return execute(runner, command, parameters, configuration)
 }
 
-func hasCommits() bool {
-	commitCount := silentgit("rev-list", "--all", "--count")
-	return commitCount != "0"
+func hasCommits(runner GitRunner) (bool, error) {
+	commitCount, err := silentgit(runner, "rev-list", "--all", "--count")
+	return commitCount != "0", err
 }
 
 func currentCliName(argZero string) string {
 	return strings.TrimSuffix(filepath.Base(argZero), ".exe")
 }
 
-func execute(command string, parameter []string, configuration config.Configuration) {
+// commandsThatDiscardUndo change the repository, so 'mob undo' must not restore the state before an earlier command
+var commandsThatDiscardUndo = []string{"s", "start", "n", "next", "d", "done", "reset", "clean", "restore"}
+
+func execute(runner GitRunner, command string, parameter []string, configuration config.Configuration) error {
 	if helpRequested(parameter) {
 		help.Help(configuration)
-		return
+		return nil
+	}
+	if contains(commandsThatDiscardUndo, command) && isGit(runner) {
+		if err := discardUndo(runner); err != nil {
+			return err
+		}
 	}
 
 	switch command {
 	case "s", "start":
-		err := start(configuration)
-		if !isMobProgramming(configuration) || err != nil {
-			Exit(1)
+		if err := runWithHooks(runner, "start", configuration, func() error { return start(runner, configuration) }); err != nil {
+			return err
+		}
+		if DryRun {
+			return nil
+		}
+		if configuration.Handover != config.HandoverStash {
+			if mobProgramming, err := isMobProgramming(runner, configuration); err != nil {
+				return err
+			} else if !mobProgramming {
+				return newMobError(ErrNotMobProgramming, "you are not in a mob session after start")
+			}
 		}
+		startAutosave(runner, configuration)
 		if len(parameter) > 0 {
 			timer := parameter[0]
-			StartTimer(timer, configuration)
+			return startTimer(runner, timer, configuration)
 		} else if configuration.Timer != "" {
-			StartTimer(configuration.Timer, configuration)
+			return startTimer(runner, configuration.Timer, configuration)
 		} else {
 			say.Info("It's now " + currentTime() + ". Happy collaborating! :)")
 		}
 	case "b", "branch":
-		branch(configuration)
+		if configuration.OutputJson {
+			return branchAsJson(runner, configuration)
+		}
+		return branch(runner, configuration)
 	case "n", "next":
-		next(configuration)
+		return runWithHooks(runner, "next", configuration, func() error { return next(runner, configuration) })
 	case "d", "done":
-		done(configuration)
+		return runWithHooks(runner, "done", configuration, func() error { return done(runner, configuration) })
+	case "wait":
+		if err := wait(runner, configuration); err != nil {
+			return err
+		}
+		if configuration.WaitStart {
+			return execute(runner, "start", parameter, configuration)
+		}
+	case "recover":
+		return recoverOperation(runner, configuration, parameter)
+	case "undo":
+		return undo(runner, configuration)
+	case "autosave":
+		return autosave(runner, configuration)
+	case "restore":
+		return restore(runner, configuration)
 	case "fetch":
-		fetch(configuration)
+		return fetch(runner, configuration)
 	case "reset":
-		reset(configuration)
+		if !configuration.ResetDeleteRemoteWipBranch {
+			return reset(runner, configuration)
+		}
+		return runWithHooks(runner, "reset", configuration, func() error { return reset(runner, configuration) })
 	case "clean":
-		clean(configuration)
+		return clean(runner, configuration)
 	case "config":
-		config.Config(configuration)
+		if configuration.OutputJson {
+			config.ConfigJson(configuration)
+		} else {
+			config.Config(configuration)
+		}
 	case "status":
-		status(configuration)
+		if configuration.OutputJson {
+			return statusAsJson(runner, configuration)
+		}
+		return status(runner, configuration)
 	case "t", "timer":
 		if len(parameter) > 0 {
 			if parameter[0] == "open" || parameter[0] == "o" {
 				if err := openTimerInBrowser(configuration); err != nil {
-					say.Error(fmt.Sprintf("Could not open webtimer: %s", err.Error()))
+					return &MobError{Kind: err, Message: fmt.Sprintf("Could not open webtimer: %s", err.Error())}
 				}
 			} else {
 				timer := parameter[0]
-				StartTimer(timer, configuration)
+				return startTimer(runner, timer, configuration)
 			}
 		} else if configuration.Timer != "" {
-			StartTimer(configuration.Timer, configuration)
+			return startTimer(runner, configuration.Timer, configuration)
 		} else {
 			help.Help(configuration)
 		}
 	case "break":
 		if len(parameter) > 0 {
-			StartBreakTimer(parameter[0], configuration)
+			return startBreakTimer(runner, parameter[0], configuration)
 		} else {
 			help.Help(configuration)
 		}
 	case "moo":
-		moo(configuration)
+		moo(runner, configuration)
 	case "sw", "squash-wip":
 		if len(parameter) > 1 && parameter[0] == "--git-editor" {
 			squashWipGitEditor(parameter[1], configuration)
@@ -371,14 +449,30 @@ func execute(command string, parameter []string, configuration config.Configurat
 			squashWipGitSequenceEditor(parameter[1], configuration)
 		}
 	case "g", "goal":
-		goal.Goal(configuration, parameter)
+		return goal.Goal(configuration, parameter)
+	case "serve":
+		return serve(runner, configuration, parameter)
+	case "webhook":
+		return webhook(runner, parameter, configuration)
+	case "notify":
+		return notifyYou(runner, parameter, configuration)
 	case "version", "--version", "-v":
-		version()
+		if configuration.OutputJson {
+			sayJson(map[string]string{"version": versionNumber})
+		} else {
+			version()
+		}
 	case "help", "--help", "-h":
 		help.Help(configuration)
 	default:
 		help.Help(configuration)
 	}
+	return nil
+}
+
+func errNotMobProgramming(configuration config.Configuration) *MobError {
+	return newMobError(ErrNotMobProgramming, "you are not in a mob session",
+		Fix{"to start working together, use", configuration.Mob("start")})
 }
 
 func openTimerInBrowser(configuration config.Configuration) error {
@@ -407,44 +501,108 @@ func helpRequested(parameter []string) bool {
 	return false
 }
 
-func clean(configuration config.Configuration) {
-	git("fetch", configuration.RemoteName, "--prune")
+func clean(runner GitRunner, configuration config.Configuration) error {
+	if err := git(runner, "fetch", configuration.RemoteName, "--prune"); err != nil {
+		return err
+	}
 
-	currentBranch := gitCurrentBranch()
-	localBranches := gitBranches()
+	currentBranch, err := gitCurrentBranch(runner)
+	if err != nil {
+		return err
+	}
+	localBranches, err := gitBranches(runner)
+	if err != nil {
+		return err
+	}
 
-	if currentBranch.isOrphanWipBranch(configuration) {
+	if orphan, err := currentBranch.isOrphanWipBranch(runner, configuration); err != nil {
+		return err
+	} else if orphan {
 		currentBaseBranch, _ := determineBranches(currentBranch, localBranches, configuration)
 
 		say.Info("Current branch " + currentBranch.Name + " is an orphan")
 		if currentBaseBranch.exists(localBranches) {
-			git("checkout", currentBaseBranch.Name)
+			err = git(runner, "checkout", currentBaseBranch.Name)
 		} else if newBranch("main").exists(localBranches) {
-			git("checkout", "main")
+			err = git(runner, "checkout", "main")
 		} else {
-			git("checkout", "master")
+			err = git(runner, "checkout", "master")
+		}
+		if err != nil {
+			return err
 		}
 	}
 
 	for _, branch := range localBranches {
 		b := newBranch(branch)
-		if b.isOrphanWipBranch(configuration) {
+		if orphan, err := b.isOrphanWipBranch(runner, configuration); err != nil {
+			return err
+		} else if orphan {
 			say.Info("Removing orphan wip branch " + b.Name)
-			git("branch", "-D", b.Name)
+			if err := git(runner, "branch", "-D", b.Name); err != nil {
+				return err
+			}
 		}
 	}
-
+	return nil
 }
 
-func (branch Branch) isOrphanWipBranch(configuration config.Configuration) bool {
-	return branch.IsWipBranch(configuration) && !branch.hasRemoteBranch(configuration)
+func (branch Branch) isOrphanWipBranch(runner GitRunner, configuration config.Configuration) (bool, error) {
+	if !branch.IsWipBranch(configuration) {
+		return false, nil
+	}
+	hasRemoteBranch, err := branch.hasRemoteBranch(runner, configuration)
+	return !hasRemoteBranch, err
 }
 
-func branch(configuration config.Configuration) {
-	say.Say(silentgit("branch", "--list", "--remote", newBranch("*").addWipPrefix(configuration).remote(configuration).Name))
+func branch(runner GitRunner, configuration config.Configuration) error {
+	wipBranches, err := silentgit(runner, "branch", "--list", "--remote", newBranch("*").addWipPrefix(configuration).remote(configuration).Name)
+	if err != nil {
+		return err
+	}
+	say.Say(wipBranches)
 
 	// DEPRECATED
-	say.Say(silentgit("branch", "--list", "--remote", newBranch("mob-session").remote(configuration).Name))
+	mobSessionBranches, err := silentgit(runner, "branch", "--list", "--remote", newBranch("mob-session").remote(configuration).Name)
+	if err != nil {
+		return err
+	}
+	say.Say(mobSessionBranches)
+	return nil
+}
+
+func branchAsJson(runner GitRunner, configuration config.Configuration) error {
+	wipBranches := []string{}
+	for _, pattern := range []string{
+		newBranch("*").addWipPrefix(configuration).remote(configuration).Name,
+		newBranch("mob-session").remote(configuration).Name, // DEPRECATED
+	} {
+		branches, err := silentgit(runner, "branch", "--list", "--remote", pattern)
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Split(branches, "\n") {
+			if strings.TrimSpace(line) != "" {
+				wipBranches = append(wipBranches, strings.TrimSpace(line))
+			}
+		}
+	}
+	sayJson(map[string][]string{"wipBranches": wipBranches})
+	return nil
+}
+
+// currentBranches determines the base and the wip branch from the current branch
+func currentBranches(runner GitRunner, configuration config.Configuration) (baseBranch Branch, wipBranch Branch, err error) {
+	currentBranch, err := gitCurrentBranch(runner)
+	if err != nil {
+		return Branch{}, Branch{}, err
+	}
+	localBranches, err := gitBranches(runner)
+	if err != nil {
+		return Branch{}, Branch{}, err
+	}
+	baseBranch, wipBranch = determineBranches(currentBranch, localBranches, configuration)
+	return baseBranch, wipBranch, nil
 }
 
 func determineBranches(currentBranch Branch, localBranches []string, configuration config.Configuration) (baseBranch Branch, wipBranch Branch) {
@@ -468,19 +626,7 @@ func determineBranches(currentBranch Branch, localBranches []string, configurati
 	return
 }
 
-func injectCommandWithMessage(command string, message string) string {
-	placeHolders := strings.Count(command, "%s")
-	if placeHolders > 1 {
-		say.Error(fmt.Sprintf("Too many placeholders (%d) in format command string: %s", placeHolders, command))
-		Exit(1)
-	}
-	if placeHolders == 0 {
-		return fmt.Sprintf("%s %s", command, message)
-	}
-	return fmt.Sprintf(command, message)
-}
-
-func executeCommandsInBackgroundProcess(commands ...string) (err error) {
+func executeCommandsInBackgroundProcess(runner GitRunner, commands ...string) (err error) {
 	cmds := make([]string, 0)
 	for _, c := range commands {
 		if len(c) > 0 {
@@ -490,9 +636,9 @@ func executeCommandsInBackgroundProcess(commands ...string) (err error) {
 	say.Debug(fmt.Sprintf("Operating System %s", runtime.GOOS))
 	switch runtime.GOOS {
 	case "windows":
-		_, err = startCommand("powershell", "-command", fmt.Sprintf("start-process powershell -NoNewWindow -ArgumentList '-command \"%s\"'", strings.Join(cmds, ";")))
+		_, err = startCommand(runner.Dir(), "powershell", "-command", fmt.Sprintf("start-process powershell -NoNewWindow -ArgumentList '-command \"%s\"'", strings.Join(cmds, ";")))
 	case "darwin", "linux":
-		_, err = startCommand("sh", "-c", fmt.Sprintf("(%s) &", strings.Join(cmds, ";")))
+		_, err = startCommand(runner.Dir(), "sh", "-c", fmt.Sprintf("(%s) &", strings.Join(cmds, ";")))
 	default:
 		say.Warning(fmt.Sprintf("Cannot execute background commands on your os: %s", runtime.GOOS))
 	}
@@ -503,9 +649,12 @@ func currentTime() string {
 	return time.Now().Format("15:04")
 }
 
-func moo(configuration config.Configuration) {
+func moo(runner GitRunner, configuration config.Configuration) {
 	voiceMessage := "moo"
-	err := executeCommandsInBackgroundProcess(getVoiceCommand(voiceMessage, configuration.VoiceCommand))
+	voiceCommand, err := getVoiceCommand(runner, voiceMessage, configuration)
+	if err == nil {
+		err = executeCommandsInBackgroundProcess(runner, voiceCommand)
+	}
 
 	if err != nil {
 		say.Warning(fmt.Sprintf("can't run voice command on your system (%s)", runtime.GOOS))
@@ -516,103 +665,254 @@ func moo(configuration config.Configuration) {
 	say.Info(voiceMessage)
 }
 
-func reset(configuration config.Configuration) {
+func reset(runner GitRunner, configuration config.Configuration) error {
 	if configuration.ResetDeleteRemoteWipBranch {
-		deleteRemoteWipBranch(configuration)
-	} else {
-		say.Fix("Executing this command deletes the mob branch for everyone. If you're sure you want that, use", configuration.Mob("reset --delete-remote-wip-branch"))
+		return deleteRemoteWipBranch(runner, configuration)
 	}
+	say.Fix("Executing this command deletes the mob branch for everyone. If you're sure you want that, use", configuration.Mob("reset --delete-remote-wip-branch"))
+	return nil
 }
 
-func deleteRemoteWipBranch(configuration config.Configuration) {
-	git("fetch", configuration.RemoteName)
+func deleteRemoteWipBranch(runner GitRunner, configuration config.Configuration) error {
+	if err := failIfOperationInProgress(runner, configuration); err != nil {
+		return err
+	}
+
+	if err := git(runner, "fetch", configuration.RemoteName); err != nil {
+		return err
+	}
+
+	currentBaseBranch, currentWipBranch, err := currentBranches(runner, configuration)
+	if err != nil {
+		return err
+	}
+	op, err := beginOperation(runner, "reset", currentBaseBranch, currentWipBranch, configuration)
+	if err != nil {
+		return err
+	}
+	return deleteRemoteWipBranchSteps(runner, configuration, op)
+}
 
-	currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+func deleteRemoteWipBranchSteps(runner GitRunner, configuration config.Configuration, op *operation) error {
+	currentBaseBranch, currentWipBranch := op.branches()
 
-	git("checkout", currentBaseBranch.String())
-	if currentWipBranch.hasLocalBranch() {
-		git("branch", "--delete", "--force", currentWipBranch.String())
+	if err := op.step(runner, "checkout-base", func() error {
+		return git(runner, "checkout", currentBaseBranch.String())
+	}); err != nil {
+		return err
 	}
-	if currentWipBranch.hasRemoteBranch(configuration) {
-		gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, "--delete", currentWipBranch.String())
+	if err := op.step(runner, "delete-wip", func() error {
+		if hasLocalBranch, err := currentWipBranch.hasLocalBranch(runner); err != nil || !hasLocalBranch {
+			return err
+		}
+		return git(runner, "branch", "--delete", "--force", currentWipBranch.String())
+	}); err != nil {
+		return err
+	}
+	if err := op.step(runner, "delete-remote-wip", func() error {
+		if hasRemoteBranch, err := currentWipBranch.hasRemoteBranch(runner, configuration); err != nil || !hasRemoteBranch {
+			return err
+		}
+		return gitWithoutEmptyStrings(runner, "push", gitHooksOption(configuration), configuration.RemoteName, "--delete", currentWipBranch.String())
+	}); err != nil {
+		return err
+	}
+	if err := op.step(runner, "delete-include", func() error {
+		return deleteIncludedFiles(runner, configuration, currentWipBranch)
+	}); err != nil {
+		return err
+	}
+	if err := op.finish(runner, configuration); err != nil {
+		return err
 	}
 	say.Info("Branches " + currentWipBranch.String() + " and " + currentWipBranch.remote(configuration).String() + " deleted")
+	return nil
 }
 
-func start(configuration config.Configuration) error {
-	uncommittedChanges := hasUncommittedChanges()
-	if uncommittedChanges && configuration.HandleUncommittedChanges == config.FailWithError {
-		say.Info("cannot start; clean working tree required")
-		sayUnstagedChangesInfo()
-		sayUntrackedFilesInfo()
-		sayFixUncommittedChanges(configuration)
-		return errors.New("cannot start; clean working tree required")
+func start(runner GitRunner, configuration config.Configuration) error {
+	if configuration.Handover == config.HandoverStash {
+		return startHandover(runner, configuration)
+	}
+	if err := failIfOperationInProgress(runner, configuration); err != nil {
+		return err
 	}
 
-	git("fetch", configuration.RemoteName, "--prune")
-	currentBranch := gitCurrentBranch()
-	currentBaseBranch, currentWipBranch := determineBranches(currentBranch, gitBranches(), configuration)
+	uncommittedChanges, err := hasUncommittedChanges(runner)
+	if err != nil {
+		return err
+	}
+	if uncommittedChanges && configuration.HandleUncommittedChanges == config.FailWithError {
+		if err := sayUnstagedChangesInfo(runner); err != nil {
+			return err
+		}
+		if err := sayUntrackedFilesInfo(runner); err != nil {
+			return err
+		}
+		return newMobError(ErrDirtyWorkingTree, "cannot start; clean working tree required", fixUncommittedChanges(configuration)...)
+	}
 
-	if !currentWipBranch.hasRemoteBranch(configuration) && configuration.StartJoin {
-		say.Error("Remote wip branch " + currentWipBranch.remote(configuration).String() + " is missing")
-		return errors.New("remote wip branch is missing")
+	if err := git(runner, "fetch", configuration.RemoteName, "--prune"); err != nil {
+		return err
+	}
+	currentBaseBranch, currentWipBranch, err := currentBranches(runner, configuration)
+	if err != nil {
+		return err
 	}
 
-	if !currentBaseBranch.hasRemoteBranch(configuration) && !configuration.StartCreate {
-		say.Error("Remote branch " + currentBaseBranch.remote(configuration).String() + " is missing")
-		say.Fix("To start and create the remote branch", "mob start --create")
-		return errors.New("remote branch is missing")
+	hasRemoteWipBranch, err := currentWipBranch.hasRemoteBranch(runner, configuration)
+	if err != nil {
+		return err
+	}
+	if !hasRemoteWipBranch && configuration.StartJoin {
+		return newMobError(ErrRemoteBranchMissing, "Remote wip branch "+currentWipBranch.remote(configuration).String()+" is missing")
 	}
 
-	createRemoteBranch(configuration, currentBaseBranch)
+	hasRemoteBaseBranch, err := currentBaseBranch.hasRemoteBranch(runner, configuration)
+	if err != nil {
+		return err
+	}
+	if !hasRemoteBaseBranch && !configuration.StartCreate {
+		return newMobError(ErrRemoteBranchMissing, "Remote branch "+currentBaseBranch.remote(configuration).String()+" is missing",
+			Fix{"To start and create the remote branch", "mob start --create"})
+	}
 
-	if currentBaseBranch.hasLocalBranch() && currentBaseBranch.hasUnpushedCommits(configuration) {
-		say.Error("cannot start; unpushed changes on base branch must be pushed upstream")
-		say.Fix("to fix this, push those commits and try again", "git push "+configuration.RemoteName+" "+currentBaseBranch.String())
-		return errors.New("cannot start; unpushed changes on base branch must be pushed upstream")
+	if err := createRemoteBranch(runner, configuration, currentBaseBranch); err != nil {
+		return err
 	}
 
-	if uncommittedChanges && configuration.HandleUncommittedChanges == config.DiscardChanges {
-		git("reset", "--hard")
+	if hasLocalBaseBranch, err := currentBaseBranch.hasLocalBranch(runner); err != nil {
+		return err
+	} else if hasLocalBaseBranch {
+		if unpushedCommits, err := currentBaseBranch.hasUnpushedCommits(runner, configuration); err != nil {
+			return err
+		} else if unpushedCommits {
+			return newMobError(ErrUnpushedCommits, "cannot start; unpushed changes on base branch must be pushed upstream",
+				Fix{"to fix this, push those commits and try again", "git push " + configuration.RemoteName + " " + currentBaseBranch.String()})
+		}
 	}
 
 	if uncommittedChanges && configuration.HandleUncommittedChanges == config.IncludeChanges {
-		if silentgit("ls-tree", "-r", "HEAD", "--full-name", "--name-only", ".") == "" {
-			say.Error("cannot start; current working dir is an uncommitted subdir")
-			say.Fix("to fix this, go to the parent directory and try again", "cd ..")
-			return errors.New("cannot start; current working dir is an uncommitted subdir")
+		if trackedFiles, err := silentgit(runner, "ls-tree", "-r", "HEAD", "--full-name", "--name-only", "."); err != nil {
+			return err
+		} else if trackedFiles == "" {
+			return newMobError(ErrInvalidArgument, "cannot start; current working dir is an uncommitted subdir",
+				Fix{"to fix this, go to the parent directory and try again", "cd .."})
 		}
-		git("stash", "push", "--include-untracked", "--message", configuration.StashName)
-		say.Info("uncommitted changes were stashed. If an error occurs later on, you can recover them with 'git stash pop'.")
 	}
 
-	if !isMobProgramming(configuration) {
-		git("merge", "FETCH_HEAD", "--ff-only")
+	op, err := beginOperation(runner, "start", currentBaseBranch, currentWipBranch, configuration)
+	if err != nil {
+		return err
 	}
+	op.HandleChanges = configuration.HandleUncommittedChanges
+	op.StashName = configuration.StashName
+	op.save(runner)
+	return startSteps(runner, configuration, op)
+}
 
-	if currentWipBranch.hasRemoteBranch(configuration) {
-		startJoinMobSession(configuration)
-	} else {
-		warnForActiveWipBranches(configuration, currentBaseBranch)
+func startSteps(runner GitRunner, configuration config.Configuration, op *operation) error {
+	currentBaseBranch, currentWipBranch := op.branches()
 
-		startNewMobSession(configuration)
+	if op.UncommittedChanges && op.HandleChanges == config.DiscardChanges {
+		if err := op.step(runner, "discard-changes", func() error {
+			return git(runner, "reset", "--hard")
+		}); err != nil {
+			return err
+		}
 	}
 
-	if uncommittedChanges && configuration.HandleUncommittedChanges == config.IncludeChanges {
-		stashes := silentgit("stash", "list")
-		stash := findStashByName(stashes, configuration.StashName)
-		git("stash", "pop", stash)
+	if op.UncommittedChanges && op.HandleChanges == config.IncludeChanges {
+		if err := op.step(runner, "stash", func() error {
+			pathspec, err := mobIgnorePathspec(runner)
+			if err != nil {
+				return err
+			}
+			if err := git(runner, append([]string{"stash", "push", "--include-untracked", "--message", op.StashName}, pathspec...)...); err != nil {
+				return err
+			}
+			say.Info("uncommitted changes were stashed. If an error occurs later on, you can recover them with 'git stash pop'.")
+			return nil
+		}); err != nil {
+			return err
+		}
 	}
 
-	say.Info("you are on wip branch '" + currentWipBranch.String() + "' (base branch '" + currentBaseBranch.String() + "')")
-	sayLastCommitsList(currentBaseBranch, currentWipBranch, configuration)
+	if err := op.step(runner, "merge-base", func() error {
+		if mobProgramming, err := isMobProgramming(runner, configuration); err != nil || mobProgramming {
+			return err
+		}
+		return git(runner, "merge", "FETCH_HEAD", "--ff-only")
+	}); err != nil {
+		return err
+	}
 
-	openLastModifiedFileIfPresent(configuration)
+	hasRemoteWipBranch, err := currentWipBranch.hasRemoteBranch(runner, configuration)
+	if err != nil {
+		return err
+	}
+	if hasRemoteWipBranch {
+		if err := op.step(runner, "join-wip", func() error {
+			return startJoinMobSession(runner, configuration)
+		}); err != nil {
+			return err
+		}
+		if hasMobIncludeFile(runner) {
+			if err := op.step(runner, "restore-include", func() error {
+				return restoreIncludedFiles(runner, configuration, currentWipBranch)
+			}); err != nil {
+				return err
+			}
+		}
+	} else {
+		if err := warnForActiveWipBranches(runner, configuration, currentBaseBranch); err != nil {
+			return err
+		}
 
-	return nil // no error
+		if err := op.step(runner, "create-wip", func() error {
+			return startNewMobSession(runner, configuration)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if op.completed("stash") {
+		if err := op.step(runner, "stash-pop", func() error {
+			stashes, err := silentgit(runner, "stash", "list")
+			if err != nil {
+				return err
+			}
+			stash := findStashByName(stashes, op.StashName)
+			return git(runner, "stash", "pop", stash)
+		}); err != nil {
+			return err
+		}
+	}
+	if err := op.finish(runner, configuration); err != nil {
+		return err
+	}
+	if DryRun {
+		return nil
+	}
+
+	say.Info("you are on wip branch '" + currentWipBranch.String() + "' (base branch '" + currentBaseBranch.String() + "')")
+	if err := sayLastCommitsList(runner, currentBaseBranch, currentWipBranch, configuration); err != nil {
+		return err
+	}
+	sayHandoverNote(lastHandoverNote(runner, configuration, "HEAD"))
+	lastCommitMessage, err := lastCommitMessage(runner)
+	if err != nil {
+		return err
+	}
+	if configuration.IsWipCommitMessage(lastCommitMessage) {
+		sayVerifyRun(lastCommitMessage)
+		if shown, err := showHandoverState(runner, configuration, lastCommitMessage); err != nil || shown {
+			return err
+		}
+	}
+	return openLastModifiedFileIfPresent(runner, configuration)
 }
 
-func sayFixUncommittedChanges(configuration config.Configuration) {
+func fixUncommittedChanges(configuration config.Configuration) []Fix {
 	var instructionInclude string
 	var instructionDiscard string
 	if configuration.StartCreate {
@@ -627,8 +927,10 @@ func sayFixUncommittedChanges(configuration config.Configuration) {
 	fixCommandInclude := fixCommandStart + " --include-uncommitted-changes"
 	fixCommandDiscard := fixCommandStart + " --discard-uncommitted-changes"
 
-	say.Fix(instructionInclude, fixCommandInclude)
-	say.Fix(instructionDiscard, fixCommandDiscard)
+	return []Fix{
+		{instructionInclude, fixCommandInclude},
+		{instructionDiscard, fixCommandDiscard},
+	}
 }
 
 func createFix(configuration config.Configuration) string {
@@ -660,34 +962,44 @@ func containsAny(list []string, elements ...string) bool {
 	return false
 }
 
-func createRemoteBranch(configuration config.Configuration, currentBaseBranch Branch) {
-	if !currentBaseBranch.hasRemoteBranch(configuration) && configuration.StartCreate {
-		git("push", configuration.RemoteName, currentBaseBranch.String(), "--set-upstream")
-	} else if currentBaseBranch.hasRemoteBranch(configuration) && configuration.StartCreate {
+func createRemoteBranch(runner GitRunner, configuration config.Configuration, currentBaseBranch Branch) error {
+	if !configuration.StartCreate {
+		return nil
+	}
+	hasRemoteBranch, err := currentBaseBranch.hasRemoteBranch(runner, configuration)
+	if err != nil {
+		return err
+	}
+	if hasRemoteBranch {
 		say.Info("Remote branch " + currentBaseBranch.remote(configuration).String() + " already exists")
+		return nil
 	}
+	return git(runner, "push", configuration.RemoteName, currentBaseBranch.String(), "--set-upstream")
 }
 
-func openLastModifiedFileIfPresent(configuration config.Configuration) {
+func openLastModifiedFileIfPresent(runner GitRunner, configuration config.Configuration) error {
 	if !configuration.IsOpenCommandGiven() {
 		say.Debug("No open command given")
-		return
+		return nil
 	}
 
 	say.Debug("Try to open last modified file")
-	if !lastCommitIsWipCommit(configuration) {
+	lastCommitMessage, err := lastCommitMessage(runner)
+	if err != nil {
+		return err
+	}
+	if !configuration.IsWipCommitMessage(lastCommitMessage) {
 		say.Debug("Last commit isn't a WIP commit.")
-		return
+		return nil
 	}
-	lastCommitMessage := lastCommitMessage()
 	split := strings.Split(lastCommitMessage, "lastFile:")
 	if len(split) == 1 {
 		say.Warning("Couldn't find last modified file in commit message!")
-		return
+		return nil
 	}
 	if len(split) > 2 {
 		say.Warning("Could not determine last modified file from commit message, separator was used multiple times!")
-		return
+		return nil
 	}
 	lastModifiedFile := split[1]
 	if strings.HasPrefix(lastModifiedFile, "\"") {
@@ -695,54 +1007,98 @@ func openLastModifiedFileIfPresent(configuration config.Configuration) {
 	}
 	if lastModifiedFile == "" {
 		say.Debug("Could not find last modified file in commit message")
+		return nil
+	}
+	rootDir, err := gitRootDir(runner)
+	if err != nil {
+		return err
+	}
+	openInEditor(runner, configuration, rootDir+"/"+lastModifiedFile, lastLineFromCommitMessage(lastCommitMessage), 1)
+	return nil
+}
+
+// lastLineFromCommitMessage returns the first changed line of the last modified file or 0 if it is unknown
+func lastLineFromCommitMessage(message string) int {
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, "lastLine:") {
+			lastLine, err := strconv.Atoi(strings.TrimPrefix(line, "lastLine:"))
+			if err != nil {
+				say.Debug("Could not parse " + line)
+				return 0
+			}
+			return lastLine
+		}
+	}
+	return 0
+}
+
+func openInEditor(runner GitRunner, configuration config.Configuration, filePath string, line int, column int) {
+	commandname, args, err := openCommandFor(runner, configuration, filePath, line, column)
+	if err == nil && DryRun {
+		say.Indented(commandname + " " + strings.Join(args, " "))
 		return
 	}
-	lastModifiedFilePath := gitRootDir() + "/" + lastModifiedFile
-	commandname, args := openCommandFor(configuration, lastModifiedFilePath)
-	_, err := startCommand(commandname, args...)
+	if err == nil {
+		_, err = startCommand(runner.Dir(), commandname, args...)
+	}
 	if err != nil {
-		say.Warning(fmt.Sprintf("Couldn't open last modified file on your system (%s)", runtime.GOOS))
+		say.Warning(fmt.Sprintf("Couldn't open file on your system (%s)", runtime.GOOS))
 		say.Warning(err.Error())
 		return
 	}
-	say.Debug("Open last modified file: " + lastModifiedFilePath)
+	say.Debug("Open file: " + filePath)
 }
 
-func warnForActiveWipBranches(configuration config.Configuration, currentBaseBranch Branch) {
-	if isMobProgramming(configuration) {
-		return
+func warnForActiveWipBranches(runner GitRunner, configuration config.Configuration, currentBaseBranch Branch) error {
+	if mobProgramming, err := isMobProgramming(runner, configuration); err != nil || mobProgramming {
+		return err
 	}
 
 	// TODO show all active wip branches, even non-qualified ones
-	existingWipBranches := getWipBranchesForBaseBranch(currentBaseBranch, configuration)
+	existingWipBranches, err := getWipBranchesForBaseBranch(runner, currentBaseBranch, configuration)
+	if err != nil {
+		return err
+	}
 	if len(existingWipBranches) > 0 && configuration.WipBranchQualifier == "" {
 		say.Warning("Creating a new wip branch even though preexisting wip branches have been detected.")
 		for _, wipBranch := range existingWipBranches {
 			say.WithPrefix(wipBranch, "  - ")
 		}
 	}
+	return nil
 }
 
-func sayUntrackedFilesInfo() {
-	untrackedFiles := getUntrackedFiles()
+func sayUntrackedFilesInfo(runner GitRunner) error {
+	untrackedFiles, err := getUntrackedFiles(runner)
+	if err != nil {
+		return err
+	}
 	hasUntrackedFiles := len(untrackedFiles) > 0
 	if hasUntrackedFiles {
 		say.Info("untracked files present:")
 		say.InfoIndented(untrackedFiles)
 	}
+	return nil
 }
 
-func sayUnstagedChangesInfo() {
-	unstagedChanges := getUnstagedChanges()
+func sayUnstagedChangesInfo(runner GitRunner) error {
+	unstagedChanges, err := getUnstagedChanges(runner)
+	if err != nil {
+		return err
+	}
 	hasUnstagedChanges := len(unstagedChanges) > 0
 	if hasUnstagedChanges {
 		say.Info("unstaged changes present:")
 		say.InfoIndented(unstagedChanges)
 	}
+	return nil
 }
 
-func getWipBranchesForBaseBranch(currentBaseBranch Branch, configuration config.Configuration) []string {
-	remoteBranches := gitRemoteBranches()
+func getWipBranchesForBaseBranch(runner GitRunner, currentBaseBranch Branch, configuration config.Configuration) ([]string, error) {
+	remoteBranches, err := gitRemoteBranches(runner)
+	if err != nil {
+		return nil, err
+	}
 	say.Debug("check on current base branch " + currentBaseBranch.String() + " with remote branches " + strings.Join(remoteBranches, ","))
 
 	remoteBranchWithQualifier := currentBaseBranch.addWipPrefix(configuration).addWipQualifier(configuration).remote(configuration).Name
@@ -759,27 +1115,39 @@ func getWipBranchesForBaseBranch(currentBaseBranch Branch, configuration config.
 		}
 	}
 
-	return result
+	return result, nil
 }
 
-func startJoinMobSession(configuration config.Configuration) {
-	baseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+func startJoinMobSession(runner GitRunner, configuration config.Configuration) error {
+	baseBranch, currentWipBranch, err := currentBranches(runner, configuration)
+	if err != nil {
+		return err
+	}
 
 	say.Info("joining existing session from " + currentWipBranch.remote(configuration).String())
-	if currentWipBranch.hasLocalBranch() && doBranchesDiverge(baseBranch.remote(configuration).Name, currentWipBranch.Name) {
+	if hasLocalBranch, err := currentWipBranch.hasLocalBranch(runner); err != nil {
+		return err
+	} else if hasLocalBranch && doBranchesDiverge(runner, baseBranch.remote(configuration).Name, currentWipBranch.Name) {
 		say.Warning("Careful, your wip branch (" + currentWipBranch.Name + ") diverges from your main branch (" + baseBranch.remote(configuration).Name + ") !")
 	}
 
-	git("checkout", "-B", currentWipBranch.Name, currentWipBranch.remote(configuration).Name)
-	git("branch", "--set-upstream-to="+currentWipBranch.remote(configuration).Name, currentWipBranch.Name)
+	if err := git(runner, "checkout", "-B", currentWipBranch.Name, currentWipBranch.remote(configuration).Name); err != nil {
+		return err
+	}
+	return git(runner, "branch", "--set-upstream-to="+currentWipBranch.remote(configuration).Name, currentWipBranch.Name)
 }
 
-func startNewMobSession(configuration config.Configuration) {
-	currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+func startNewMobSession(runner GitRunner, configuration config.Configuration) error {
+	currentBaseBranch, currentWipBranch, err := currentBranches(runner, configuration)
+	if err != nil {
+		return err
+	}
 
 	say.Info("starting new session from " + currentBaseBranch.remote(configuration).String())
-	git("checkout", "-B", currentWipBranch.Name, currentBaseBranch.remote(configuration).Name)
-	gitWithoutEmptyStrings(append(gitPushArgs(configuration), gitHooksOption(configuration), "--set-upstream", configuration.RemoteName, currentWipBranch.Name+":"+currentWipBranch.Name)...)
+	if err := git(runner, "checkout", "-B", currentWipBranch.Name, currentBaseBranch.remote(configuration).Name); err != nil {
+		return err
+	}
+	return gitWithoutEmptyStrings(runner, append(gitPushArgs(configuration), gitHooksOption(configuration), "--set-upstream", configuration.RemoteName, currentWipBranch.Name+":"+currentWipBranch.Name)...)
 }
 
 func gitPushArgs(c config.Configuration) []string {
@@ -790,12 +1158,12 @@ func gitPushArgs(c config.Configuration) []string {
 	return append(pushArgs, "--push-option", "ci.skip")
 }
 
-func getUntrackedFiles() string {
-	return silentgit("ls-files", "--others", "--exclude-standard", "--full-name")
+func getUntrackedFiles(runner GitRunner) (string, error) {
+	return silentgit(runner, "ls-files", "--others", "--exclude-standard", "--full-name")
 }
 
-func getUnstagedChanges() string {
-	return silentgit("diff", "--stat")
+func getUnstagedChanges(runner GitRunner) (string, error) {
+	return silentgit(runner, "diff", "--stat")
 }
 
 func findStashByName(stashes string, stash string) string {
@@ -809,67 +1177,197 @@ func findStashByName(stashes string, stash string) string {
 	return "unknown"
 }
 
-func next(configuration config.Configuration) {
-	if !isMobProgramming(configuration) {
-		say.Fix("to start working together, use", configuration.Mob("start"))
-		return
+func next(runner GitRunner, configuration config.Configuration) error {
+	if configuration.Handover == config.HandoverStash {
+		return nextHandover(runner, configuration)
+	}
+	if mobProgramming, err := isMobProgramming(runner, configuration); err != nil {
+		return err
+	} else if !mobProgramming {
+		return errNotMobProgramming(configuration)
 	}
 
-	if !configuration.HasCustomCommitMessage() && configuration.RequireCommitMessage && hasUncommittedChanges() {
-		say.Error("commit message required")
-		return
+	if !configuration.HasCustomCommitMessage() && configuration.RequireCommitMessage {
+		if uncommittedChanges, err := hasUncommittedChanges(runner); err != nil {
+			return err
+		} else if uncommittedChanges {
+			return newMobError(ErrCommitMessageRequired, "commit message required",
+				Fix{"To hand over with a commit message, use", configuration.Mob("next --message \"<message>\"")})
+		}
 	}
 
-	currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+	if err := failIfOperationInProgress(runner, configuration); err != nil {
+		return err
+	}
 
-	if isNothingToCommit() {
-		if currentWipBranch.hasLocalCommits(configuration) {
-			gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, currentWipBranch.Name)
-		} else {
+	configuration, err := withHandoverNote(runner, configuration)
+	if err != nil {
+		return err
+	}
+	configuration, err = withVerifyRun(runner, configuration)
+	if err != nil {
+		return err
+	}
+
+	currentBaseBranch, currentWipBranch, err := currentBranches(runner, configuration)
+	if err != nil {
+		return err
+	}
+	op, err := beginOperation(runner, "next", currentBaseBranch, currentWipBranch, configuration)
+	if err != nil {
+		return err
+	}
+	op.NextStay = configuration.NextStay
+	op.save(runner)
+	return nextSteps(runner, configuration, op)
+}
+
+func nextSteps(runner GitRunner, configuration config.Configuration, op *operation) error {
+	currentBaseBranch, currentWipBranch := op.branches()
+
+	if err := op.step(runner, "wip-commit", func() error {
+		nothingToCommit, err := isNothingToCommit(runner)
+		if err != nil {
+			return err
+		}
+		if nothingToCommit && configuration.NextNote == "" {
+			return nil
+		}
+		if err := makeWipCommit(runner, configuration); err != nil {
+			return err
+		}
+		op.MadeWipCommit = true
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := op.step(runner, "push-wip", func() error {
+		hasLocalCommits, err := currentWipBranch.hasLocalCommits(runner, configuration)
+		if err != nil {
+			return err
+		}
+		if !op.MadeWipCommit && !hasLocalCommits {
 			say.Info("nothing was done, so nothing to commit")
+			return nil
 		}
-	} else {
-		makeWipCommit(configuration)
-		gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, currentWipBranch.Name)
+		return gitWithoutEmptyStrings(runner, "push", gitHooksOption(configuration), configuration.RemoteName, currentWipBranch.Name)
+	}); err != nil {
+		return err
+	}
+	if hasMobIncludeFile(runner) {
+		if err := op.step(runner, "push-include", func() error {
+			return pushIncludedFiles(runner, configuration, currentWipBranch)
+		}); err != nil {
+			return err
+		}
+	}
+	if err := showNext(runner, configuration); err != nil {
+		return err
 	}
-	showNext(configuration)
 
-	if !configuration.NextStay {
-		git("checkout", currentBaseBranch.Name)
+	if !op.NextStay {
+		if err := op.step(runner, "checkout-base", func() error {
+			return git(runner, "checkout", currentBaseBranch.Name)
+		}); err != nil {
+			return err
+		}
 	}
+	return op.finish(runner, configuration)
 }
 
-func getChangesOfLastCommit() string {
-	return silentgit("diff", "HEAD^1", "--stat")
+func getChangesOfLastCommit(runner GitRunner) (string, error) {
+	return silentgit(runner, "diff", "HEAD^1", "--stat")
 }
 
-func getCachedChanges() string {
-	return silentgit("diff", "--cached", "--stat")
+func getCachedChanges(runner GitRunner) (string, error) {
+	return silentgit(runner, "diff", "--cached", "--stat")
 }
 
-func makeWipCommit(configuration config.Configuration) {
-	git("add", "--all")
-	commitMessage := createWipCommitMessage(configuration)
-	gitWithoutEmptyStrings("commit", "--message", commitMessage, gitHooksOption(configuration))
-	say.InfoIndented(getChangesOfLastCommit())
-	say.InfoIndented(gitCommitHash())
+func makeWipCommit(runner GitRunner, configuration config.Configuration) error {
+	if err := stageChanges(runner); err != nil {
+		return err
+	}
+	commitMessage, err := createWipCommitMessage(runner, configuration)
+	if err != nil {
+		return err
+	}
+	allowEmpty := ""
+	if configuration.NextNote != "" {
+		allowEmpty = "--allow-empty" // a note is worth handing over without changes
+	}
+	if err := gitWithoutEmptyStrings(runner, "commit", "--message", commitMessage, gitHooksOption(configuration), allowEmpty); err != nil {
+		return err
+	}
+	if DryRun {
+		return nil
+	}
+	consumeHandoverState(runner, configuration)
+	changes, err := getChangesOfLastCommit(runner)
+	if err != nil {
+		return err
+	}
+	say.InfoIndented(changes)
+	say.InfoIndented(gitCommitHash(runner))
+	return nil
 }
 
-func createWipCommitMessage(configuration config.Configuration) string {
+func createWipCommitMessage(runner GitRunner, configuration config.Configuration) (string, error) {
 	commitMessage := configuration.WipCommitMessage
 
-	lastModifiedFilePath := getPathOfLastModifiedFile()
+	if configuration.NextNote != "" {
+		commitMessage += "\n\n" + handoverNoteCommitMessage(configuration.NextNote)
+	}
+	if verifyRun := verifyRunCommitMessage(configuration.NextVerifyResult); verifyRun != "" {
+		commitMessage += "\n\n" + verifyRun
+	}
+	if state, err := handoverStateCommitMessage(runner, configuration); err != nil {
+		return "", err
+	} else if state != "" {
+		commitMessage += "\n\n" + state
+	}
+	rootDir, err := gitRootDir(runner)
+	if err != nil {
+		return "", err
+	}
+	lastModifiedFilePath := getPathOfLastModifiedFile(runner, rootDir)
 	if lastModifiedFilePath != "" {
-		commitMessage += "\n\nlastFile:" + lastModifiedFilePath
+		// lastLine comes first, as older versions read everything after lastFile: as the path
+		commitMessage += "\n\n"
+		if lastLine := getFirstChangedLine(runner, rootDir, lastModifiedFilePath); lastLine > 0 {
+			commitMessage += "lastLine:" + strconv.Itoa(lastLine) + "\n"
+		}
+		commitMessage += "lastFile:" + quoteIfNecessary(lastModifiedFilePath)
 	}
 
-	return commitMessage
+	return commitMessage, nil
 }
 
-// uses git status --porcelain. To work properly files have to be staged.
-func getPathOfLastModifiedFile() string {
-	rootDir := gitRootDir()
-	files := getModifiedFiles(rootDir)
+// getFirstChangedLine returns the line of the first change in the file compared to HEAD, 0 if it can't be determined
+func getFirstChangedLine(runner GitRunner, rootDir string, relativeFilepath string) int {
+	_, diff, err := runner.In(rootDir).Query("diff", "--no-color", "--no-ext-diff", "-U0", "HEAD", "--", relativeFilepath)
+	if err != nil {
+		say.Debug("git diff failed: " + err.Error())
+		return 0
+	}
+	hunk := regexp.MustCompile(`(?m)^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`).FindStringSubmatch(diff)
+	if hunk == nil {
+		return 0
+	}
+	line, _ := strconv.Atoi(hunk[1])
+	return max(line, 1) // a deletion at the beginning of the file starts at line 0
+}
+
+// quoteIfNecessary quotes paths that would break the commit message, e.g. with a newline
+func quoteIfNecessary(path string) string {
+	if quoted := strconv.Quote(path); quoted != "\""+path+"\"" || strings.HasPrefix(path, "\"") {
+		return quoted
+	}
+	return path
+}
+
+// getPathOfLastModifiedFile returns the modified file that was touched last, staged or not
+func getPathOfLastModifiedFile(runner GitRunner, rootDir string) string {
+	files := getModifiedFiles(runner, rootDir)
 	lastModifiedFilePath := ""
 	lastModifiedTime := time.Time{}
 
@@ -899,31 +1397,66 @@ func getPathOfLastModifiedFile() string {
 	return lastModifiedFilePath
 }
 
-// uses git status --porcelain. To work properly files have to be staged.
-func getModifiedFiles(rootDir string) []string {
+// getModifiedFiles returns the changed, added, renamed and untracked files that still exist, relative to rootDir.
+// It reads git status --porcelain=v2 -z, so paths are never quoted and may contain any character.
+func getModifiedFiles(runner GitRunner, rootDir string) []string {
 	say.Debug("Find modified files")
-	oldWorkingDir := workingDir
-	workingDir = rootDir
-	gitstatus := silentgit("status", "--porcelain")
-	workingDir = oldWorkingDir
-	lines := strings.Split(gitstatus, "\n")
+	pathspec, err := mobIgnorePathspec(runner)
+	if err != nil {
+		say.Debug("reading " + mobIgnoreFile + " failed: " + err.Error())
+		return []string{}
+	}
+	_, gitstatus, err := runner.In(rootDir).Query(append([]string{"status", "--porcelain=v2", "-z", "--untracked-files=all"}, pathspec...)...)
+	if err != nil {
+		say.Debug("git status failed: " + err.Error())
+		return []string{}
+	}
+
 	files := []string{}
-	for _, line := range lines {
-		relativeFilepath := ""
-		if strings.HasPrefix(line, "M") {
-			relativeFilepath = strings.TrimPrefix(line, "M")
-		} else if strings.HasPrefix(line, "A") {
-			relativeFilepath = strings.TrimPrefix(line, "A")
-		} else {
+	entries := strings.Split(gitstatus, "\x00")
+	for i := 0; i < len(entries); i++ {
+		relativeFilepath, deleted := parseStatusEntry(entries[i])
+		if strings.HasPrefix(entries[i], "2 ") {
+			i++ // skip the original path of a rename or copy
+		}
+		if relativeFilepath == "" || deleted {
 			continue
 		}
-		relativeFilepath = strings.TrimSpace(relativeFilepath)
 		say.Debug(relativeFilepath)
 		files = append(files, relativeFilepath)
 	}
 	return files
 }
 
+// parseStatusEntry returns the path of a git status --porcelain=v2 entry and whether it was deleted in the working tree
+func parseStatusEntry(entry string) (path string, deleted bool) {
+	fields := strings.Split(entry, " ")
+	if fields[0] != "?" && len(fields) > 2 && strings.HasPrefix(fields[2], "S") {
+		return "", false // submodules are directories, there is nothing to open
+	}
+	switch fields[0] {
+	case "1": // 1 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <path>
+		if len(fields) < 9 {
+			return "", false
+		}
+		return strings.Join(fields[8:], " "), strings.Contains(fields[1], "D")
+	case "2": // 2 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <X><score> <path>
+		if len(fields) < 10 {
+			return "", false
+		}
+		return strings.Join(fields[9:], " "), strings.Contains(fields[1], "D")
+	case "u": // u <XY> <sub> <m1> <m2> <m3> <mW> <h1> <h2> <h3> <path>
+		if len(fields) < 11 {
+			return "", false
+		}
+		return strings.Join(fields[10:], " "), false
+	case "?": // ? <path>
+		return strings.TrimPrefix(entry, "? "), false
+	default: // ignored files and the empty entry after the last separator
+		return "", false
+	}
+}
+
 func gitHooksOption(c config.Configuration) string {
 	if c.GitHooksEnabled {
 		return ""
@@ -932,80 +1465,262 @@ func gitHooksOption(c config.Configuration) string {
 	}
 }
 
-func fetch(configuration config.Configuration) {
-	git("fetch", configuration.RemoteName, "--prune")
+func fetch(runner GitRunner, configuration config.Configuration) error {
+	return git(runner, "fetch", configuration.RemoteName, "--prune")
 }
 
-func done(configuration config.Configuration) {
-	if !isMobProgramming(configuration) {
-		say.Fix("to start working together, use", configuration.Mob("start"))
-		return
+func done(runner GitRunner, configuration config.Configuration) error {
+	if configuration.DoneContinue || configuration.DoneAbort {
+		op, err := loadOperation(runner)
+		if err != nil || op.Command != "done" {
+			return newMobError(ErrInvalidArgument, "there is no done in progress")
+		}
+		if configuration.DoneAbort {
+			return rollbackOperation(runner, configuration, op)
+		}
+		return doneContinue(runner, configuration, op)
+	}
+	if configuration.Handover == config.HandoverStash {
+		return doneHandover(runner, configuration)
+	}
+	if err := failIfOperationInProgress(runner, configuration); err != nil {
+		return err
 	}
 
-	git("fetch", configuration.RemoteName, "--prune")
+	if mobProgramming, err := isMobProgramming(runner, configuration); err != nil {
+		return err
+	} else if !mobProgramming {
+		return errNotMobProgramming(configuration)
+	}
 
-	baseBranch, wipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+	if configuration.DonePullRequest {
+		return donePullRequest(runner, configuration)
+	}
 
-	if wipBranch.hasRemoteBranch(configuration) {
-		if configuration.DoneSquash == config.SquashWip {
-			git("merge", "FETCH_HEAD", "--ff-only")
-			squashWip(configuration)
-		}
-		uncommittedChanges := hasUncommittedChanges()
-		if uncommittedChanges {
-			makeWipCommit(configuration)
+	if err := git(runner, "fetch", configuration.RemoteName, "--prune"); err != nil {
+		return err
+	}
+
+	baseBranch, wipBranch, err := currentBranches(runner, configuration)
+	if err != nil {
+		return err
+	}
+
+	hasRemoteWipBranch, err := wipBranch.hasRemoteBranch(runner, configuration)
+	if err != nil {
+		return err
+	}
+	if hasRemoteWipBranch {
+		op, err := beginOperation(runner, "done", baseBranch, wipBranch, configuration)
+		if err != nil {
+			return err
 		}
-		gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, wipBranch.Name)
+		op.DoneSquash = configuration.DoneSquash
+		op.save(runner)
+		return doneSteps(runner, configuration, op)
+	}
+
+	if err := git(runner, "checkout", baseBranch.Name); err != nil {
+		return err
+	}
+	if err := git(runner, "branch", "-D", wipBranch.Name); err != nil {
+		return err
+	}
+	if err := git(runner, "pull", "--ff-only"); err != nil {
+		return err
+	}
+	say.Info("someone else already ended your session")
+	return nil
+}
 
-		git("checkout", baseBranch.Name)
-		git("merge", baseBranch.remote(configuration).Name, "--ff-only")
-		mergeFailed := gitIgnoreFailure("merge", squashOrCommit(configuration), "--ff", wipBranch.Name)
+func doneSteps(runner GitRunner, configuration config.Configuration, op *operation) error {
+	baseBranch, wipBranch := op.branches()
+	configuration.DoneSquash = op.DoneSquash
 
-		if mergeFailed != nil {
-			// TODO should this be an error and a fix for that error?
-			say.Warning("Skipped deleting " + wipBranch.Name + " because of merge conflicts.")
-			say.Warning("To fix this, solve the merge conflict manually, commit, push, and afterwards delete " + wipBranch.Name)
-			return
+	if op.DoneSquash == config.SquashWip {
+		if err := op.step(runner, "squash-wip", func() error {
+			if err := git(runner, "merge", "FETCH_HEAD", "--ff-only"); err != nil {
+				return err
+			}
+			return squashWip(runner, configuration)
+		}); err != nil {
+			return err
+		}
+	}
+	if err := op.step(runner, "wip-commit", func() error {
+		if uncommittedChanges, err := hasUncommittedChanges(runner); err != nil || !uncommittedChanges {
+			return err
+		}
+		if err := makeWipCommit(runner, configuration); err != nil {
+			return err
+		}
+		op.MadeWipCommit = true
+		op.WipCommit = gitCommitHash(runner)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := op.step(runner, "push-wip", func() error {
+		return gitWithoutEmptyStrings(runner, "push", gitHooksOption(configuration), configuration.RemoteName, wipBranch.Name)
+	}); err != nil {
+		return err
+	}
+	if err := op.step(runner, "checkout-base", func() error {
+		if err := git(runner, "checkout", baseBranch.Name); err != nil {
+			return err
 		}
+		return git(runner, "merge", baseBranch.remote(configuration).Name, "--ff-only")
+	}); err != nil {
+		return err
+	}
 
-		git("branch", "-D", wipBranch.Name)
+	if !op.completed("merge-wip") {
+		if err := gitIgnoreFailure(runner, "merge", squashOrCommit(configuration), "--ff", wipBranch.Name); err != nil {
+			op.MergeConflict = true
+			op.save(runner)
+			return newMobError(ErrMergeConflict, "merging "+wipBranch.Name+" into "+baseBranch.Name+" failed because of merge conflicts", doneMergeConflictFixes(configuration)...)
+		}
+		op.complete(runner, "merge-wip")
+	}
 
-		if uncommittedChanges && configuration.DoneSquash != config.Squash { // give the user the chance to name their final commit
-			git("reset", "--soft", "HEAD^")
+	if err := op.step(runner, "delete-wip", func() error {
+		return git(runner, "branch", "-D", wipBranch.Name)
+	}); err != nil {
+		return err
+	}
+	if op.MadeWipCommit && op.DoneSquash != config.Squash { // give the user the chance to name their final commit
+		if err := op.step(runner, "undo-wip-commit", func() error {
+			return undoWipCommit(runner, configuration, op)
+		}); err != nil {
+			return err
 		}
+	}
+	if err := op.step(runner, "delete-remote-wip", func() error {
+		return gitWithoutEmptyStrings(runner, "push", gitHooksOption(configuration), configuration.RemoteName, "--delete", wipBranch.Name)
+	}); err != nil {
+		return err
+	}
+	if err := op.step(runner, "delete-include", func() error {
+		return deleteIncludedFiles(runner, configuration, wipBranch)
+	}); err != nil {
+		return err
+	}
+	if err := op.finish(runner, configuration); err != nil {
+		return err
+	}
 
-		gitWithoutEmptyStrings("push", gitHooksOption(configuration), configuration.RemoteName, "--delete", wipBranch.Name)
+	cachedChanges, err := getCachedChanges(runner)
+	if err != nil {
+		return err
+	}
+	hasCachedChanges := len(cachedChanges) > 0
+	if hasCachedChanges {
+		say.InfoIndented(cachedChanges)
+	}
+	gitDir, err := gitDir(runner)
+	if err != nil {
+		return err
+	}
+	if err := appendCoauthorsToSquashMsg(runner, gitDir); err != nil {
+		say.Warning(err.Error())
+	}
 
-		cachedChanges := getCachedChanges()
-		hasCachedChanges := len(cachedChanges) > 0
-		if hasCachedChanges {
-			say.InfoIndented(cachedChanges)
+	headBeforeCommit := gitCommitHash(runner)
+	uncommittedChanges, err := hasUncommittedChanges(runner)
+	if err != nil {
+		return err
+	}
+	if uncommittedChanges {
+		if !configuration.DoneCommit {
+			say.Next("To finish, use", "git commit")
+			return nil
 		}
-		err := appendCoauthorsToSquashMsg(gitDir())
-		if err != nil {
-			say.Warning(err.Error())
+		if err := commitDone(runner, configuration); err != nil {
+			return err
 		}
+	} else if configuration.DoneSquash == config.Squash {
+		say.Info("nothing was done, so nothing to commit")
+	}
 
-		if hasUncommittedChanges() {
-			say.Next("To finish, use", "git commit")
-		} else if configuration.DoneSquash == config.Squash {
-			say.Info("nothing was done, so nothing to commit")
+	if configuration.DonePush {
+		return pushDone(runner, configuration, baseBranch, headBeforeCommit)
+	}
+	return nil
+}
+
+// undoWipCommit turns the wip commit done made back into uncommitted changes. After resolving the merge conflicts of
+// --no-squash, HEAD is the merge commit, so the wip commit is reverted and its changes are applied again instead.
+func undoWipCommit(runner GitRunner, configuration config.Configuration, op *operation) error {
+	if gitCommitHash(runner) == op.WipCommit {
+		return git(runner, "reset", "--soft", "HEAD^")
+	}
+	if op.WipCommit == "" {
+		say.Warning("kept the wip commit, as it is unknown which commit it was")
+		return nil
+	}
+	if err := gitIgnoreFailure(runner, "revert", "--no-commit", op.WipCommit); err != nil {
+		if err := git(runner, "revert", "--abort"); err != nil {
+			return err
 		}
+		say.Warning("kept the wip commit " + op.WipCommit + ", as it can't be reverted without conflicts")
+		return nil
+	}
+	if err := gitWithoutEmptyStrings(runner, "commit", "--no-edit", gitHooksOption(configuration)); err != nil {
+		return err
+	}
+	return git(runner, "cherry-pick", "--no-commit", op.WipCommit)
+}
 
-	} else {
-		git("checkout", baseBranch.Name)
-		git("branch", "-D", wipBranch.Name)
-		git("pull", "--ff-only")
-		say.Info("someone else already ended your session")
+func commitDone(runner GitRunner, configuration config.Configuration) error {
+	gitDir, err := gitDir(runner)
+	if err != nil {
+		return err
 	}
+	if configuration.DoneCommitMessage == "" {
+		if !hasSquashMsg(gitDir) {
+			return newMobError(ErrCommitMessageRequired, "commit message required",
+				Fix{"To finish with your own commit message, use", configuration.Mob("done --commit --message \"<message>\"")})
+		}
+		return gitWithoutEmptyStrings(runner, "commit", "--no-edit", "--cleanup=strip", gitHooksOption(configuration))
+	}
+
+	coauthors, err := coauthorsFromSquashMsg(runner, gitDir)
+	if err != nil {
+		say.Warning(err.Error())
+	}
+	commitMessage := configuration.DoneCommitMessage
+	if len(coauthors) > 0 {
+		commitMessage += "\n\n" + coauthorTrailers(coauthors)
+	}
+	return gitWithoutEmptyStrings(runner, "commit", "--message", commitMessage, gitHooksOption(configuration))
+}
+
+func pushDone(runner GitRunner, configuration config.Configuration, baseBranch Branch, headBeforeCommit string) error {
+	if unpushedCommits, err := baseBranch.hasUnpushedCommits(runner, configuration); err != nil {
+		return err
+	} else if !unpushedCommits {
+		say.Info("nothing to push")
+		return nil
+	}
+	err := gitIgnoreFailure(runner, deleteEmptyStrings([]string{"push", gitHooksOption(configuration), configuration.RemoteName, baseBranch.Name})...)
+	if err != nil {
+		mobError := newMobError(ErrPushRejected, "pushing base branch '"+baseBranch.Name+"' to "+configuration.RemoteName+" was rejected")
+		if headBeforeCommit != gitCommitHash(runner) {
+			mobError.Fixes = append(mobError.Fixes, Fix{"To undo the final commit and keep its changes staged, use", "git reset --soft " + headBeforeCommit})
+		}
+		mobError.Fixes = append(mobError.Fixes, Fix{"To integrate the remote changes and push again, use", "git pull --rebase && git push"})
+		return mobError
+	}
+	say.Info("pushed base branch '" + baseBranch.Name + "' to " + configuration.RemoteName)
+	return nil
 }
 
-func gitDir() string {
-	return silentgit("rev-parse", "--absolute-git-dir")
+func gitDir(runner GitRunner) (string, error) {
+	return silentgit(runner, "rev-parse", "--absolute-git-dir")
 }
 
-func gitRootDir() string {
-	return silentgit("rev-parse", "--show-toplevel")
+func gitRootDir(runner GitRunner) (string, error) {
+	return silentgit(runner, "rev-parse", "--show-toplevel")
 }
 
 func squashOrCommit(configuration config.Configuration) string {
@@ -1016,12 +1731,14 @@ func squashOrCommit(configuration config.Configuration) string {
 	}
 }
 
-func sayLastCommitsList(currentBaseBranch Branch, currentWipBranch Branch, configuration config.Configuration) {
+func sayLastCommitsList(runner GitRunner, currentBaseBranch Branch, currentWipBranch Branch, configuration config.Configuration) error {
 	commitsBaseWipBranch := currentBaseBranch.String() + ".." + currentWipBranch.String()
-	log, err := silentgitignorefailure("--no-pager", "log", commitsBaseWipBranch, "--pretty=format:%h %cr <%an>", "--abbrev-commit")
+	log, err := silentgitignorefailure(runner, "--no-pager", "log", commitsBaseWipBranch, "--pretty=format:%h %cr <%an>", "--abbrev-commit")
 	if err != nil {
 		commitsBaseWipBranch = currentBaseBranch.remote(configuration).String() + ".." + currentWipBranch.String()
-		log = silentgit("--no-pager", "log", commitsBaseWipBranch, "--pretty=format:%h %cr <%an>", "--abbrev-commit")
+		if log, err = silentgit(runner, "--no-pager", "log", commitsBaseWipBranch, "--pretty=format:%h %cr <%an>", "--abbrev-commit"); err != nil {
+			return err
+		}
 	}
 	lines := strings.Split(log, "\n")
 	if len(lines) > 5 {
@@ -1031,6 +1748,7 @@ func sayLastCommitsList(currentBaseBranch Branch, currentWipBranch Branch, confi
 	ReverseSlice(lines)
 	output := strings.Join(lines, "\n")
 	say.Say(output)
+	return nil
 }
 
 func ReverseSlice(s interface{}) {
@@ -1041,65 +1759,100 @@ func ReverseSlice(s interface{}) {
 	}
 }
 
-func isNothingToCommit() bool {
-	output := silentgit("status", "--porcelain")
-	return len(output) == 0
+func isNothingToCommit(runner GitRunner) (bool, error) {
+	pathspec, err := mobIgnorePathspec(runner)
+	if err != nil {
+		return false, err
+	}
+	output, err := silentgit(runner, append([]string{"status", "--porcelain"}, pathspec...)...)
+	return len(output) == 0, err
 }
 
-func hasUncommittedChanges() bool {
-	return !isNothingToCommit()
+func hasUncommittedChanges(runner GitRunner) (bool, error) {
+	nothingToCommit, err := isNothingToCommit(runner)
+	return !nothingToCommit, err
 }
 
-func isMobProgramming(configuration config.Configuration) bool {
-	currentBranch := gitCurrentBranch()
-	_, currentWipBranch := determineBranches(currentBranch, gitBranches(), configuration)
+func isMobProgramming(runner GitRunner, configuration config.Configuration) (bool, error) {
+	currentBranch, err := gitCurrentBranch(runner)
+	if err != nil {
+		return false, err
+	}
+	localBranches, err := gitBranches(runner)
+	if err != nil {
+		return false, err
+	}
+	_, currentWipBranch := determineBranches(currentBranch, localBranches, configuration)
 	say.Debug("current branch " + currentBranch.String() + " and currentWipBranch " + currentWipBranch.String())
-	return currentWipBranch == currentBranch
+	return currentWipBranch == currentBranch, nil
 }
 
-func gitBranches() []string {
-	return strings.Split(silentgit("branch", "--format=%(refname:short)"), "\n")
+func gitBranches(runner GitRunner) ([]string, error) {
+	branches, err := silentgit(runner, "branch", "--format=%(refname:short)")
+	return strings.Split(branches, "\n"), err
 }
 
-func gitRemoteBranches() []string {
-	return strings.Split(silentgit("branch", "--remotes", "--format=%(refname:short)"), "\n")
+func gitRemoteBranches(runner GitRunner) ([]string, error) {
+	branches, err := silentgit(runner, "branch", "--remotes", "--format=%(refname:short)")
+	return strings.Split(branches, "\n"), err
 }
 
-func gitCurrentBranch() Branch {
+func gitCurrentBranch(runner GitRunner) (Branch, error) {
 	// upgrade to branch --show-current when git v2.21 is more widely spread
-	return newBranch(silentgit("rev-parse", "--abbrev-ref", "HEAD"))
+	branch, err := silentgit(runner, "rev-parse", "--abbrev-ref", "HEAD")
+	return newBranch(branch), err
 }
 
-func doBranchesDiverge(ancestor string, successor string) bool {
-	_, _, err := runCommandSilent("git", "merge-base", "--is-ancestor", ancestor, successor)
+func doBranchesDiverge(runner GitRunner, ancestor string, successor string) bool {
+	_, _, err := runner.Query("merge-base", "--is-ancestor", ancestor, successor)
 	if err == nil {
 		return false
 	}
 	return true
 }
 
-func gitUserName() string {
-	output, _ := silentgitignorefailure("config", "--get", "user.name")
+func gitUserName(runner GitRunner) string {
+	output, _ := silentgitignorefailure(runner, "config", "--get", "user.name")
 	return output
 }
 
-func gitUserEmail() string {
-	return silentgit("config", "--get", "user.email")
+func gitUserEmail(runner GitRunner) (string, error) {
+	return silentgit(runner, "config", "--get", "user.email")
 }
 
-func showNext(configuration config.Configuration) {
+func showNext(runner GitRunner, configuration config.Configuration) error {
 	say.Debug("determining next person based on previous changes")
-	gitUserName := gitUserName()
+	gitUserName := gitUserName(runner)
 	if gitUserName == "" {
 		say.Warning("failed to detect who's next because you haven't set your git user name")
 		say.Fix("To fix, use", "git config --global user.name \"Your Name Here\"")
-		return
+		return nil
 	}
 
-	currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
-	commitsBaseWipBranch := currentBaseBranch.String() + ".." + currentWipBranch.String()
+	currentBaseBranch, currentWipBranch, err := currentBranches(runner, configuration)
+	if err != nil {
+		return err
+	}
+	nextTypist, previousCommitters, err := determineNextTypist(runner, currentBaseBranch, currentWipBranch, gitUserName)
+	if err != nil {
+		return err
+	}
+	if nextTypist != "" {
+		if len(previousCommitters) != 0 {
+			say.Info("Committers after your last commit: " + strings.Join(previousCommitters, ", "))
+		}
+		say.Info("***" + nextTypist + "*** is (probably) next.")
+	}
+	return nil
+}
 
-	changes := silentgit("--no-pager", "log", commitsBaseWipBranch, "--pretty=format:%an", "--abbrev-commit")
+func determineNextTypist(runner GitRunner, baseBranch Branch, wipBranch Branch, gitUserName string) (nextTypist string, previousCommitters []string, err error) {
+	commitsBaseWipBranch := baseBranch.String() + ".." + wipBranch.String()
+
+	changes, err := silentgit(runner, "--no-pager", "log", commitsBaseWipBranch, "--pretty=format:%an", "--abbrev-commit")
+	if err != nil {
+		return "", nil, err
+	}
 	lines := strings.Split(strings.Replace(changes, "\r\n", "\n", -1), "\n")
 	numberOfLines := len(lines)
 	say.Debug("there have been " + strconv.Itoa(numberOfLines) + " changes")
@@ -1107,37 +1860,40 @@ func showNext(configuration config.Configuration) {
 	if numberOfLines < 1 {
 		return
 	}
-	nextTypist, previousCommitters := findNextTypist(lines, gitUserName)
-	if nextTypist != "" {
-		if len(previousCommitters) != 0 {
-			say.Info("Committers after your last commit: " + strings.Join(previousCommitters, ", "))
-		}
-		say.Info("***" + nextTypist + "*** is (probably) next.")
-	}
+	nextTypist, previousCommitters = findNextTypist(lines, gitUserName)
+	return nextTypist, previousCommitters, nil
 }
 
 func version() {
 	say.Say("v" + versionNumber)
 }
 
-func silentgit(args ...string) string {
-	commandString, output, err := runCommandSilent("git", args...)
+// silentgit runs a git command that only reads the repository and returns its output
+func silentgit(runner GitRunner, args ...string) (string, error) {
+	commandString, output, err := runner.Query(args...)
 
 	if err != nil {
-		if !isGit() {
-			say.Error("expecting the current working directory to be a git repository.")
-		} else {
-			say.Error(commandString)
-			say.Error(output)
-			say.Error(err.Error())
-		}
-		Exit(1)
+		return "", gitError(runner, commandString, output, err)
 	}
-	return strings.TrimSpace(output)
+	return strings.TrimSpace(output), nil
 }
 
-func silentgitignorefailure(args ...string) (string, error) {
-	_, output, err := runCommandSilent("git", args...)
+// silentgitChange runs a git command that changes the repository without printing it and returns its output.
+// A dry run skips it like every other change, prints it for the plan and returns a placeholder for a hash.
+func silentgitChange(runner GitRunner, args ...string) (string, error) {
+	commandString, output, err := runner.Run(args...)
+
+	if err != nil {
+		return "", gitError(runner, commandString, output, err)
+	}
+	if DryRun {
+		say.Indented(commandString)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+func silentgitignorefailure(runner GitRunner, args ...string) (string, error) {
+	_, output, err := runner.Query(args...)
 
 	if err != nil {
 		return "", err
@@ -1155,68 +1911,68 @@ func deleteEmptyStrings(s []string) []string {
 	return r
 }
 
-func gitWithoutEmptyStrings(args ...string) {
+func gitWithoutEmptyStrings(runner GitRunner, args ...string) error {
 	argsWithoutEmptyStrings := deleteEmptyStrings(args)
-	git(argsWithoutEmptyStrings...)
+	return git(runner, argsWithoutEmptyStrings...)
 }
 
-func git(args ...string) {
+func git(runner GitRunner, args ...string) error {
 	say.Indented("git " + strings.Join(args, " "))
-	commandString, output, err := "", "", error(nil)
-	if GitPassthroughStderrStdout {
-		commandString, output, err = runCommand("git", args...)
-	} else {
-		commandString, output, err = runCommandSilent("git", args...)
-	}
+	commandString, output, err := runner.Run(args...)
 
 	if err != nil {
-		if !isGit() {
-			say.Error("expecting the current working directory to be a git repository.")
-		} else {
-			if strings.Contains(output, "does not support push options") {
-				say.Error("The receiving end does not support push options")
-				say.Fix("Disable the push option ci.skip in your .mob file or set the expected environment variable", "export MOB_SKIP_CI_PUSH_OPTION_ENABLED=false")
-			} else {
-				say.Error(commandString)
-				say.Error(output)
-				say.Error(err.Error())
-			}
-		}
-		Exit(1)
+		return gitError(runner, commandString, output, err)
 	}
+	return nil
 }
 
-func gitIgnoreFailure(args ...string) error {
-	commandString, output, err := "", "", error(nil)
-	if GitPassthroughStderrStdout {
-		commandString, output, err = runCommand("git", args...)
-	} else {
-		commandString, output, err = runCommandSilent("git", args...)
-	}
+// gitIgnoreFailure warns instead of failing if git fails, the caller decides what the error means
+func gitIgnoreFailure(runner GitRunner, args ...string) error {
+	commandString, output, err := runner.Run(args...)
 
 	if err != nil {
-		if !isGit() {
-			say.Error("expecting the current working directory to be a git repository.")
-			Exit(1)
-		} else {
-			say.Warning(commandString)
-			say.Warning(output)
-			say.Warning(err.Error())
-			return err
+		if !isGit(runner) {
+			return gitError(runner, commandString, output, err)
 		}
+		say.Warning(commandString)
+		say.Warning(output)
+		say.Warning(err.Error())
+		return err
 	}
 
 	say.Indented(commandString)
 	return nil
 }
 
-func gitCommitHash() string {
-	output, _ := silentgitignorefailure("rev-parse", "HEAD")
+func gitError(runner GitRunner, commandString string, output string, err error) *MobError {
+	if !isGit(runner) {
+		return newMobError(ErrNotGitRepository, "expecting the current working directory to be a git repository.")
+	}
+	if strings.Contains(output, "does not support push options") {
+		return newMobError(ErrGitFailed, "The receiving end does not support push options",
+			Fix{"Disable the push option ci.skip in your .mob file or set the expected environment variable", "export MOB_SKIP_CI_PUSH_OPTION_ENABLED=false"})
+	}
+	kind := ErrGitFailed
+	if strings.Contains(output, "[rejected]") || strings.Contains(output, "[remote rejected]") {
+		kind = ErrPushRejected
+	} else if strings.Contains(output, "CONFLICT") {
+		kind = ErrMergeConflict
+	}
+	return &MobError{Kind: kind, Message: commandString, Details: []string{output, err.Error()}}
+}
+
+func gitRefHash(runner GitRunner, ref string) string {
+	output, _ := silentgitignorefailure(runner, "rev-parse", "--verify", "--quiet", ref)
+	return output
+}
+
+func gitCommitHash(runner GitRunner) string {
+	output, _ := silentgitignorefailure(runner, "rev-parse", "HEAD")
 	return output
 }
 
-func gitVersion() string {
-	_, output, err := runCommandSilent("git", "--version")
+func gitVersion(runner GitRunner) string {
+	_, output, err := runner.Query("--version")
 	if err != nil {
 		say.Debug("gitVersion encountered an error: " + err.Error())
 		return ""
@@ -1224,15 +1980,16 @@ func gitVersion() string {
 	return strings.TrimSpace(output)
 }
 
-func isGit() bool {
-	_, _, err := runCommandSilent("git", "rev-parse")
+func isGit(runner GitRunner) bool {
+	_, _, err := runner.Query("rev-parse")
 	return err == nil
 }
 
-func runCommandSilent(name string, args ...string) (string, string, error) {
+func runCommandSilent(dir string, env []string, name string, args ...string) (string, string, error) {
 	command := exec.Command(name, args...)
-	if len(workingDir) > 0 {
-		command.Dir = workingDir
+	command.Dir = dir
+	if len(env) > 0 {
+		command.Env = append(os.Environ(), env...)
 	}
 	commandString := strings.Join(command.Args, " ")
 	say.Debug("Running command <" + commandString + "> in silent mode, capturing combined output")
@@ -1242,10 +1999,11 @@ func runCommandSilent(name string, args ...string) (string, string, error) {
 	return commandString, output, err
 }
 
-func runCommand(name string, args ...string) (string, string, error) {
+func runCommand(dir string, env []string, name string, args ...string) (string, string, error) {
 	command := exec.Command(name, args...)
-	if len(workingDir) > 0 {
-		command.Dir = workingDir
+	command.Dir = dir
+	if len(env) > 0 {
+		command.Env = append(os.Environ(), env...)
 	}
 	commandString := strings.Join(command.Args, " ")
 	say.Debug("Running command <" + commandString + "> passing output through")
@@ -1286,11 +2044,9 @@ func runCommand(name string, args ...string) (string, string, error) {
 	return commandString, output, nil
 }
 
-func startCommand(name string, args ...string) (string, error) {
+func startCommand(dir string, name string, args ...string) (string, error) {
 	command := exec.Command(name, args...)
-	if len(workingDir) > 0 {
-		command.Dir = workingDir
-	}
+	command.Dir = dir
 	commandString := strings.Join(command.Args, " ")
 	say.Debug("Starting command " + commandString)
 	err := command.Start()
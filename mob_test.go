@@ -1,6 +1,8 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	config "github.com/remotemobprogramming/mob/v5/configuration"
 	"github.com/remotemobprogramming/mob/v5/open"
@@ -10,9 +12,11 @@ import (
 	"reflect"
 	"runtime"
 	"runtime/debug"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 var (
@@ -125,63 +129,76 @@ func TestVersion(t *testing.T) {
 	assertOutputContains(t, output, versionNumber)
 }
 
+func TestVersionJson(t *testing.T) {
+	output, configuration := setup(t)
+
+	execute(gitRunner, "version", []string{}, withOutputJson(configuration))
+
+	equals(t, "{\n  \"version\": \""+versionNumber+"\"\n}\n", *output)
+}
+
 func TestHasCommits(t *testing.T) {
 	_, _ = setup(t)
 
-	commits := hasCommits()
+	commits, err := hasCommits(gitRunner)
 
+	assertNoError(t, err)
 	equals(t, true, commits)
 }
 
 func TestHasCommits_NoCommits(t *testing.T) {
 	tempDir = t.TempDir()
 	setWorkingDir(tempDir)
-	git("init")
+	git(gitRunner, "init")
 
-	commits := hasCommits()
+	commits, err := hasCommits(gitRunner)
 
+	assertNoError(t, err)
 	equals(t, false, commits)
 }
 
 func TestNextNotMobProgramming(t *testing.T) {
-	output, configuration := setup(t)
+	_, configuration := setup(t)
 
-	next(configuration)
+	err := next(gitRunner, configuration)
 
-	assertOutputContains(t, output, "to start working together")
+	assertErrorIs(t, err, ErrNotMobProgramming)
+	assertFix(t, err, "to start working together, use")
 }
 
 func TestRequireCommitMessage(t *testing.T) {
 	output, configuration := setup(t)
 	configuration.NextStay = true
 	configuration.RequireCommitMessage = true
-	start(configuration)
+	start(gitRunner, configuration)
 
-	next(configuration)
+	next(gitRunner, configuration)
 	// ensure we don't complain if there's nothing to commit
 	// https://github.com/remotemobprogramming/mob/pull/107#issuecomment-761298861
 	assertOutputContains(t, output, "nothing to commit")
 
 	createFile(t, "example.txt", "contentIrrelevant")
-	next(configuration)
+	err := next(gitRunner, configuration)
 	// failure message should make sense regardless of whether we
 	// provided commit message via `-m` or MOB_WIP_COMMIT_MESSAGE
 	// https://github.com/remotemobprogramming/mob/pull/107#issuecomment-761591039
-	assertOutputContains(t, output, "commit message required")
+	assertError(t, err, "commit message required")
+	assertErrorIs(t, err, ErrCommitMessageRequired)
 }
 
 func TestDoneNotMobProgramming(t *testing.T) {
-	output, configuration := setup(t)
+	_, configuration := setup(t)
 
-	done(configuration)
+	err := done(gitRunner, configuration)
 
-	assertOutputContains(t, output, "to start working together")
+	assertErrorIs(t, err, ErrNotMobProgramming)
+	assertFix(t, err, "to start working together, use")
 }
 
 func TestExecuteInvalidCommandKicksOffHelp(t *testing.T) {
 	output, _ := setup(t)
 
-	execute("whatever", []string{}, config.GetDefaultConfiguration())
+	execute(gitRunner, "whatever", []string{}, config.GetDefaultConfiguration())
 
 	assertOutputContains(t, output, "Basic Commands:")
 }
@@ -189,17 +206,17 @@ func TestExecuteInvalidCommandKicksOffHelp(t *testing.T) {
 func TestExecuteAnyCommandWithHelpArgumentShowsHelpOutput(t *testing.T) {
 	output, _ := setup(t)
 
-	execute("s", []string{"10", "--help"}, config.GetDefaultConfiguration())
+	execute(gitRunner, "s", []string{"10", "--help"}, config.GetDefaultConfiguration())
 	assertOutputContains(t, output, "Basic Commands:")
 
-	execute("next", []string{"help"}, config.GetDefaultConfiguration())
+	execute(gitRunner, "next", []string{"help"}, config.GetDefaultConfiguration())
 	assertOutputContains(t, output, "Basic Commands:")
 }
 
 func TestStart(t *testing.T) {
 	_, configuration := setup(t)
 
-	start(configuration)
+	start(gitRunner, configuration)
 
 	assertOnBranch(t, "mob-session")
 	assertMobSessionBranches(t, configuration, "mob-session")
@@ -207,9 +224,9 @@ func TestStart(t *testing.T) {
 
 func TestStartDespiteGitHook(t *testing.T) {
 	_, configuration := setup(t)
-	createExecutableFileInPath(t, workingDir+"/.git/hooks", "pre-commit", "#!/bin/sh\necho 'boo'\nexit 1\n")
+	createExecutableFileInPath(t, gitRunner.Dir()+"/.git/hooks", "pre-commit", "#!/bin/sh\necho 'boo'\nexit 1\n")
 
-	start(configuration)
+	start(gitRunner, configuration)
 
 	assertOnBranch(t, "mob-session")
 	assertMobSessionBranches(t, configuration, "mob-session")
@@ -218,21 +235,20 @@ func TestStartDespiteGitHook(t *testing.T) {
 func TestStartWithCISkip(t *testing.T) {
 	output, configuration := setup(t)
 	configuration.SkipCiPushOptionEnabled = true
-	mockExit()
 
-	start(configuration)
+	err := start(gitRunner, configuration)
 
 	assertOutputContains(t, output, "git push --push-option ci.skip --no-verify --set-upstream origin mob-session:mob-session")
-	assertOutputContains(t, output, "Disable the push option ci.skip in your .mob file or set the expected environment variable")
-	assertOutputContains(t, output, "export MOB_SKIP_CI_PUSH_OPTION_ENABLED=false")
-	resetExit()
+	assertError(t, err, "The receiving end does not support push options")
+	assertFix(t, err, "Disable the push option ci.skip in your .mob file or set the expected environment variable")
+	assertFix(t, err, "export MOB_SKIP_CI_PUSH_OPTION_ENABLED=false")
 }
 
 func TestStartWithOutCISkip(t *testing.T) {
 	output, configuration := setup(t)
 	configuration.SkipCiPushOptionEnabled = false
 
-	start(configuration)
+	start(gitRunner, configuration)
 
 	assertOnBranch(t, "mob-session")
 	assertMobSessionBranches(t, configuration, "mob-session")
@@ -245,13 +261,13 @@ func TestStartWithMultipleExistingBranches(t *testing.T) {
 	output, configuration := setup(t)
 
 	configuration.WipBranchQualifier = "green"
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob/master-green")
-	next(configuration)
+	next(gitRunner, configuration)
 	assertOnBranch(t, "master")
 
 	configuration.WipBranchQualifier = ""
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob-session")
 	assertOutputContains(t, output, "preexisting wip branches have been detected")
 	assertOutputContains(t, output, "mob/master-green")
@@ -261,11 +277,11 @@ func TestStartWithMultipleExistingBranchesAndEmptyWipBranchQualifier(t *testing.
 	output, configuration := setup(t)
 
 	configuration.WipBranchQualifier = "green"
-	start(configuration)
-	next(configuration)
+	start(gitRunner, configuration)
+	next(gitRunner, configuration)
 
 	configuration.WipBranchQualifier = ""
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob-session")
 	assertOutputNotContains(t, output, "qualified mob branches detected")
 }
@@ -276,13 +292,13 @@ func TestStartWithMultipleExistingBranchesWithStay(t *testing.T) {
 
 	configuration.WipBranchQualifier = "green"
 	assertOnBranch(t, "master")
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob/master-green")
-	next(configuration)
+	next(gitRunner, configuration)
 	assertOnBranch(t, "mob/master-green")
 
 	configuration.WipBranchQualifier = ""
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob/master-green")
 	assertOutputNotContains(t, output, "qualified mob branches detected")
 }
@@ -293,16 +309,16 @@ func TestStartNextWithBranch(t *testing.T) {
 	assertOnBranch(t, "master")
 	configuration.WipBranchQualifier = "green"
 
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob/master-green")
 	assertMobSessionBranches(t, configuration, "mob/master-green")
 	configuration.WipBranchQualifier = ""
 
-	next(configuration)
+	next(gitRunner, configuration)
 	assertOnBranch(t, "master")
 
 	configuration.WipBranchQualifier = "green"
-	reset(configuration)
+	reset(gitRunner, configuration)
 	assertNoMobSessionBranches(t, configuration, "mob/master-green")
 }
 
@@ -312,13 +328,13 @@ func TestStartNextStartWithBranch(t *testing.T) {
 	configuration.NextStay = true
 	assertOnBranch(t, "master")
 
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob/master-green")
 
-	next(configuration)
+	next(gitRunner, configuration)
 	assertOnBranch(t, "mob/master-green")
 
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob/master-green")
 }
 
@@ -328,12 +344,12 @@ func TestStartWarnsAboutPreexistingWipBranches(t *testing.T) {
 	checkoutAndPushBranch("feature-something-2")
 
 	assertOnBranch(t, "feature-something-2")
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob/feature-something-2")
-	next(configuration)
+	next(gitRunner, configuration)
 
-	git("checkout", "feature-something")
-	start(configuration)
+	git(gitRunner, "checkout", "feature-something")
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob/feature-something")
 	assertOutputContains(t, output, "preexisting wip branches have been detected")
 	assertOutputContains(t, output, "mob/feature-something-2")
@@ -342,15 +358,15 @@ func TestStartWarnsAboutPreexistingWipBranches(t *testing.T) {
 func TestStartWarnsOnDivergingWipBranch(t *testing.T) {
 	output, configuration := setup(t)
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFileAndCommitIt(t, "example.txt", "asdf", "asdf")
-	next(configuration)
+	next(gitRunner, configuration)
 
-	git("checkout", "master")
+	git(gitRunner, "checkout", "master")
 	createFileAndCommitIt(t, "example.txt", "other", "other")
-	git("push")
+	git(gitRunner, "push")
 
-	start(configuration)
+	start(gitRunner, configuration)
 
 	assertOutputContains(t, output, "Careful, your wip branch (mob-session) diverges from your main branch (origin/master) !")
 }
@@ -358,12 +374,12 @@ func TestStartWarnsOnDivergingWipBranch(t *testing.T) {
 func TestStartJoinDoesNotWarn(t *testing.T) {
 	output, configuration := setup(t)
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFileAndCommitIt(t, "example.txt", "asdf", "asdf")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/localother")
-	start(configuration)
+	start(gitRunner, configuration)
 
 	assertOutputNotContains(t, output, "Careful, your wip branch (mob-session) diverges from your main branch (origin/master) !")
 }
@@ -374,10 +390,10 @@ func TestStartNextOnFeatureWithBranch(t *testing.T) {
 	checkoutAndPushBranch("feature1")
 	assertOnBranch(t, "feature1")
 
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob/feature1-green")
 
-	next(configuration)
+	next(gitRunner, configuration)
 	assertOnBranch(t, "feature1")
 }
 
@@ -385,21 +401,21 @@ func TestStartNextOnFeatureWithBranch(t *testing.T) {
 func TestStartNextWithBranchContainingHyphen(t *testing.T) {
 	_, configuration := setup(t)
 	configuration.WipBranchQualifier = "test-branch"
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob/master-test-branch")
 	assertMobSessionBranches(t, configuration, "mob/master-test-branch")
 
 	configuration.WipBranchQualifier = ""
-	next(configuration)
+	next(gitRunner, configuration)
 }
 
 func TestStartWithPushDefaultTracking(t *testing.T) {
 	_, configuration := setup(t)
 	createFileAndCommitIt(t, "example.txt", "asdf", "asdf")
-	git("push", "origin", "master")
-	git("config", "push.default", "tracking")
+	git(gitRunner, "push", "origin", "master")
+	git(gitRunner, "config", "push.default", "tracking")
 
-	start(configuration)
+	start(gitRunner, configuration)
 	assertMobSessionBranches(t, configuration, "mob-session")
 }
 
@@ -407,14 +423,14 @@ func TestStartWithJoiningNonExistingSession(t *testing.T) {
 	_, configuration := setup(t)
 	assertOnBranch(t, "master")
 	configuration.StartJoin = true
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "master")
 }
 
 func TestReset(t *testing.T) {
 	output, configuration := setup(t)
 
-	reset(configuration)
+	reset(gitRunner, configuration)
 
 	assertOutputContains(t, output, "mob reset --delete-remote-wip-branch")
 }
@@ -423,7 +439,7 @@ func TestResetDeleteRemoteWipBranch(t *testing.T) {
 	_, configuration := setup(t)
 	configuration.ResetDeleteRemoteWipBranch = true
 
-	reset(configuration)
+	reset(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertNoMobSessionBranches(t, configuration, "mob-session")
@@ -431,12 +447,12 @@ func TestResetDeleteRemoteWipBranch(t *testing.T) {
 
 func TestResetCommit(t *testing.T) {
 	output, configuration := setup(t)
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "example.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 	assertMobSessionBranches(t, configuration, "mob-session")
 
-	reset(configuration)
+	reset(gitRunner, configuration)
 
 	assertOutputContains(t, output, "mob reset --delete-remote-wip-branch")
 	assertMobSessionBranches(t, configuration, "mob-session")
@@ -445,12 +461,12 @@ func TestResetCommit(t *testing.T) {
 func TestResetDeleteRemoteWipBranchCommit(t *testing.T) {
 	_, configuration := setup(t)
 	configuration.ResetDeleteRemoteWipBranch = true
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "example.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 	assertMobSessionBranches(t, configuration, "mob-session")
 
-	reset(configuration)
+	reset(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertNoMobSessionBranches(t, configuration, "mob-session")
@@ -459,12 +475,12 @@ func TestResetDeleteRemoteWipBranchCommit(t *testing.T) {
 func TestResetCommitBranch(t *testing.T) {
 	output, configuration := setup(t)
 	configuration.WipBranchQualifier = "green"
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "example.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 	assertMobSessionBranches(t, configuration, "mob/master-green")
 
-	reset(configuration)
+	reset(gitRunner, configuration)
 
 	assertOutputContains(t, output, "mob reset --delete-remote-wip-branch")
 	assertMobSessionBranches(t, configuration, "mob/master-green")
@@ -474,12 +490,12 @@ func TestResetDeleteRemoteWipBranchCommitBranch(t *testing.T) {
 	_, configuration := setup(t)
 	configuration.WipBranchQualifier = "green"
 	configuration.ResetDeleteRemoteWipBranch = true
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "example.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 	assertMobSessionBranches(t, configuration, "mob/master-green")
 
-	reset(configuration)
+	reset(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertNoMobSessionBranches(t, configuration, "mob/master-green")
@@ -487,9 +503,9 @@ func TestResetDeleteRemoteWipBranchCommitBranch(t *testing.T) {
 
 func TestClean(t *testing.T) {
 	_, configuration := setup(t)
-	git("checkout", "-b", "mob-session")
+	git(gitRunner, "checkout", "-b", "mob-session")
 
-	clean(configuration)
+	clean(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertNoLocalBranch(t, "mob-session")
@@ -497,9 +513,9 @@ func TestClean(t *testing.T) {
 
 func TestCleanAfterStart(t *testing.T) {
 	_, configuration := setup(t)
-	start(configuration)
+	start(gitRunner, configuration)
 
-	clean(configuration)
+	clean(gitRunner, configuration)
 
 	assertOnBranch(t, "mob-session")
 	assertMobSessionBranches(t, configuration, "mob-session")
@@ -507,15 +523,15 @@ func TestCleanAfterStart(t *testing.T) {
 
 func TestCleanNotFullyMergedMissingRemoteBranch(t *testing.T) {
 	_, configuration := setup(t)
-	start(configuration)
+	start(gitRunner, configuration)
 
 	createFile(t, "example.txt", "contentIrrelevant")
 
-	next(configuration)
+	next(gitRunner, configuration)
 
-	git("push", "origin", "mob-session", "--delete")
+	git(gitRunner, "push", "origin", "mob-session", "--delete")
 
-	clean(configuration)
+	clean(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertNoLocalBranch(t, "mob-session")
@@ -523,11 +539,11 @@ func TestCleanNotFullyMergedMissingRemoteBranch(t *testing.T) {
 
 func TestCleanFeatureOrphanWipBranch(t *testing.T) {
 	_, configuration := setup(t)
-	git("checkout", "-b", "feature1")
-	git("push", "origin", "feature1", "--set-upstream")
-	git("checkout", "-b", "mob/feature1")
+	git(gitRunner, "checkout", "-b", "feature1")
+	git(gitRunner, "push", "origin", "feature1", "--set-upstream")
+	git(gitRunner, "checkout", "-b", "mob/feature1")
 
-	clean(configuration)
+	clean(gitRunner, configuration)
 
 	assertOnBranch(t, "feature1")
 	assertNoLocalBranch(t, "mob/feature1")
@@ -535,25 +551,26 @@ func TestCleanFeatureOrphanWipBranch(t *testing.T) {
 
 func TestCleanMissingBaseBranch(t *testing.T) {
 	_, configuration := setup(t)
-	git("checkout", "-b", "mob/feature1")
+	git(gitRunner, "checkout", "-b", "mob/feature1")
 
-	clean(configuration)
+	clean(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertNoLocalBranch(t, "mob/feature1")
 }
 
 func TestStartUnstagedChanges(t *testing.T) {
-	output, configuration := setup(t)
+	_, configuration := setup(t)
 	configuration.HandleUncommittedChanges = config.FailWithError
 	createFile(t, "test.txt", "contentIrrelevant")
 
-	start(configuration)
+	err := start(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertNoMobSessionBranches(t, configuration, "mob-session")
-	assertOutputContains(t, output, "mob start --include-uncommitted-changes")
-	assertOutputContains(t, output, "mob start --discard-uncommitted-changes")
+	assertErrorIs(t, err, ErrDirtyWorkingTree)
+	assertFix(t, err, "mob start --include-uncommitted-changes")
+	assertFix(t, err, "mob start --discard-uncommitted-changes")
 }
 
 func TestStartIncludeUnstagedChanges(t *testing.T) {
@@ -561,7 +578,7 @@ func TestStartIncludeUnstagedChanges(t *testing.T) {
 	configuration.HandleUncommittedChanges = config.IncludeChanges
 	createFile(t, "test.txt", "contentIrrelevant")
 
-	start(configuration)
+	start(gitRunner, configuration)
 
 	assertOnBranch(t, "mob-session")
 	assertMobSessionBranches(t, configuration, "mob-session")
@@ -572,7 +589,7 @@ func TestStartDiscardUnstagedChanges(t *testing.T) {
 	configuration.HandleUncommittedChanges = config.DiscardChanges
 	createFile(t, "test.txt", "contentIrrelevant")
 
-	start(configuration)
+	start(gitRunner, configuration)
 
 	assertOnBranch(t, "mob-session")
 	assertMobSessionBranches(t, configuration, "mob-session")
@@ -580,43 +597,55 @@ func TestStartDiscardUnstagedChanges(t *testing.T) {
 }
 
 func TestStartIncludeUnstagedChangesInNewWorkingDirectory(t *testing.T) {
-	output, configuration := setup(t)
+	_, configuration := setup(t)
 	configuration.HandleUncommittedChanges = config.IncludeChanges
 	createDirectory(t, "subdirnew")
 	setWorkingDir(tempDir + "/local/subdirnew")
 	createFile(t, "test.txt", "contentIrrelevant")
 	assertFileExist(t, tempDir+"/local/subdirnew/test.txt")
 
-	start(configuration)
+	err := start(gitRunner, configuration)
 
-	assertOutputContains(t, output, "cannot start; current working dir is an uncommitted subdir")
+	assertError(t, err, "cannot start; current working dir is an uncommitted subdir")
 }
 
 func TestStartHasUnpushedCommits(t *testing.T) {
 	output, configuration := setup(t)
 	createFileAndCommitIt(t, "test.txt", "contentIrrelevant", "unpushed change")
 
-	start(configuration)
+	err := start(gitRunner, configuration)
 
-	assertOutputContains(t, output, "cannot start; unpushed changes")
+	assertError(t, err, "cannot start; unpushed changes on base branch must be pushed upstream")
 	assertOutputContains(t, output, "unpushed commits")
 }
 
 func TestBranch(t *testing.T) {
 	output, configuration := setup(t)
-	start(configuration)
+	start(gitRunner, configuration)
 
-	branch(configuration)
+	branch(gitRunner, configuration)
 
 	assertOutputContains(t, output, "\norigin/mob-session\n")
 }
 
+func TestBranchJson(t *testing.T) {
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+	*output = ""
+
+	execute(gitRunner, "branch", []string{}, withOutputJson(configuration))
+
+	var branches map[string][]string
+	assertNoError(t, json.Unmarshal([]byte(*output), &branches))
+	equals(t, []string{"origin/mob-session"}, branches["wipBranches"])
+}
+
 func TestStartIncludeUntrackedFiles(t *testing.T) {
 	_, configuration := setup(t)
 	configuration.HandleUncommittedChanges = config.IncludeChanges
 	createFile(t, "example.txt", "contentIrrelevant")
 
-	start(configuration)
+	start(gitRunner, configuration)
 
 	assertOnBranch(t, "mob-session")
 }
@@ -626,40 +655,41 @@ func TestStartUntrackedFiles(t *testing.T) {
 	configuration.HandleUncommittedChanges = config.FailWithError
 	createFile(t, "example.txt", "contentIrrelevant")
 
-	start(configuration)
+	start(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 }
 
 func TestStartOnUnpushedFeatureBranch(t *testing.T) {
-	output, configuration := setup(t)
-	git("checkout", "-b", "feature1")
+	_, configuration := setup(t)
+	git(gitRunner, "checkout", "-b", "feature1")
 
-	start(configuration)
+	err := start(gitRunner, configuration)
 
 	assertOnBranch(t, "feature1")
-	assertOutputContains(t, output, "Remote branch origin/feature1 is missing")
-	assertOutputContains(t, output, "mob start --create")
+	assertError(t, err, "Remote branch origin/feature1 is missing")
+	assertErrorIs(t, err, ErrRemoteBranchMissing)
+	assertFix(t, err, "mob start --create")
 }
 
 func TestStartOnUnpushedFeatureBranchWithUncommitedChanges(t *testing.T) {
-	output, configuration := setup(t)
-	git("checkout", "-b", "feature1")
+	_, configuration := setup(t)
+	git(gitRunner, "checkout", "-b", "feature1")
 	createFile(t, "file.txt", "contentIrrelevant")
 
-	start(configuration)
+	err := start(gitRunner, configuration)
 
 	assertOnBranch(t, "feature1")
-	assertOutputContains(t, output, "mob start --include-uncommitted-changes")
-	assertOutputContains(t, output, "mob start --discard-uncommitted-changes")
+	assertFix(t, err, "mob start --include-uncommitted-changes")
+	assertFix(t, err, "mob start --discard-uncommitted-changes")
 }
 
 func TestStartCreateOnUnpushedFeatureBranch(t *testing.T) {
 	output, configuration := setup(t)
-	git("checkout", "-b", "feature1")
+	git(gitRunner, "checkout", "-b", "feature1")
 
 	configuration.StartCreate = true
-	start(configuration)
+	start(gitRunner, configuration)
 
 	assertOutputNotContains(t, output, "Remote branch origin/feature1 already exists")
 	assertOnBranch(t, "mob/feature1")
@@ -667,50 +697,50 @@ func TestStartCreateOnUnpushedFeatureBranch(t *testing.T) {
 
 func TestStartCreateOnUnpushedFeatureBranchWithBranchPostfix(t *testing.T) {
 	output, configuration := setup(t)
-	git("checkout", "-b", "feature1")
+	git(gitRunner, "checkout", "-b", "feature1")
 
 	configuration.StartCreate = true
 	configuration.WipBranchQualifier = "green"
-	start(configuration)
+	start(gitRunner, configuration)
 
 	assertOutputNotContains(t, output, "Remote branch origin/feature1 already exists")
 	assertOnBranch(t, "mob/feature1-green")
 }
 
 func TestStartCreateOnUnpushedFeatureBranchWithUncommitedChanges(t *testing.T) {
-	output, configuration := setup(t)
-	git("checkout", "-b", "feature1")
+	_, configuration := setup(t)
+	git(gitRunner, "checkout", "-b", "feature1")
 	createFile(t, "file.txt", "contentIrrelevant")
 
 	configuration.StartCreate = true
-	start(configuration)
+	err := start(gitRunner, configuration)
 
-	assertOutputContains(t, output, "To start, including uncommitted changes and create the remote branch, use")
-	assertOutputContains(t, output, "mob start --create --include-uncommitted-changes")
-	assertOutputContains(t, output, "mob start --create --discard-uncommitted-changes")
+	assertFix(t, err, "To start, including uncommitted changes and create the remote branch, use")
+	assertFix(t, err, "mob start --create --include-uncommitted-changes")
+	assertFix(t, err, "mob start --create --discard-uncommitted-changes")
 }
 
 func TestStartCreateIncludeUncommitedChangesOnUnpushedFeatureBranchWithUncommitedChanges(t *testing.T) {
 	_, configuration := setup(t)
-	git("checkout", "-b", "feature1")
+	git(gitRunner, "checkout", "-b", "feature1")
 	createFile(t, "file.txt", "contentIrrelevant")
 
 	configuration.StartCreate = true
 	configuration.HandleUncommittedChanges = config.IncludeChanges
-	start(configuration)
+	start(gitRunner, configuration)
 
 	assertOnBranch(t, "mob/feature1")
 }
 
 func TestStartCreateIncludeUncommitedChangesOnUnpushedFeatureBranchWithUncommitedChangesAndBranchPostfix(t *testing.T) {
 	_, configuration := setup(t)
-	git("checkout", "-b", "feature1")
+	git(gitRunner, "checkout", "-b", "feature1")
 	createFile(t, "file.txt", "contentIrrelevant")
 
 	configuration.StartCreate = true
 	configuration.HandleUncommittedChanges = config.IncludeChanges
 	configuration.WipBranchQualifier = "green"
-	start(configuration)
+	start(gitRunner, configuration)
 
 	assertOnBranch(t, "mob/feature1-green")
 }
@@ -720,7 +750,7 @@ func TestStartCreateOnPushedFeatureBranch(t *testing.T) {
 	checkoutAndPushBranch("feature1")
 
 	configuration.StartCreate = true
-	start(configuration)
+	start(gitRunner, configuration)
 
 	assertOutputContains(t, output, "Remote branch origin/feature1 already exists")
 	assertOnBranch(t, "mob/feature1")
@@ -732,7 +762,7 @@ func TestStartCreateOnPushedFeatureBranchWithBranchPostfix(t *testing.T) {
 
 	configuration.StartCreate = true
 	configuration.WipBranchQualifier = "green"
-	start(configuration)
+	start(gitRunner, configuration)
 
 	assertOutputContains(t, output, "Remote branch origin/feature1 already exists")
 	assertOnBranch(t, "mob/feature1-green")
@@ -742,37 +772,37 @@ func TestStartCreateOnPushedFeatureBranchWhichIsAhead(t *testing.T) {
 	_, configuration := setup(t)
 	checkoutAndPushBranch("feature1")
 	createFile(t, "file.txt", "contentIrrelevant")
-	git("add", ".")
-	git("commit", "-m", "commit ahead")
-	git("push")
-	git("reset", "--hard", "HEAD~1")
+	git(gitRunner, "add", ".")
+	git(gitRunner, "commit", "-m", "commit ahead")
+	git(gitRunner, "push")
+	git(gitRunner, "reset", "--hard", "HEAD~1")
 
 	configuration.StartCreate = true
-	start(configuration)
+	start(gitRunner, configuration)
 
 	assertOnBranch(t, "mob/feature1")
 	assertCommitLogContainsMessage(t, "mob/feature1", "commit ahead")
 }
 
 func TestStartCreateOnPushedFeatureBranchWhichIsBehind(t *testing.T) {
-	output, configuration := setup(t)
+	_, configuration := setup(t)
 	checkoutAndPushBranch("feature1")
 	createFile(t, "file.txt", "contentIrrelevant")
-	git("add", ".")
-	git("commit", "-m", "commit ahead")
+	git(gitRunner, "add", ".")
+	git(gitRunner, "commit", "-m", "commit ahead")
 
 	configuration.StartCreate = true
-	start(configuration)
+	err := start(gitRunner, configuration)
 
 	assertOnBranch(t, "feature1")
-	assertOutputContains(t, output, "ERROR cannot start; unpushed changes on base branch must be pushed upstream")
-	assertOutputContains(t, output, "git push origin feature1")
+	assertError(t, err, "cannot start; unpushed changes on base branch must be pushed upstream")
+	assertFix(t, err, "git push origin feature1")
 }
 
 func TestStartPushOnWIPBranchWithOptions(t *testing.T) {
 	output, configuration := setup(t)
 
-	start(configuration)
+	start(gitRunner, configuration)
 
 	assertOutputContains(t, output, "git push --no-verify --set-upstream origin mob-session")
 }
@@ -780,7 +810,7 @@ func TestStartPushOnWIPBranchWithOptions(t *testing.T) {
 func TestStartPushOnWIPBranchWithOptionsShouldFailAndRetry(t *testing.T) {
 	output, configuration := setup(t)
 
-	start(configuration)
+	start(gitRunner, configuration)
 
 	assertOutputContains(t, output, "git push --no-verify --set-upstream origin mob-session")
 	assertOutputContains(t, output, "you are on wip branch 'mob-session' (base branch 'master')")
@@ -788,11 +818,11 @@ func TestStartPushOnWIPBranchWithOptionsShouldFailAndRetry(t *testing.T) {
 
 func TestStartNextBackToMaster(t *testing.T) {
 	_, configuration := setup(t)
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "example.txt", "contentIrrelevant")
 	assertOnBranch(t, "mob-session")
 
-	next(configuration)
+	next(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertMobSessionBranches(t, configuration, "mob-session")
@@ -801,13 +831,13 @@ func TestStartNextBackToMaster(t *testing.T) {
 func TestStartNextStay(t *testing.T) {
 	_, configuration := setup(t)
 	configuration.NextStay = true
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file1.txt", "contentIrrelevant")
 	assertOnBranch(t, "mob-session")
 
-	next(configuration)
+	next(gitRunner, configuration)
 
-	equals(t, silentgit("log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastFile:file1.txt")
+	equals(t, gitOutput(t, "log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastLine:1\nlastFile:file1.txt")
 	assertOnBranch(t, "mob-session")
 }
 
@@ -815,82 +845,152 @@ func TestStartNextStay_WriteLastModifiedFileInCommit_WhenFileIsAdded(t *testing.
 	_, configuration := setup(t)
 	configuration.NextStay = true
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "olderFile.txt", "contentIrrelevant")
 	createFile(t, "newerFile.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 
-	equals(t, silentgit("log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastFile:newerFile.txt")
+	equals(t, gitOutput(t, "log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastLine:1\nlastFile:newerFile.txt")
 }
 
 func TestStartNextStay_WriteLastModifiedFileInCommit_WhenFileIsModified(t *testing.T) {
 	_, configuration := setup(t)
 	configuration.NextStay = true
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file1.txt", "contentIrrelevant")
 	createFile(t, "file2.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file1.txt", "contentIrrelevantButModified")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	assertOnBranch(t, "mob-session")
-	equals(t, silentgit("log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastFile:file1.txt")
+	equals(t, gitOutput(t, "log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastLine:1\nlastFile:file1.txt")
 }
 
 func TestStartNextStay_WriteLastModifiedFileInCommit_WhenFileIsModifiedAndWorkingDirIsNotProjectRoot(t *testing.T) {
 	_, configuration := setup(t)
 	configuration.NextStay = true
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file1.txt", "contentIrrelevant")
 	createFile(t, "file2.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createDirectory(t, "dir")
 	createFile(t, "file1.txt", "contentIrrelevantButModified")
-	setWorkingDir(workingDir + "/dir")
-	next(configuration)
+	setWorkingDir(gitRunner.Dir() + "/dir")
+	next(gitRunner, configuration)
 
 	assertOnBranch(t, "mob-session")
-	equals(t, silentgit("log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastFile:file1.txt")
+	equals(t, gitOutput(t, "log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastLine:1\nlastFile:file1.txt")
+}
+
+func TestStartNextStay_WriteFirstChangedLineInCommit(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.NextStay = true
+
+	start(gitRunner, configuration)
+	createFile(t, "file1.txt", "one\ntwo\nthree\nfour\n")
+	next(gitRunner, configuration)
+
+	start(gitRunner, configuration)
+	createFile(t, "file1.txt", "one\ntwo\nthree changed\nfour\n")
+	next(gitRunner, configuration)
+
+	equals(t, gitOutput(t, "log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastLine:3\nlastFile:file1.txt")
+	lastCommitMessage, err := lastCommitMessage(gitRunner)
+	assertNoError(t, err)
+	equals(t, 3, lastLineFromCommitMessage(lastCommitMessage))
+}
+
+func TestGetModifiedFiles(t *testing.T) {
+	setup(t)
+	createFileAndCommitIt(t, "renamed.txt", "contentIrrelevant", "add file to rename")
+	createFileAndCommitIt(t, "deleted.txt", "contentIrrelevant", "add file to delete")
+	git(gitRunner, "mv", "renamed.txt", "new name.txt")
+	removeFile(t, filepath.Join(gitRunner.Dir(), "deleted.txt"))
+	createFile(t, "test.txt", "modified, but not staged")
+	createDirectory(t, "dir")
+	createFile(t, "dir/untracked \"quoted\".txt", "contentIrrelevant")
+
+	files := getModifiedFiles(gitRunner, gitRunner.Dir())
+
+	sort.Strings(files)
+	equals(t, []string{"dir/untracked \"quoted\".txt", "new name.txt", "test.txt"}, files)
+}
+
+func TestGetPathOfLastModifiedFileIncludesUnstagedChanges(t *testing.T) {
+	setup(t)
+	createFile(t, "older.txt", "contentIrrelevant")
+	createFile(t, "test.txt", "modified, but not staged")
+	os.Chtimes(filepath.Join(gitRunner.Dir(), "older.txt"), time.Now().Add(-time.Hour), time.Now().Add(-time.Hour))
+
+	equals(t, "test.txt", getPathOfLastModifiedFile(gitRunner, gitRunner.Dir()))
+}
+
+func TestWriteQuotedLastModifiedFileInCommit(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.NextStay = true
+	start(gitRunner, configuration)
+	createFile(t, "\"quoted\".txt", "contentIrrelevant")
+
+	next(gitRunner, configuration)
+
+	equals(t, gitOutput(t, "log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastLine:1\nlastFile:\"\\\"quoted\\\".txt\"")
+}
+
+func TestOpenCommandForWithLineAndColumn(t *testing.T) {
+	configuration := config.GetDefaultConfiguration()
+
+	configuration.OpenCommand = "code -g %s:%l:%c"
+	command, args, err := openCommandFor(gitRunner, configuration, "/path with spaces/file.txt", 12, 0)
+	assertNoError(t, err)
+	equals(t, "code", command)
+	equals(t, []string{"-g", "/path with spaces/file.txt:12:1"}, args)
+
+	configuration.OpenCommand = "idea --line %l %s"
+	command, args, err = openCommandFor(gitRunner, configuration, "/path/file.txt", 0, 0)
+	assertNoError(t, err)
+	equals(t, "idea", command)
+	equals(t, []string{"--line", "1", "/path/file.txt"}, args)
 }
 
 func TestStartNextStay_DoNotWriteLastModifiedFileInCommit_WhenFileIsDeleted(t *testing.T) {
 	_, configuration := setup(t)
 	configuration.NextStay = true
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file1.txt", "contentIrrelevant")
 	createFile(t, "file2.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 
-	start(configuration)
-	removeFile(t, filepath.Join(workingDir, "file1.txt"))
-	next(configuration)
+	start(gitRunner, configuration)
+	removeFile(t, filepath.Join(gitRunner.Dir(), "file1.txt"))
+	next(gitRunner, configuration)
 
 	assertOnBranch(t, "mob-session")
-	equals(t, silentgit("log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage)
+	equals(t, gitOutput(t, "log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage)
 }
 
-func TestStartNextStay_DoNotWriteLastModifiedFileInCommit_WhenFileIsMoved(t *testing.T) {
+func TestStartNextStay_WriteLastModifiedFileInCommit_WhenFileIsMoved(t *testing.T) {
 	_, configuration := setup(t)
 	configuration.NextStay = true
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file1.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createDirectory(t, "dir")
-	moveFile(t, filepath.Join(workingDir, "file1.txt"), filepath.Join(workingDir, "dir", "file1.txt"))
-	next(configuration)
+	moveFile(t, filepath.Join(gitRunner.Dir(), "file1.txt"), filepath.Join(gitRunner.Dir(), "dir", "file1.txt"))
+	next(gitRunner, configuration)
 
 	assertOnBranch(t, "mob-session")
-	equals(t, silentgit("log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage)
+	equals(t, gitOutput(t, "log", "--format=%B", "-n", "1", "HEAD"), configuration.WipCommitMessage+"\n\nlastLine:1\nlastFile:dir/file1.txt")
 }
 
 func TestStartNextStay_OpenLastModifiedFile(t *testing.T) {
@@ -902,12 +1002,12 @@ func TestStartNextStay_OpenLastModifiedFile(t *testing.T) {
 		configuration.OpenCommand = "touch %s-1"
 	}
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file.txt", "contentIrrelevant")
 	assertOnBranch(t, "mob-session")
-	next(configuration)
+	next(gitRunner, configuration)
 
-	start(configuration)
+	start(gitRunner, configuration)
 
 	assertGitStatus(t, GitStatus{
 		"file.txt-1": "??",
@@ -923,12 +1023,12 @@ func TestStartNextStay_OpenLastModifiedFile_WhenLastModifiedFilePathContainsSpac
 		configuration.OpenCommand = "touch %s-1"
 	}
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file with spaces.txt", "contentIrrelevant")
 	assertOnBranch(t, "mob-session")
-	next(configuration)
+	next(gitRunner, configuration)
 
-	start(configuration)
+	start(gitRunner, configuration)
 
 	assertGitStatus(t, GitStatus{
 		"file with spaces.txt-1": "??",
@@ -939,7 +1039,7 @@ func TestRunOutput(t *testing.T) {
 	_, configuration := setup(t)
 
 	setWorkingDir(tempDir + "/local")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file1.txt", "asdf")
 	output := readFile(t, filepath.Join(tempDir, "local", "file1.txt"))
 	assertOutputContains(t, &output, "asdf")
@@ -949,34 +1049,34 @@ func TestTestbed(t *testing.T) {
 	_, configuration := setup(t)
 
 	setWorkingDir(tempDir + "/local")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file1.txt", "asdf")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/localother")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file2.txt", "asdf")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/alice")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file3.txt", "owqe")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/bob")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file4.txt", "zcvx")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/local-symlink")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file5.txt", "uiop")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/local")
-	start(configuration)
+	start(gitRunner, configuration)
 
-	output := silentgit("log", "--pretty=format:'%ae'")
+	output := gitOutput(t, "log", "--pretty=format:'%ae'")
 	assertOutputContains(t, &output, "local")
 	assertOutputContains(t, &output, "localother")
 	assertOutputContains(t, &output, "alice")
@@ -988,10 +1088,10 @@ func TestStartDoneWithMobDoneSquash(t *testing.T) {
 	_, configuration := setup(t)
 	configuration.DoneSquash = config.Squash
 
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob-session")
 
-	done(configuration)
+	done(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertNoMobSessionBranches(t, configuration, "mob-session")
@@ -1001,22 +1101,22 @@ func TestStartDoneWithMobDoneSquashWithOldEmptyMobStartCommit(t *testing.T) {
 	_, configuration := setup(t)
 	configuration.DoneSquash = config.Squash
 
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob-session")
 	assertCommitsOnBranch(t, 1, "mob-session")
 
-	git("commit", gitHooksOption(configuration), "--allow-empty", "-m", configuration.StartCommitMessage)
-	git("push")
+	git(gitRunner, "commit", gitHooksOption(configuration), "--allow-empty", "-m", configuration.StartCommitMessage)
+	git(gitRunner, "push")
 	assertCommitsOnBranch(t, 2, "mob-session")
 
 	createFile(t, "test1.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 	assertCommitsOnBranch(t, 3, "mob-session")
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "test2.txt", "contentIrrelevant")
 
-	done(configuration)
+	done(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertGitStatus(t, GitStatus{
@@ -1031,22 +1131,22 @@ func TestStartDoneWithMobDoneNoSquashWithOldEmptyMobStartCommit(t *testing.T) {
 	_, configuration := setup(t)
 	configuration.DoneSquash = config.NoSquash
 
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob-session")
 	assertCommitsOnBranch(t, 1, "mob-session")
 
-	git("commit", gitHooksOption(configuration), "--allow-empty", "-m", configuration.StartCommitMessage)
-	git("push")
+	git(gitRunner, "commit", gitHooksOption(configuration), "--allow-empty", "-m", configuration.StartCommitMessage)
+	git(gitRunner, "push")
 	assertCommitsOnBranch(t, 2, "mob-session")
 
 	createFile(t, "test1.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 	assertCommitsOnBranch(t, 3, "mob-session")
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "test2.txt", "contentIrrelevant")
 
-	done(configuration)
+	done(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertGitStatus(t, GitStatus{
@@ -1060,26 +1160,26 @@ func TestStartDoneWithMobDoneSquashWipWithOldEmptyMobStartCommit(t *testing.T) {
 	_, configuration := setup(t)
 	configuration.DoneSquash = config.SquashWip
 
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob-session")
 	assertCommitsOnBranch(t, 1, "mob-session")
 
-	git("commit", gitHooksOption(configuration), "--allow-empty", "-m", configuration.StartCommitMessage)
-	git("push")
+	git(gitRunner, "commit", gitHooksOption(configuration), "--allow-empty", "-m", configuration.StartCommitMessage)
+	git(gitRunner, "push")
 	assertCommitsOnBranch(t, 2, "mob-session")
 
 	manualCommit(t, configuration, "test1.txt", "test1")
 	assertCommitsOnBranch(t, 3, "mob-session")
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "test2.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 	assertCommitsOnBranch(t, 4, "mob-session")
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "test3.txt", "contentIrrelevant")
 
-	done(configuration)
+	done(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertGitStatus(t, GitStatus{
@@ -1093,10 +1193,10 @@ func TestStartDoneWithMobDoneSquashWipWithOldEmptyMobStartCommit(t *testing.T) {
 func TestStartDoneWithMobDoneBugMergeTwice(t *testing.T) {
 	output, configuration := setup(t)
 
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob-session")
 
-	done(configuration)
+	done(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertNoMobSessionBranches(t, configuration, "mob-session")
@@ -1111,16 +1211,16 @@ func TestStartDoneSquashWithUnpushedCommit(t *testing.T) {
 	createFileAndCommitIt(t, "file1.txt", "owqe", "not a mob session yet")
 
 	setWorkingDir(tempDir + "/alice")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file2.txt", "zcvx")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/local")
-	git("push")
+	git(gitRunner, "push")
 
 	setWorkingDir(tempDir + "/alice")
-	start(configuration)
-	done(configuration)
+	start(gitRunner, configuration)
+	done(gitRunner, configuration)
 
 	assertFileExist(t, "file1.txt")
 }
@@ -1133,16 +1233,16 @@ func TestStartDoneSquashWipWithUnpushedCommit(t *testing.T) {
 	createFileAndCommitIt(t, "file1.txt", "owqe", "not a mob session yet")
 
 	setWorkingDir(tempDir + "/alice")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file2.txt", "zcvx")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/local")
-	git("push")
+	git(gitRunner, "push")
 
 	setWorkingDir(tempDir + "/alice")
-	start(configuration)
-	done(configuration)
+	start(gitRunner, configuration)
+	done(gitRunner, configuration)
 
 	assertFileExist(t, "file1.txt")
 }
@@ -1151,10 +1251,10 @@ func TestStartDoneWithMobDoneNoSquash(t *testing.T) {
 	_, configuration := setup(t)
 	configuration.DoneSquash = config.NoSquash
 
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob-session")
 
-	done(configuration)
+	done(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertNoMobSessionBranches(t, configuration, "mob-session")
@@ -1164,12 +1264,12 @@ func TestStartDonePublishingOneManualCommit(t *testing.T) {
 	_, configuration := setup(t)
 	configuration.DoneSquash = config.NoSquash
 
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob-session")
 	createFileAndCommitIt(t, "example.txt", "contentIrrelevant", "[manual-commit-1] publish this commit to master")
 	assertCommits(t, 2)
 
-	done(configuration) // without squash (configuration)
+	done(gitRunner, configuration) // without squash (configuration)
 
 	assertOnBranch(t, "master")
 	assertCleanGitStatus(t)
@@ -1183,12 +1283,12 @@ func TestStartDoneSquashTheOneManualCommit(t *testing.T) {
 	_, configuration := setup(t)
 	configuration.DoneSquash = config.Squash
 
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob-session")
 	createFileAndCommitIt(t, "example.txt", "contentIrrelevant", "[manual-commit-1] publish this commit to master")
 	assertCommits(t, 2)
 
-	done(configuration)
+	done(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertGitStatus(t, GitStatus{
@@ -1202,10 +1302,10 @@ func TestStartDoneSquashTheOneManualCommit(t *testing.T) {
 func TestStartDoneWithUncommittedChanges(t *testing.T) {
 	_, configuration := setup(t)
 
-	start(configuration) // should be 1 commit on mob-session so far
+	start(gitRunner, configuration) // should be 1 commit on mob-session so far
 	createFile(t, "example.txt", "contentIrrelevant")
 
-	done(configuration)
+	done(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertGitStatus(t, GitStatus{
@@ -1220,10 +1320,10 @@ func TestStartDoneNoSquashWithUncommittedChanges(t *testing.T) {
 	_, configuration := setup(t)
 	configuration.DoneSquash = config.NoSquash
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "example.txt", "content")
 
-	done(configuration) // without squash (configuration)
+	done(gitRunner, configuration) // without squash (configuration)
 
 	assertOnBranch(t, "master")
 	assertGitStatus(t, GitStatus{
@@ -1238,14 +1338,14 @@ func TestStartDoneSquashWipPublishingOneManualCommit(t *testing.T) {
 	_, configuration := setup(t)
 	configuration.DoneSquash = config.SquashWip
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "some.txt", "contentIrrelevant")
-	next(configuration) // this wip commit will be squashed
+	next(gitRunner, configuration) // this wip commit will be squashed
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFileAndCommitIt(t, "example.txt", "contentIrrelevant", "[manual-commit-1] publish this commit to master")
 
-	done(configuration)
+	done(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertCleanGitStatus(t)
@@ -1258,11 +1358,11 @@ func TestStartDoneSquashWipPublishingOneManualCommit(t *testing.T) {
 func TestStartDoneSquashWipWithUncommittedChanges(t *testing.T) {
 	_, configuration := setup(t)
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "example.txt", "contentIrrelevant")
 
 	configuration.DoneSquash = config.SquashWip
-	done(configuration)
+	done(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertGitStatus(t, GitStatus{
@@ -1277,11 +1377,11 @@ func TestStartDoneSquashWipPublishingOneManualCommitHasUncommittedModifications(
 	_, configuration := setup(t)
 	configuration.DoneSquash = config.SquashWip
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFileAndCommitIt(t, "example.txt", "contentIrrelevant", "[manual-commit-1] publish this commit to master")
 
 	createFile(t, "example.txt", "contentIrrelevant2") // modify previously committed file
-	done(configuration)
+	done(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertGitStatus(t, GitStatus{
@@ -1296,17 +1396,17 @@ func TestStartDoneSquashWipPublishingOneManualCommitHasUncommittedModifications(
 func TestStartDoneSquashWipOneWipCommitAfterManualCommit(t *testing.T) {
 	_, configuration := setup(t)
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFileAndCommitIt(t, "example.txt", "contentIrrelevant", "[manual-commit-1] publish this commit to master")
-	next(configuration)
+	next(gitRunner, configuration)
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file.txt", "contentIrrelevant") // the user should see these changes staged after done
-	next(configuration)
+	next(gitRunner, configuration)
 
-	start(configuration)
+	start(gitRunner, configuration)
 	configuration.DoneSquash = config.SquashWip
-	done(configuration)
+	done(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertGitStatus(t, GitStatus{
@@ -1320,21 +1420,21 @@ func TestStartDoneSquashWipOneWipCommitAfterManualCommit(t *testing.T) {
 func TestStartDoneSquashWipManyWipCommitsAfterManualCommit(t *testing.T) {
 	_, configuration := setup(t)
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFileAndCommitIt(t, "example.txt", "contentIrrelevant", "[manual-commit-1] publish this commit to master")
-	next(configuration)
+	next(gitRunner, configuration)
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file1.txt", "contentIrrelevant") // the user should see these changes staged after done
-	next(configuration)
+	next(gitRunner, configuration)
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file2.txt", "contentIrrelevant") // the user should see these changes staged after done
-	next(configuration)
+	next(gitRunner, configuration)
 
-	start(configuration)
+	start(gitRunner, configuration)
 	configuration.DoneSquash = config.SquashWip
-	done(configuration)
+	done(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertGitStatus(t, GitStatus{
@@ -1349,17 +1449,17 @@ func TestStartDoneSquashWipManyWipCommitsAfterManualCommit(t *testing.T) {
 func TestStartDoneSquashWipOnlyWipCommits(t *testing.T) {
 	_, configuration := setup(t)
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file1.txt", "contentIrrelevant") // the user should see these changes staged after done
-	next(configuration)
+	next(gitRunner, configuration)
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file2.txt", "contentIrrelevant") // the user should see these changes staged after done
-	next(configuration)
+	next(gitRunner, configuration)
 
-	start(configuration)
+	start(gitRunner, configuration)
 	configuration.DoneSquash = config.SquashWip
-	done(configuration)
+	done(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertGitStatus(t, GitStatus{
@@ -1373,17 +1473,17 @@ func TestStartDoneSquashWipOnlyWipCommits(t *testing.T) {
 func TestStartDoneSquashWipOnlyManualCommits(t *testing.T) {
 	_, configuration := setup(t)
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFileAndCommitIt(t, "example.txt", "contentIrrelevant", "[manual-commit-1] publish this commit to master")
-	next(configuration)
+	next(gitRunner, configuration)
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFileAndCommitIt(t, "example2.txt", "contentIrrelevant", "[manual-commit-2] publish this commit to master")
-	next(configuration)
+	next(gitRunner, configuration)
 
-	start(configuration)
+	start(gitRunner, configuration)
 	configuration.DoneSquash = config.SquashWip
-	done(configuration)
+	done(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertCleanGitStatus(t)
@@ -1397,20 +1497,20 @@ func TestDoneSquashWipWithoutStartDoesNotLooseChanges(t *testing.T) {
 	_, configuration := setup(t)
 
 	setWorkingDir(tempDir + "/local")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFileAndCommitIt(t, "file1.txt", "owqe", "not a mob session yet")
 	configuration.NextStay = true
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/alice")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file2.txt", "zcvx")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/local")
 	assertOnBranch(t, "mob-session")
 	configuration.DoneSquash = config.SquashWip
-	done(configuration)
+	done(gitRunner, configuration)
 
 	assertOnBranch(t, "master")
 	assertFileExist(t, "file2.txt")
@@ -1418,13 +1518,13 @@ func TestDoneSquashWipWithoutStartDoesNotLooseChanges(t *testing.T) {
 
 func TestStartDoneFeatureBranch(t *testing.T) {
 	_, configuration := setup(t)
-	git("checkout", "-b", "feature1")
-	git("push", "origin", "feature1", "--set-upstream")
+	git(gitRunner, "checkout", "-b", "feature1")
+	git(gitRunner, "push", "origin", "feature1", "--set-upstream")
 	assertOnBranch(t, "feature1")
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob/feature1")
 
-	done(configuration)
+	done(gitRunner, configuration)
 
 	assertOnBranch(t, "feature1")
 	assertNoMobSessionBranches(t, configuration, "mob-session")
@@ -1432,13 +1532,13 @@ func TestStartDoneFeatureBranch(t *testing.T) {
 
 func TestStartNextFeatureBranch(t *testing.T) {
 	_, configuration := setup(t)
-	git("checkout", "-b", "feature1")
-	git("push", "origin", "feature1", "--set-upstream")
+	git(gitRunner, "checkout", "-b", "feature1")
+	git(gitRunner, "push", "origin", "feature1", "--set-upstream")
 	assertOnBranch(t, "feature1")
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob/feature1")
 
-	next(configuration)
+	next(gitRunner, configuration)
 
 	assertOnBranch(t, "feature1")
 	assertNoMobSessionBranches(t, configuration, "mob-session")
@@ -1446,11 +1546,11 @@ func TestStartNextFeatureBranch(t *testing.T) {
 
 func TestStartDoneFeatureBranchWithDash(t *testing.T) {
 	_, configuration := setup(t)
-	git("checkout", "-b", "feat/load_test_DLC-253")
-	git("push", "origin", "feat/load_test_DLC-253", "--set-upstream")
-	start(configuration)
+	git(gitRunner, "checkout", "-b", "feat/load_test_DLC-253")
+	git(gitRunner, "push", "origin", "feat/load_test_DLC-253", "--set-upstream")
+	start(gitRunner, configuration)
 	createFile(t, "file1.txt", "contentIrrelevant")
-	done(configuration)
+	done(gitRunner, configuration)
 
 	assertNoMobSessionBranches(t, configuration, "mob/feat/load_test_DLC-253")
 }
@@ -1458,7 +1558,9 @@ func TestStartDoneFeatureBranchWithDash(t *testing.T) {
 func TestGitRootDir(t *testing.T) {
 	setup(t)
 	expectedPath, _ := filepath.EvalSymlinks(tempDir + "/local")
-	equals(t, expectedPath, filepath.FromSlash(gitRootDir()))
+	rootDir, err := gitRootDir(gitRunner)
+	assertNoError(t, err)
+	equals(t, expectedPath, filepath.FromSlash(rootDir))
 }
 
 func TestGitRootDirWithSymbolicLink(t *testing.T) {
@@ -1466,35 +1568,37 @@ func TestGitRootDirWithSymbolicLink(t *testing.T) {
 	symlinkDir := tempDir + "/local-symlink"
 	setWorkingDir(symlinkDir)
 	expectedLocalSymlinkPath, _ := filepath.EvalSymlinks(symlinkDir)
-	equals(t, expectedLocalSymlinkPath, filepath.FromSlash(gitRootDir()))
+	rootDir, err := gitRootDir(gitRunner)
+	assertNoError(t, err)
+	equals(t, expectedLocalSymlinkPath, filepath.FromSlash(rootDir))
 }
 
 func TestBothCreateNonemptyCommitWithNext(t *testing.T) {
 	_, configuration := setup(t)
 
 	setWorkingDir(tempDir + "/local")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file1.txt", "contentIrrelevant")
 
 	setWorkingDir(tempDir + "/localother")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file2.txt", "contentIrrelevant")
 
 	setWorkingDir(tempDir + "/local")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/localother")
 	// next(configuration) not possible, would fail
-	git("pull")
-	next(configuration)
+	git(gitRunner, "pull")
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/local")
-	start(configuration)
+	start(gitRunner, configuration)
 	assertFileExist(t, "file1.txt")
 	assertFileExist(t, "file2.txt")
 
 	setWorkingDir(tempDir + "/localother")
-	start(configuration)
+	start(gitRunner, configuration)
 	assertFileExist(t, "file1.txt")
 	assertFileExist(t, "file2.txt")
 }
@@ -1503,25 +1607,25 @@ func TestNothingToCommitCreatesNoCommits(t *testing.T) {
 	_, configuration := setup(t)
 
 	setWorkingDir(tempDir + "/local")
-	start(configuration)
+	start(gitRunner, configuration)
 	assertCommits(t, 1)
 
 	setWorkingDir(tempDir + "/localother")
-	start(configuration)
+	start(gitRunner, configuration)
 	assertCommits(t, 1)
 
 	setWorkingDir(tempDir + "/local")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/localother")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/local")
-	start(configuration)
+	start(gitRunner, configuration)
 	assertCommits(t, 1)
 
 	setWorkingDir(tempDir + "/localother")
-	start(configuration)
+	start(gitRunner, configuration)
 	assertCommits(t, 1)
 }
 
@@ -1530,12 +1634,12 @@ func TestStartNextPushManualCommits(t *testing.T) {
 
 	setWorkingDir(tempDir + "/local")
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFileAndCommitIt(t, "example.txt", "contentIrrelevant", "asdf")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/localother")
-	start(configuration)
+	start(gitRunner, configuration)
 	assertFileExist(t, "example.txt")
 }
 
@@ -1573,7 +1677,7 @@ func TestStartCreateBranchWithUncommitedChangesFixWithBranch(t *testing.T) {
 
 	setWorkingDir(tempDir + "/local")
 
-	git("checkout", "-b", "unpushedBranch")
+	git(gitRunner, "checkout", "-b", "unpushedBranch")
 	createFile(t, "uncommited.txt", "contentIrrelevant")
 	runMob(t, tempDir+"/local", "start", "--create", "-b", "green")
 
@@ -1588,7 +1692,7 @@ func TestStartCreateBranchEnvWithUncommitedChangesFixWithoutBranch(t *testing.T)
 
 	setWorkingDir(tempDir + "/local")
 	os.Setenv("MOB_WIP_BRANCH_QUALIFIER", "red")
-	git("checkout", "-b", "unpushedBranch")
+	git(gitRunner, "checkout", "-b", "unpushedBranch")
 	createFile(t, "uncommited.txt", "contentIrrelevant")
 	runMob(t, tempDir+"/local", "start", "--create")
 
@@ -1603,19 +1707,19 @@ func TestStartNextPushManualCommitsFeatureBranch(t *testing.T) {
 
 	setWorkingDir(tempDir + "/local")
 
-	git("checkout", "-b", "feature1")
-	git("push", "origin", "feature1", "--set-upstream")
+	git(gitRunner, "checkout", "-b", "feature1")
+	git(gitRunner, "push", "origin", "feature1", "--set-upstream")
 	assertOnBranch(t, "feature1")
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob/feature1")
 
 	createFileAndCommitIt(t, "example.txt", "contentIrrelevant", "asdf")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/localother")
-	git("fetch")
-	git("checkout", "feature1")
-	start(configuration)
+	git(gitRunner, "fetch")
+	git(gitRunner, "checkout", "feature1")
+	start(gitRunner, configuration)
 	assertFileExist(t, "example.txt")
 }
 
@@ -1623,26 +1727,26 @@ func TestConflictingMobSessions(t *testing.T) {
 	_, configuration := setup(t)
 
 	setWorkingDir(tempDir + "/local")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "example.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/localother")
-	start(configuration)
-	next(configuration)
+	start(gitRunner, configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/local")
-	start(configuration)
-	done(configuration)
-	git("commit", "-m", "\"finished mob session\"")
+	start(gitRunner, configuration)
+	done(gitRunner, configuration)
+	git(gitRunner, "commit", "-m", "\"finished mob session\"")
 
 	setWorkingDir(tempDir + "/local")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "example2.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/localother")
-	start(configuration)
+	start(gitRunner, configuration)
 }
 
 func TestConflictingMobSessionsNextStay(t *testing.T) {
@@ -1650,66 +1754,460 @@ func TestConflictingMobSessionsNextStay(t *testing.T) {
 	configuration.NextStay = true
 
 	setWorkingDir(tempDir + "/local")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "example.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/localother")
-	start(configuration)
-	next(configuration)
+	start(gitRunner, configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/local")
-	start(configuration)
-	done(configuration)
-	git("commit", "-m", "\"finished mob session\"")
+	start(gitRunner, configuration)
+	done(gitRunner, configuration)
+	git(gitRunner, "commit", "-m", "\"finished mob session\"")
 
 	setWorkingDir(tempDir + "/localother")
-	start(configuration)
+	start(gitRunner, configuration)
 }
 
 func TestDoneMergeConflict(t *testing.T) {
+	_, configuration := setup(t)
+
+	setWorkingDir(tempDir + "/local")
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "content")
+	next(gitRunner, configuration)
+
+	setWorkingDir(tempDir + "/localother")
+	createFileAndCommitIt(t, "example.txt", "asdf", "asdf")
+	git(gitRunner, "push")
+
+	setWorkingDir(tempDir + "/local")
+	start(gitRunner, configuration)
+	err := done(gitRunner, configuration)
+	assertError(t, err, "merging mob-session into master failed because of merge conflicts")
+	assertErrorIs(t, err, ErrMergeConflict)
+	assertFix(t, err, "mob done --continue")
+	assertFix(t, err, "mob done --abort")
+	assertMobSessionBranches(t, configuration, "mob-session")
+}
+
+func TestDoneMergeConflictContinue(t *testing.T) {
+	output, configuration := setupDoneMergeConflict(t)
+	assertErrorIs(t, done(gitRunner, configuration), ErrMergeConflict)
+
+	createFile(t, "example.txt", "resolved")
+	git(gitRunner, "add", "example.txt")
+	configuration.DoneContinue = true
+	assertNoError(t, done(gitRunner, configuration))
+
+	assertOnBranch(t, "master")
+	assertGitStatus(t, GitStatus{
+		"example.txt": "M",
+	})
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+	assertOutputContains(t, output, "  git commit")
+	equals(t, false, hasOperationInProgress(gitRunner))
+}
+
+func TestDoneNoSquashMergeConflictContinue(t *testing.T) {
+	_, configuration := setupDoneMergeConflict(t)
+	configuration.DoneSquash = config.NoSquash
+	assertErrorIs(t, done(gitRunner, configuration), ErrMergeConflict)
+
+	createFile(t, "example.txt", "resolved")
+	git(gitRunner, "add", "example.txt")
+	continueConfiguration := config.GetDefaultConfiguration()
+	continueConfiguration.SkipCiPushOptionEnabled = false
+	continueConfiguration.DoneContinue = true
+	assertNoError(t, done(gitRunner, continueConfiguration))
+
+	assertOnBranch(t, "master")
+	assertCleanGitStatus(t)
+	assertCommitsOnBranch(t, 4, "master")
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+}
+
+func TestDoneNoSquashMergeConflictContinueKeepsTheMergedWipHistory(t *testing.T) {
+	_, configuration := setupDoneMergeConflict(t)
+	configuration.DoneSquash = config.NoSquash
+	createFile(t, "uncommitted.txt", "contentIrrelevant")
+	assertErrorIs(t, done(gitRunner, configuration), ErrMergeConflict)
+
+	createFile(t, "example.txt", "resolved")
+	git(gitRunner, "add", "example.txt")
+	configuration.DoneContinue = true
+	assertNoError(t, done(gitRunner, configuration))
+
+	assertOnBranch(t, "master")
+	assertGitStatus(t, GitStatus{
+		"uncommitted.txt": "A",
+	})
+	assertCommitLogContainsMessage(t, "master", configuration.WipCommitMessage)
+	equals(t, "resolved", gitOutput(t, "show", "HEAD:example.txt"))
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+}
+
+func TestDoneMergeConflictContinueWithUnresolvedConflicts(t *testing.T) {
+	_, configuration := setupDoneMergeConflict(t)
+	assertErrorIs(t, done(gitRunner, configuration), ErrMergeConflict)
+
+	configuration.DoneContinue = true
+	err := done(gitRunner, configuration)
+
+	assertError(t, err, "cannot continue; there are unresolved merge conflicts in")
+	assertErrorIs(t, err, ErrMergeConflict)
+	equals(t, true, hasOperationInProgress(gitRunner))
+}
+
+func TestDoneMergeConflictBlocksAnotherDone(t *testing.T) {
+	_, configuration := setupDoneMergeConflict(t)
+	assertErrorIs(t, done(gitRunner, configuration), ErrMergeConflict)
+
+	err := done(gitRunner, configuration)
+	assertErrorIs(t, err, ErrOperationInProgress)
+	assertFix(t, err, "mob done --abort")
+}
+
+func TestDoneMergeConflictAbort(t *testing.T) {
+	output, configuration := setupDoneMergeConflict(t)
+	wipHeadBefore := gitCommitHash(gitRunner)
+	assertErrorIs(t, done(gitRunner, configuration), ErrMergeConflict)
+
+	configuration.DoneAbort = true
+	assertNoError(t, done(gitRunner, configuration))
+
+	assertOnBranch(t, "mob-session")
+	assertCleanGitStatus(t)
+	equals(t, wipHeadBefore, gitCommitHash(gitRunner))
+	equals(t, wipHeadBefore, gitRefHash(gitRunner, "refs/remotes/origin/mob-session"))
+	equals(t, false, hasOperationInProgress(gitRunner))
+	assertOutputContains(t, output, "rolled back 'mob done'")
+}
+
+func TestDoneMergeConflictAbortRestoresUncommittedChanges(t *testing.T) {
+	_, configuration := setupDoneMergeConflict(t)
+	wipHeadBefore := gitCommitHash(gitRunner)
+	createFile(t, "uncommitted.txt", "contentIrrelevant")
+	assertErrorIs(t, done(gitRunner, configuration), ErrMergeConflict)
+
+	configuration.DoneAbort = true
+	assertNoError(t, done(gitRunner, configuration))
+
+	assertOnBranch(t, "mob-session")
+	assertGitStatus(t, GitStatus{
+		"uncommitted.txt": "??",
+	})
+	equals(t, wipHeadBefore, gitCommitHash(gitRunner))
+	git(gitRunner, "fetch")
+	equals(t, wipHeadBefore, gitRefHash(gitRunner, "refs/remotes/origin/mob-session"))
+}
+
+func TestDoneMergeConflictAbortKeepsWhatSomeoneElsePushed(t *testing.T) {
+	output, configuration := setupDoneMergeConflict(t)
+	createFile(t, "uncommitted.txt", "contentIrrelevant")
+	assertErrorIs(t, done(gitRunner, configuration), ErrMergeConflict)
+	setWorkingDir(tempDir + "/alice")
+	start(gitRunner, configuration)
+	createFile(t, "alice.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+	aliceHead := gitRefHash(gitRunner, "refs/remotes/origin/mob-session")
+
+	setWorkingDir(tempDir + "/local")
+	configuration.DoneAbort = true
+	assertNoError(t, done(gitRunner, configuration))
+
+	assertOutputContains(t, output, "did not restore origin/mob-session because someone else pushed to it in the meantime")
+	git(gitRunner, "fetch")
+	equals(t, aliceHead, gitRefHash(gitRunner, "refs/remotes/origin/mob-session"))
+}
+
+func setupDoneMergeConflict(t *testing.T) (*string, config.Configuration) {
 	output, configuration := setup(t)
 
 	setWorkingDir(tempDir + "/local")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "example.txt", "content")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/localother")
 	createFileAndCommitIt(t, "example.txt", "asdf", "asdf")
-	git("push")
+	git(gitRunner, "push")
 
 	setWorkingDir(tempDir + "/local")
-	start(configuration)
-	done(configuration)
-	assertOutputContains(t, output, "To fix this, solve the merge conflict manually, commit, push, and afterwards delete mob-session")
+	start(gitRunner, configuration)
+	return output, configuration
 }
 
 func TestDoneMerge(t *testing.T) {
 	output, configuration := setup(t)
 
 	setWorkingDir(tempDir + "/local")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "example.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/localother")
 	createFileAndCommitIt(t, "example2.txt", "contentIrrelevant", "asdf")
-	git("push")
+	git(gitRunner, "push")
 
 	setWorkingDir(tempDir + "/local")
-	start(configuration)
-	done(configuration)
+	start(gitRunner, configuration)
+	done(gitRunner, configuration)
 	assertOutputContains(t, output, "  git commit")
 }
 
+func TestStartDoneCommit(t *testing.T) {
+	_, configuration := setup(t)
+
+	setWorkingDir(tempDir + "/alice")
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+
+	setWorkingDir(tempDir + "/bob")
+	start(gitRunner, configuration)
+	createFile(t, "example2.txt", "contentIrrelevant")
+	configuration.DoneCommit = true
+	assertNoError(t, done(gitRunner, configuration))
+
+	assertOnBranch(t, "master")
+	assertCleanGitStatus(t)
+	assertCommitsOnBranch(t, 2, "master")
+	assertCommitsOnBranch(t, 1, "origin/master")
+	lastCommitMessage := gitOutput(t, "log", "-1", "--pretty=format:%B")
+	assertOutputContains(t, &lastCommitMessage, "Co-authored-by: alice <alice@example.com>")
+	assertOutputNotContains(t, &lastCommitMessage, "# automatically added all co-authors")
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+}
+
+func TestStartDoneCommitWithMessage(t *testing.T) {
+	_, configuration := setup(t)
+
+	setWorkingDir(tempDir + "/alice")
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+
+	setWorkingDir(tempDir + "/bob")
+	start(gitRunner, configuration)
+	configuration.DoneCommit = true
+	configuration.DoneCommitMessage = "create example file"
+	assertNoError(t, done(gitRunner, configuration))
+
+	assertCleanGitStatus(t)
+	equals(t, "create example file\n\nCo-authored-by: alice <alice@example.com>", gitOutput(t, "log", "-1", "--pretty=format:%B"))
+}
+
+func TestStartDoneNoSquashCommitRequiresMessage(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.DoneSquash = config.NoSquash
+	configuration.DoneCommit = true
+
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+
+	err := done(gitRunner, configuration)
+	assertErrorIs(t, err, ErrCommitMessageRequired)
+	assertFix(t, err, "mob done --commit --message \"<message>\"")
+	assertGitStatus(t, GitStatus{
+		"example.txt": "A",
+	})
+}
+
+func TestStartDonePush(t *testing.T) {
+	output, configuration := setup(t)
+
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	configuration.DoneCommit = true
+	configuration.DonePush = true
+	assertNoError(t, done(gitRunner, configuration))
+
+	assertCleanGitStatus(t)
+	assertCommitsOnBranch(t, 2, "master")
+	assertCommitsOnBranch(t, 2, "origin/master")
+	assertOutputContains(t, output, "pushed base branch 'master' to origin")
+}
+
+func TestRecoverNothingToRecover(t *testing.T) {
+	output, configuration := setup(t)
+
+	assertNoError(t, recoverOperation(gitRunner, configuration, []string{}))
+
+	assertOutputContains(t, output, "nothing to recover")
+}
+
+func TestNextInterruptedByRejectedPush(t *testing.T) {
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	rejectPushesTo(t, "mob-session")
+
+	runUntilExit(func() { next(gitRunner, configuration) })
+
+	assertOnBranch(t, "mob-session")
+	equals(t, true, hasOperationInProgress(gitRunner))
+	assertErrorIs(t, start(gitRunner, configuration), ErrOperationInProgress)
+	assertNoError(t, recoverOperation(gitRunner, configuration, []string{}))
+	assertOutputContains(t, output, "'mob next' on wip branch 'mob-session' (base branch 'master') was interrupted")
+	assertOutputContains(t, output, "completed steps: wip-commit")
+	assertOutputContains(t, output, "mob recover --resume")
+	assertOutputContains(t, output, "mob recover --rollback")
+}
+
+func TestNextInterruptedRecoverResume(t *testing.T) {
+	_, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	removeHook := rejectPushesTo(t, "mob-session")
+	runUntilExit(func() { next(gitRunner, configuration) })
+	removeHook()
+
+	assertNoError(t, recoverOperation(gitRunner, configuration, []string{"--resume"}))
+
+	assertOnBranch(t, "master")
+	assertCommitsOnBranch(t, 2, "origin/mob-session")
+	equals(t, false, hasOperationInProgress(gitRunner))
+}
+
+func TestNextInterruptedRecoverRollback(t *testing.T) {
+	_, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	rejectPushesTo(t, "mob-session")
+	runUntilExit(func() { next(gitRunner, configuration) })
+
+	assertNoError(t, recoverOperation(gitRunner, configuration, []string{"--rollback"}))
+
+	assertOnBranch(t, "mob-session")
+	assertCommitsOnBranch(t, 1, "mob-session")
+	assertGitStatus(t, GitStatus{
+		"example.txt": "??",
+	})
+	equals(t, false, hasOperationInProgress(gitRunner))
+}
+
+func TestStartInterruptedRecoverRollback(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.HandleUncommittedChanges = config.IncludeChanges
+	createFile(t, "example.txt", "contentIrrelevant")
+	rejectPushesTo(t, "mob-session")
+	runUntilExit(func() { start(gitRunner, configuration) })
+	assertOnBranch(t, "mob-session")
+
+	assertNoError(t, recoverOperation(gitRunner, configuration, []string{"--rollback"}))
+
+	assertOnBranch(t, "master")
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+	assertGitStatus(t, GitStatus{
+		"example.txt": "??",
+	})
+	equals(t, false, hasOperationInProgress(gitRunner))
+}
+
+func TestStartInterruptedRecoverResume(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.HandleUncommittedChanges = config.IncludeChanges
+	createFile(t, "example.txt", "contentIrrelevant")
+	removeHook := rejectPushesTo(t, "mob-session")
+	runUntilExit(func() { start(gitRunner, configuration) })
+	removeHook()
+
+	assertNoError(t, recoverOperation(gitRunner, configuration, []string{"--resume"}))
+
+	assertOnBranch(t, "mob-session")
+	assertMobSessionBranches(t, configuration, "mob-session")
+	assertGitStatus(t, GitStatus{
+		"example.txt": "??",
+	})
+	equals(t, false, hasOperationInProgress(gitRunner))
+}
+
+func TestStartDryRun(t *testing.T) {
+	output, configuration := setup(t)
+	enableDryRun(t)
+
+	assertNoError(t, start(gitRunner, configuration))
+
+	assertOutputContains(t, output, "git checkout -B mob-session origin/master")
+	assertOutputContains(t, output, "git push --no-verify --set-upstream origin mob-session:mob-session")
+	assertOnBranch(t, "master")
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+	equals(t, false, hasOperationInProgress(gitRunner))
+}
+
+func TestNextDryRun(t *testing.T) {
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	enableDryRun(t)
+
+	next(gitRunner, configuration)
+
+	assertOutputContains(t, output, "git add --all")
+	assertOutputContains(t, output, "git push --no-verify origin mob-session")
+	assertOnBranch(t, "mob-session")
+	assertCommitsOnBranch(t, 1, "origin/mob-session")
+	assertGitStatus(t, GitStatus{
+		"example.txt": "??",
+	})
+}
+
+func TestDoneDryRun(t *testing.T) {
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	enableDryRun(t)
+
+	assertNoError(t, done(gitRunner, configuration))
+
+	assertOutputContains(t, output, "git merge --squash --ff mob-session")
+	assertOutputContains(t, output, "git push --no-verify origin --delete mob-session")
+	assertOnBranch(t, "mob-session")
+	assertMobSessionBranches(t, configuration, "mob-session")
+	equals(t, false, hasOperationInProgress(gitRunner))
+}
+
+func TestResetDryRun(t *testing.T) {
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+	configuration.ResetDeleteRemoteWipBranch = true
+	enableDryRun(t)
+
+	reset(gitRunner, configuration)
+
+	assertOutputContains(t, output, "git branch --delete --force mob-session")
+	assertMobSessionBranches(t, configuration, "mob-session")
+}
+
+func TestStartDonePushRejected(t *testing.T) {
+	_, configuration := setup(t)
+	createExecutableFileInPath(t, tempDir+"/remote/hooks", "pre-receive",
+		"#!/bin/sh\nwhile read old new ref; do if [ \"$ref\" = refs/heads/master ]; then exit 1; fi; done\n")
+
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	configuration.DoneCommit = true
+	configuration.DonePush = true
+	headBeforeCommit := gitCommitHash(gitRunner)
+
+	err := done(gitRunner, configuration)
+	assertError(t, err, "pushing base branch 'master' to origin was rejected")
+	assertErrorIs(t, err, ErrPushRejected)
+	assertFix(t, err, "git reset --soft "+headBeforeCommit)
+	assertCommitsOnBranch(t, 2, "master")
+	assertCommitsOnBranch(t, 1, "origin/master")
+}
+
 func TestDoneSquashNoChanges(t *testing.T) {
 	output, configuration := setup(t)
 	setWorkingDir(tempDir + "/local")
 	checkoutAndPushBranch("feature-something")
 
-	start(configuration)
-	done(configuration)
+	start(gitRunner, configuration)
+	done(gitRunner, configuration)
 
 	assertOutputContains(t, output, "nothing to commit")
 }
@@ -1719,11 +2217,11 @@ func TestDoneSquashWipStartCommit(t *testing.T) {
 	configuration.NextStay = true
 	configuration.DoneSquash = config.SquashWip
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file1.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 	assertCommitsOnBranch(t, 2, "mob-session")
-	done(configuration)
+	done(gitRunner, configuration)
 	assertCommitsOnBranch(t, 1, "master")
 }
 
@@ -1732,18 +2230,18 @@ func TestDonePullsIfAlreadyDone(t *testing.T) {
 	configuration.NextStay = true
 
 	setWorkingDir(tempDir + "/bob")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "example.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/alice")
-	start(configuration)
-	done(configuration)
-	git("commit", "-am", "\"mob done by Alice\"")
-	git("push")
+	start(gitRunner, configuration)
+	done(gitRunner, configuration)
+	git(gitRunner, "commit", "-am", "\"mob done by Alice\"")
+	git(gitRunner, "push")
 
 	setWorkingDir(tempDir + "/bob")
-	done(configuration)
+	done(gitRunner, configuration)
 
 	assertFileExist(t, "example.txt")
 }
@@ -1753,11 +2251,11 @@ func TestDoneNoSquashStartCommit(t *testing.T) {
 	configuration.NextStay = true
 	configuration.DoneSquash = config.NoSquash
 
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file1.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 	assertCommitsOnBranch(t, 2, "mob-session")
-	done(configuration)
+	done(gitRunner, configuration)
 	assertCommitsOnBranch(t, 2, "master")
 }
 
@@ -1765,19 +2263,19 @@ func TestStartAndNextInSubdir(t *testing.T) {
 	_, configuration := setup(t)
 
 	setWorkingDir(tempDir + "/local/subdir")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "example.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/localother/subdir")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "example2.txt", "contentIrrelevant")
 	createFile(t, "../example3.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/local/subdir")
-	start(configuration)
-	done(configuration)
+	start(gitRunner, configuration)
+	done(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/local")
 	assertFileExist(t, "subdir/example.txt")
@@ -1787,18 +2285,18 @@ func TestStartAndNextInSubdir(t *testing.T) {
 
 func TestIsGitIdentifiesGitRepo(t *testing.T) {
 	setup(t)
-	equals(t, true, isGit())
+	equals(t, true, isGit(gitRunner))
 }
 
 func TestIsGitIdentifiesOutsideOfGitRepo(t *testing.T) {
 	setWorkingDir(tempDir + "/notgit")
-	equals(t, false, isGit())
+	equals(t, false, isGit(gitRunner))
 }
 
 func TestEmptyGitStatus(t *testing.T) {
 	setup(t)
 
-	status := gitStatus()
+	status := gitStatus(t)
 
 	equals(t, 0, len(status))
 	assertCleanGitStatus(t)
@@ -1808,7 +2306,7 @@ func TestGitStatusWithOneFile(t *testing.T) {
 	setup(t)
 	createFile(t, "hello.txt", "contentIrrelevant")
 
-	status := gitStatus()
+	status := gitStatus(t)
 
 	equals(t, GitStatus{
 		"hello.txt": "??",
@@ -1819,9 +2317,9 @@ func TestGitStatusWithManyFiles(t *testing.T) {
 	setup(t)
 	createFile(t, "hello.txt", "contentIrrelevant")
 	createFile(t, "added.txt", "contentIrrelevant")
-	git("add", "added.txt")
+	git(gitRunner, "add", "added.txt")
 
-	status := gitStatus()
+	status := gitStatus(t)
 
 	equals(t, GitStatus{
 		"added.txt": "A",
@@ -1832,9 +2330,9 @@ func TestGitStatusWithManyFiles(t *testing.T) {
 func TestBranchesDoNotDiverge(t *testing.T) {
 	setup(t)
 	createFileAndCommitIt(t, "example.txt", "asdf", "asdf")
-	git("checkout", "-b", "diverges")
+	git(gitRunner, "checkout", "-b", "diverges")
 
-	diverge := doBranchesDiverge("master", "diverges")
+	diverge := doBranchesDiverge(gitRunner, "master", "diverges")
 
 	equals(t, false, diverge)
 }
@@ -1842,12 +2340,12 @@ func TestBranchesDoNotDiverge(t *testing.T) {
 func TestBranchesDoDiverge(t *testing.T) {
 	setup(t)
 	createFileAndCommitIt(t, "example.txt", "asdf", "asdf")
-	git("checkout", "-b", "diverges")
+	git(gitRunner, "checkout", "-b", "diverges")
 	createFileAndCommitIt(t, "example.txt", "other", "asdf")
-	git("checkout", "master")
+	git(gitRunner, "checkout", "master")
 	createFileAndCommitIt(t, "diverging-commit.txt", "asdf", "diverging")
 
-	diverge := doBranchesDiverge("master", "diverges")
+	diverge := doBranchesDiverge(gitRunner, "master", "diverges")
 
 	equals(t, true, diverge)
 }
@@ -1864,22 +2362,22 @@ func TestMobClean(t *testing.T) {
 	_, configuration := setup(t)
 
 	setWorkingDir(tempDir + "/alice")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file1.txt", "abc")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/bob")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file2.txt", "def")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/alice")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file3.txt", "ghi")
-	done(configuration)
+	done(gitRunner, configuration)
 
 	setWorkingDir(tempDir + "/bob")
-	clean(configuration)
+	clean(gitRunner, configuration)
 
 	assertNoMobSessionBranches(t, configuration, "mob-session")
 }
@@ -1888,24 +2386,24 @@ func TestMobStartOnWipBranchWithoutCheckedOutBaseBranchWithoutHyphens(t *testing
 	output, configuration := setup(t)
 
 	setWorkingDir(tempDir + "/alice")
-	git("checkout", "-b", "basebranchwithouthyphen")
+	git(gitRunner, "checkout", "-b", "basebranchwithouthyphen")
 	configuration.StartCreate = true
-	start(configuration)
+	start(gitRunner, configuration)
 	assertOnBranch(t, "mob/basebranchwithouthyphen")
 	createFile(t, "file1.txt", "abc")
-	next(configuration)
+	next(gitRunner, configuration)
 	assertOnBranch(t, "basebranchwithouthyphen")
 
 	setWorkingDir(tempDir + "/bob")
-	git("checkout", "-b", "mob/basebranchwithouthyphen")
+	git(gitRunner, "checkout", "-b", "mob/basebranchwithouthyphen")
 	configuration.StartCreate = false
 
-	assertNoError(t, start(configuration))
+	assertNoError(t, start(gitRunner, configuration))
 	assertOnBranch(t, "mob/basebranchwithouthyphen")
 	assertOutputContains(t, output, "joining existing session from origin/mob/basebranchwithouthyphen")
 
 	createFile(t, "file2.txt", "abc")
-	done(configuration)
+	done(gitRunner, configuration)
 	assertOnBranch(t, "basebranchwithouthyphen")
 }
 
@@ -1980,11 +2478,11 @@ func TestMobVersionWorksOutsideOfGitRepository(t *testing.T) {
 func runMob(t *testing.T, workingDir string, args ...string) {
 	setWorkingDir(workingDir)
 	newArgs := append([]string{"mob"}, args...)
-	run(newArgs)
+	run(gitRunner, newArgs)
 }
 
-func gitStatus() GitStatus {
-	shortStatus := silentgit("status", "--porcelain")
+func gitStatus(t *testing.T) GitStatus {
+	shortStatus := gitOutput(t, "status", "--porcelain")
 	statusLines := strings.Split(shortStatus, "\n")
 	var statusMap = make(GitStatus)
 	for _, line := range statusLines {
@@ -2008,8 +2506,8 @@ func setup(t *testing.T) (output *string, configuration config.Configuration) {
 	configuration.NextStay = false
 	createTestbed(t, configuration)
 	assertOnBranch(t, "master")
-	equals(t, []string{"master"}, gitBranches())
-	equals(t, []string{"origin/master"}, gitRemoteBranches())
+	equals(t, []string{"master"}, localBranches(t))
+	equals(t, []string{"origin/master"}, remoteBranches(t))
 	assertNoMobSessionBranches(t, configuration, "mob-session")
 	output = captureOutput(t)
 	return output, configuration
@@ -2031,6 +2529,37 @@ func mockOpenInBrowser() {
 	}
 }
 
+func enableDryRun(t *testing.T) {
+	originalGitRunner := gitRunner
+	DryRun = true
+	gitRunner = dryRunGitRunner{gitRunner}
+	t.Cleanup(func() {
+		DryRun = false
+		gitRunner = originalGitRunner
+	})
+}
+
+// runUntilExit runs action and stops it at the first Exit like the real process would
+func runUntilExit(action func()) {
+	originalExit := Exit
+	Exit = func(code int) {
+		panic(code)
+	}
+	defer func() {
+		Exit = originalExit
+		recover()
+	}()
+	action()
+}
+
+func rejectPushesTo(t *testing.T, branch string) (removeHook func()) {
+	hook := createExecutableFileInPath(t, tempDir+"/remote/hooks", "pre-receive",
+		"#!/bin/sh\nwhile read old new ref; do if [ \"$ref\" = refs/heads/"+branch+" ]; then exit 1; fi; done\n")
+	return func() {
+		removeFile(t, hook)
+	}
+}
+
 func mockExit() {
 	originalExitFunction = Exit
 	Exit = func(code int) {
@@ -2049,7 +2578,7 @@ func resetExit() {
 }
 
 func createTestbed(t *testing.T, configuration config.Configuration) {
-	workingDir = ""
+	gitRunner = newExecGitRunner("")
 
 	tempDir = t.TempDir()
 
@@ -2080,14 +2609,14 @@ func createTestbedIn(t *testing.T, temporaryDirectory string) {
 	cloneRepository(localDirectory, remoteDirectory)
 
 	say.Debug("Populate, initial import and push")
-	workingDir = localDirectory
+	gitRunner = newExecGitRunner(localDirectory)
 	createFile(t, "test.txt", "test")
 	createDirectory(t, "subdir")
 	createFileInPath(t, localDirectory+"/subdir", "subdir.txt", "subdir")
-	git("checkout", "-b", "master")
-	git("add", ".")
-	git("commit", "-m", "\"initial import\"")
-	git("push", "--set-upstream", "--all", "origin")
+	git(gitRunner, "checkout", "-b", "master")
+	git(gitRunner, "add", ".")
+	git(gitRunner, "commit", "-m", "\"initial import\"")
+	git(gitRunner, "push", "--set-upstream", "--all", "origin")
 
 	for _, name := range [3]string{"localother", "alice", "bob"} {
 		cleanRepository(temporaryDirectory + "/" + name)
@@ -2111,8 +2640,11 @@ func createTestbedIn(t *testing.T, temporaryDirectory string) {
 	say.Debug("Done.")
 }
 
+// gitRunner is the runner the tests pass to the commands, setWorkingDir points it to another repository
+var gitRunner GitRunner = newExecGitRunner("")
+
 func setWorkingDir(dir string) {
-	workingDir = dir
+	gitRunner = newExecGitRunner(dir)
 	say.Say("\n===== cd " + dir)
 }
 
@@ -2132,34 +2664,53 @@ func assertError(t *testing.T, err error, errorMessage string) {
 	}
 }
 
+func assertFix(t *testing.T, err error, instructionOrCommand string) {
+	var mobError *MobError
+	if !errors.As(err, &mobError) {
+		failWithFailure(t, "fix '"+instructionOrCommand+"'", err)
+	}
+	for _, fix := range mobError.Fixes {
+		if fix.Instruction == instructionOrCommand || fix.Command == instructionOrCommand {
+			return
+		}
+	}
+	failWithFailure(t, "fix '"+instructionOrCommand+"'", mobError.Fixes)
+}
+
+func assertErrorIs(t *testing.T, err error, kind error) {
+	if !errors.Is(err, kind) {
+		failWithFailure(t, kind, err)
+	}
+}
+
 func assertCommits(t *testing.T, commits int) {
 	assertCommitsOnBranch(t, commits, "HEAD")
 }
 
 func assertCommitsOnBranch(t *testing.T, commits int, branchName string) {
-	result := silentgit("rev-list", "--count", branchName)
+	result := gitOutput(t, "rev-list", "--count", branchName)
 	number, _ := strconv.Atoi(result)
 	if number != commits {
-		failWithFailure(t, strconv.Itoa(commits)+" commits in "+workingDir, strconv.Itoa(number)+" commits in "+workingDir)
+		failWithFailure(t, strconv.Itoa(commits)+" commits in "+gitRunner.Dir(), strconv.Itoa(number)+" commits in "+gitRunner.Dir())
 	}
 }
 
 func assertCommitLogContainsMessage(t *testing.T, branchName string, commitMessage string) {
-	logMessages := silentgit("log", branchName, "--oneline")
+	logMessages := gitOutput(t, "log", branchName, "--oneline")
 	if !strings.Contains(logMessages, commitMessage) {
 		failWithFailure(t, "git log contains '"+commitMessage+"'", logMessages)
 	}
 }
 
 func assertCommitLogNotContainsMessage(t *testing.T, branchName string, commitMessage string) {
-	logMessages := silentgit("log", branchName, "--oneline")
+	logMessages := gitOutput(t, "log", branchName, "--oneline")
 	if strings.Contains(logMessages, commitMessage) {
 		failWithFailure(t, "git log does not contain '"+commitMessage+"'", logMessages)
 	}
 }
 
 func assertFileExist(t *testing.T, filename string) {
-	path := workingDir + "/" + filename
+	path := gitRunner.Dir() + "/" + filename
 	if strings.Index(filename, "/") == 0 {
 		path = filename
 	}
@@ -2170,12 +2721,12 @@ func assertFileExist(t *testing.T, filename string) {
 
 func createFileAndCommitIt(t *testing.T, filename string, content string, commitMessage string) {
 	createFile(t, filename, content)
-	git("add", filename)
-	git("commit", "-m", commitMessage)
+	git(gitRunner, "add", filename)
+	git(gitRunner, "commit", "-m", commitMessage)
 }
 
 func createFile(t *testing.T, filename string, content string) (pathToFile string) {
-	return createFileInPath(t, workingDir, filename, content)
+	return createFileInPath(t, gitRunner.Dir(), filename, content)
 }
 
 func createFileInPath(t *testing.T, path, filename, content string) (pathToFile string) {
@@ -2201,7 +2752,7 @@ func createExecutableFileInPath(t *testing.T, path, filename, content string) (p
 }
 
 func createDirectory(t *testing.T, directory string) (pathToDirectory string) {
-	return ensureDirectoryExists(t, workingDir+"/"+directory)
+	return ensureDirectoryExists(t, gitRunner.Dir()+"/"+directory)
 }
 
 func ensureDirectoryExists(t *testing.T, path string) (pathToDirectory string) {
@@ -2236,7 +2787,8 @@ func readFile(t *testing.T, path string) string {
 }
 
 func assertOnBranch(t *testing.T, branch string) {
-	currentBranch := gitCurrentBranch()
+	currentBranch, err := gitCurrentBranch(gitRunner)
+	assertNoError(t, err)
 	if currentBranch.Name != branch {
 		failWithFailure(t, "on branch "+branch, "on branch "+currentBranch.String())
 	}
@@ -2257,42 +2809,70 @@ func assertOutputNotContains(t *testing.T, output *string, notContains string) {
 
 func assertMobSessionBranches(t *testing.T, configuration config.Configuration, branchName string) {
 	branch := newBranch(branchName)
-	if !branch.hasRemoteBranch(configuration) {
+	if !hasRemoteBranch(t, configuration, branch) {
 		failWithFailure(t, branch.remote(configuration).Name, "none")
 	}
-	if !branch.hasLocalBranch() {
+	if !hasLocalBranch(t, branch) {
 		failWithFailure(t, branchName, "none")
 	}
 }
 
 func assertLocalBranch(t *testing.T, branch string) {
-	if !newBranch(branch).hasLocalBranch() {
+	if !hasLocalBranch(t, newBranch(branch)) {
 		failWithFailure(t, branch, "none")
 	}
 }
 
 func assertNoLocalBranch(t *testing.T, branch string) {
-	if newBranch(branch).hasLocalBranch() {
+	if hasLocalBranch(t, newBranch(branch)) {
 		failWithFailure(t, branch, "none")
 	}
 }
 
 func assertNoMobSessionBranches(t *testing.T, configuration config.Configuration, branchName string) {
 	branch := newBranch(branchName)
-	if branch.hasRemoteBranch(configuration) {
+	if hasRemoteBranch(t, configuration, branch) {
 		failWithFailure(t, "none", branch.remote(configuration).Name)
 	}
-	if branch.hasLocalBranch() {
+	if hasLocalBranch(t, branch) {
 		failWithFailure(t, "none", branchName)
 	}
 }
 
+func hasLocalBranch(t *testing.T, branch Branch) bool {
+	t.Helper()
+	exists, err := branch.hasLocalBranch(gitRunner)
+	assertNoError(t, err)
+	return exists
+}
+
+func hasRemoteBranch(t *testing.T, configuration config.Configuration, branch Branch) bool {
+	t.Helper()
+	exists, err := branch.hasRemoteBranch(gitRunner, configuration)
+	assertNoError(t, err)
+	return exists
+}
+
+func localBranches(t *testing.T) []string {
+	t.Helper()
+	branches, err := gitBranches(gitRunner)
+	assertNoError(t, err)
+	return branches
+}
+
+func remoteBranches(t *testing.T) []string {
+	t.Helper()
+	branches, err := gitRemoteBranches(gitRunner)
+	assertNoError(t, err)
+	return branches
+}
+
 func assertGitStatus(t *testing.T, expected map[string]string) {
-	equals(t, expected, gitStatus())
+	equals(t, expected, gitStatus(t))
 }
 
 func assertCleanGitStatus(t *testing.T) {
-	status := gitStatus()
+	status := gitStatus(t)
 	if len(status) != 0 {
 		_, file, line, _ := runtime.Caller(1)
 		fmt.Printf("\033[31m%s:%d:\n\n\texpected a clean git status, but contained %s\"\n", filepath.Base(file), line, status)
@@ -2314,8 +2894,8 @@ func failWithFailure(t *testing.T, exp interface{}, act interface{}) {
 }
 
 func checkoutAndPushBranch(branch string) {
-	git("checkout", "-b", branch)
-	git("push", "origin", branch, "--set-upstream")
+	git(gitRunner, "checkout", "-b", branch)
+	git(gitRunner, "push", "origin", branch, "--set-upstream")
 }
 
 func cleanRepository(path string) {
@@ -2337,13 +2917,13 @@ func createRemoteRepository(path string) {
 		say.Error(err.Error())
 		return
 	}
-	workingDir = path
+	gitRunner = newExecGitRunner(path)
 	say.Debug("before git init")
-	git("--bare", "init")
+	git(gitRunner, "--bare", "init")
 	say.Debug("before symbolic-ref")
-	git("symbolic-ref", "HEAD", "refs/heads/"+branch)
+	git(gitRunner, "symbolic-ref", "HEAD", "refs/heads/"+branch)
 	// see bug #346 changes the output of git status --short
-	git("config", "--local", "status.branch", "true")
+	git(gitRunner, "config", "--local", "status.branch", "true")
 	say.Debug("finished")
 }
 
@@ -2355,13 +2935,13 @@ func cloneRepository(path, remoteDirectory string) {
 		say.Error(err.Error())
 		return
 	}
-	workingDir = path
+	gitRunner = newExecGitRunner(path)
 	name := basename(path)
-	git("clone", "--origin", "origin", "file://"+remoteDirectory, ".")
-	git("config", "--local", "user.name", name)
-	git("config", "--local", "user.email", name+"@example.com")
+	git(gitRunner, "clone", "--origin", "origin", "file://"+remoteDirectory, ".")
+	git(gitRunner, "config", "--local", "user.name", name)
+	git(gitRunner, "config", "--local", "user.email", name+"@example.com")
 	// see bug #346 changes the output of git status --short
-	git("config", "--local", "status.branch", "true")
+	git(gitRunner, "config", "--local", "status.branch", "true")
 }
 
 func cloneRepositoryWithSymlink(path, gitDirectory, remoteDirectory string) {
@@ -2416,3 +2996,11 @@ func getSymlinkGitDirectory(path string) string {
 func getSymlinkDirectory(path string) string {
 	return path + "/local-symlink"
 }
+
+// gitOutput runs a git command that must succeed and returns its output
+func gitOutput(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := silentgit(gitRunner, args...)
+	assertNoError(t, err)
+	return output
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// Files that match a pattern in .mobignore (gitignore syntax), e.g. scratch files or personal notes,
+// stay untouched in the working tree but never end up in the wip branch.
+
+const mobIgnoreFile = ".mobignore"
+
+// mobIgnorePatterns returns the patterns of .mobignore as glob pathspecs relative to the root directory.
+// Git can't re-include a file with a pathspec, so negated patterns (!) are not supported.
+func mobIgnorePatterns(runner GitRunner) ([]string, error) {
+	rootDir, err := gitRootDir(runner)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(rootDir + "/" + mobIgnoreFile)
+	if err != nil {
+		return nil, nil
+	}
+	patterns := []string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		pattern := strings.TrimRight(line, " \t\r")
+		if pattern == "" || strings.HasPrefix(pattern, "#") || strings.HasPrefix(pattern, "!") {
+			continue
+		}
+		if strings.HasPrefix(pattern, "\\#") || strings.HasPrefix(pattern, "\\!") {
+			pattern = pattern[1:]
+		}
+		directoryOnly := strings.HasSuffix(pattern, "/")
+		pattern = strings.TrimSuffix(pattern, "/")
+		if strings.Contains(pattern, "/") {
+			pattern = strings.TrimPrefix(pattern, "/")
+		} else {
+			pattern = "**/" + pattern
+		}
+		if pattern == "" {
+			continue
+		}
+		if !directoryOnly {
+			patterns = append(patterns, pattern)
+		}
+		patterns = append(patterns, pattern+"/**")
+	}
+	return patterns, nil
+}
+
+// mobIgnorePathspec limits a git command to the whole repository without the files matching .mobignore
+func mobIgnorePathspec(runner GitRunner) ([]string, error) {
+	patterns, err := mobIgnorePatterns(runner)
+	if err != nil || len(patterns) == 0 {
+		return nil, err
+	}
+	pathspec := []string{"--", ":(top)"}
+	for _, pattern := range patterns {
+		pathspec = append(pathspec, ":(top,exclude,glob)"+pattern)
+	}
+	return pathspec, nil
+}
+
+// stageChanges adds all changes to the index of runner, except the files matching .mobignore
+func stageChanges(runner GitRunner) error {
+	if err := git(runner, "add", "--all"); err != nil {
+		return err
+	}
+	return unstageMobIgnoredFiles(runner)
+}
+
+// unstageMobIgnoredFiles resets the files matching .mobignore in the index, their changes stay in the working tree
+func unstageMobIgnoredFiles(runner GitRunner) error {
+	patterns, err := mobIgnorePatterns(runner)
+	if err != nil || len(patterns) == 0 {
+		return err
+	}
+	args := []string{"reset", "--quiet", "--"}
+	for _, pattern := range patterns {
+		args = append(args, ":(top,glob)"+pattern)
+	}
+	_, err = silentgitChange(runner, args...)
+	return err
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+)
+
+func setupMobIgnore(t *testing.T) {
+	createFileAndCommitIt(t, ".mobignore", "notes.txt\nscratch/\n", "add .mobignore")
+	git(gitRunner, "push", "origin", "master")
+}
+
+func TestNextKeepsMobIgnoredFilesOutOfWipBranch(t *testing.T) {
+	_, configuration := setup(t)
+	setupMobIgnore(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	createFile(t, "notes.txt", "personal notes")
+	os.Mkdir(tempDir+"/local/scratch", 0755)
+	createFile(t, "scratch/idea.txt", "contentIrrelevant")
+
+	next(gitRunner, configuration)
+
+	equals(t, "example.txt", gitOutput(t, "diff", "--name-only", "origin/master", "origin/mob-session"))
+	assertGitStatus(t, GitStatus{
+		"notes.txt": "??",
+		"scratch/":  "??",
+	})
+}
+
+func TestNextWithOnlyMobIgnoredChanges(t *testing.T) {
+	output, configuration := setup(t)
+	setupMobIgnore(t)
+	start(gitRunner, configuration)
+	createFile(t, "notes.txt", "personal notes")
+
+	next(gitRunner, configuration)
+
+	assertOutputContains(t, output, "nothing was done, so nothing to commit")
+	equals(t, "", gitOutput(t, "diff", "--name-only", "origin/master", "origin/mob-session"))
+	assertFileExist(t, "notes.txt")
+}
+
+func TestNextDryRunShowsUnstagingMobIgnoredFiles(t *testing.T) {
+	output, configuration := setup(t)
+	setupMobIgnore(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	createFile(t, "notes.txt", "personal notes")
+	enableDryRun(t)
+
+	next(gitRunner, configuration)
+
+	assertOutputContains(t, output, "git add --all")
+	assertOutputContains(t, output, "git reset --quiet -- :(top,glob)**/notes.txt :(top,glob)**/notes.txt/** :(top,glob)**/scratch/**")
+}
+
+func TestStartIncludeUncommittedChangesLeavesMobIgnoredFiles(t *testing.T) {
+	_, configuration := setup(t)
+	setupMobIgnore(t)
+	configuration.HandleUncommittedChanges = config.IncludeChanges
+	createFile(t, "example.txt", "contentIrrelevant")
+	createFile(t, "notes.txt", "personal notes")
+
+	start(gitRunner, configuration)
+
+	assertOnBranch(t, "mob-session")
+	equals(t, "", gitOutput(t, "stash", "list"))
+	assertGitStatus(t, GitStatus{
+		"example.txt": "??",
+		"notes.txt":   "??",
+	})
+}
+
+func TestDoneKeepsMobIgnoredFilesOutOfBaseBranch(t *testing.T) {
+	_, configuration := setup(t)
+	setupMobIgnore(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	createFile(t, "notes.txt", "personal notes")
+
+	done(gitRunner, configuration)
+
+	assertOnBranch(t, "master")
+	assertGitStatus(t, GitStatus{
+		"example.txt": "A",
+		"notes.txt":   "??",
+	})
+}
+
+func TestCheckpointKeepsMobIgnoredFilesOutOfAutosave(t *testing.T) {
+	_, configuration := setup(t)
+	setupMobIgnore(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	createFile(t, "notes.txt", "personal notes")
+
+	commit := createCheckpoint(t, configuration)
+
+	equals(t, "example.txt", gitOutput(t, "diff", "--name-only", "HEAD", commit))
+}
+
+func TestNextKeepsMobIgnoredFilesInSubdirectoriesOutOfWipBranch(t *testing.T) {
+	_, configuration := setup(t)
+	createFileAndCommitIt(t, ".mobignore", "*.log\n/docs/*.draft\n", "add .mobignore")
+	git(gitRunner, "push", "origin", "master")
+	start(gitRunner, configuration)
+	os.MkdirAll(tempDir+"/local/docs/deep", 0755)
+	createFile(t, "docs/debug.log", "contentIrrelevant")
+	createFile(t, "docs/idea.draft", "contentIrrelevant")
+	createFile(t, "docs/deep/other.draft", "contentIrrelevant")
+
+	next(gitRunner, configuration)
+
+	equals(t, "docs/deep/other.draft", gitOutput(t, "diff", "--name-only", "origin/master", "origin/mob-session"))
+}
+
+func TestMobIgnorePatterns(t *testing.T) {
+	setup(t)
+	createFile(t, ".mobignore", "# comment\n\nnotes.txt\nscratch/\n/build/*.tmp\n!keep.txt\n\\#hash\n")
+
+	patterns, err := mobIgnorePatterns(gitRunner)
+
+	assertNoError(t, err)
+	equals(t, []string{"**/notes.txt", "**/notes.txt/**", "**/scratch/**", "build/*.tmp", "build/*.tmp/**", "**/#hash", "**/#hash/**"}, patterns)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// A handover note tells the next typist what you intended, e.g. "I was about to rename X".
+// next stores it in the wip commit message, start and status show the note of the last wip commit.
+
+const handoverNotePrefix = "handoverNote:"
+
+const handoverNoteTemplate = `
+# Write a note for the next typist, e.g. what you were about to do.
+# Lines starting with '#' are ignored, an empty note hands over without a note.
+`
+
+// editNote opens the git editor, like git commit does. Without a terminal (e.g. in mob serve) there is no editor.
+var editNote = func(runner GitRunner, path string) error {
+	if !stdinIsTerminal() {
+		return errors.New("there is no terminal to open the editor in")
+	}
+	editor, err := silentgit(runner, "var", "GIT_EDITOR")
+	if err != nil {
+		return err
+	}
+	name, args := shellCommand(editorCommandLine(editor, path))
+	command := exec.Command(name, args...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	return command.Run()
+}
+
+// editorCommandLine appends the path to GIT_EDITOR, which may already contain arguments, e.g. "code --wait"
+func editorCommandLine(editor string, path string) string {
+	if !posixShell {
+		return "& " + editor + " '" + strings.ReplaceAll(path, "'", "''") + "'"
+	}
+	return editor + " " + escapeForShell(path, 0)
+}
+
+// withHandoverNote asks for the note in the editor if next was called with --note but without a text
+func withHandoverNote(runner GitRunner, configuration config.Configuration) (config.Configuration, error) {
+	if !configuration.NextNoteEdit {
+		return configuration, nil
+	}
+	gitDir, err := gitDir(runner)
+	if err != nil {
+		return configuration, err
+	}
+	path := gitDir + "/MOB_NOTE_EDITMSG"
+	defer os.Remove(path)
+	if err := os.WriteFile(path, []byte(handoverNoteTemplate), 0644); err != nil {
+		return configuration, &MobError{Kind: err, Message: "could not write the handover note", Details: []string{err.Error()}}
+	}
+	if err := editNote(runner, path); err != nil {
+		return configuration, &MobError{Kind: err, Message: "the editor for the handover note failed", Details: []string{err.Error()},
+			Fixes: []Fix{{"To hand over with a note without an editor, use", configuration.Mob("next --note \"<note>\"")}}}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return configuration, &MobError{Kind: err, Message: "could not read the handover note", Details: []string{err.Error()}}
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, strings.TrimRight(line, " \r\t"))
+		}
+	}
+	configuration.NextNote = strings.TrimSpace(strings.Join(lines, "\n"))
+	return configuration, nil
+}
+
+// handoverNoteCommitMessage quotes notes with several lines, and notes starting with a quote so they read back as is
+func handoverNoteCommitMessage(note string) string {
+	if strings.Contains(note, "\n") || strings.HasPrefix(note, "\"") {
+		return handoverNotePrefix + strconv.Quote(note)
+	}
+	return handoverNotePrefix + note
+}
+
+func handoverNoteFromCommitMessage(message string) string {
+	for _, line := range strings.Split(message, "\n") {
+		if !strings.HasPrefix(line, handoverNotePrefix) {
+			continue
+		}
+		note := strings.TrimPrefix(line, handoverNotePrefix)
+		if strings.HasPrefix(note, "\"") {
+			if unquoted, err := strconv.Unquote(note); err == nil {
+				return unquoted
+			}
+		}
+		return note // older versions didn't quote one-line notes starting with a quote
+	}
+	return ""
+}
+
+// lastHandoverNote returns the author and the note of the given wip commit, or no note if the commit can't be read
+func lastHandoverNote(runner GitRunner, configuration config.Configuration, commit string) (author string, note string) {
+	message, err := silentgitignorefailure(runner, "log", "-1", "--pretty=format:%B", commit)
+	if err != nil || !configuration.IsWipCommitMessage(message) {
+		return "", ""
+	}
+	note = handoverNoteFromCommitMessage(message)
+	if note == "" {
+		return "", ""
+	}
+	author, _ = silentgitignorefailure(runner, "log", "-1", "--pretty=format:%an", commit)
+	return author, note
+}
+
+func sayHandoverNote(author string, note string) {
+	if note == "" {
+		return
+	}
+	say.WithPrefix("note from "+author+":", "📝 ")
+	say.InfoIndented(note)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+)
+
+func TestNextWithNote(t *testing.T) {
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	configuration.NextNote = "I was about to rename X"
+
+	next(gitRunner, configuration)
+
+	assertCommitMessageContains(t, "origin/mob-session", "handoverNote:I was about to rename X")
+	assertCommitMessageContains(t, "origin/mob-session", "lastFile:example.txt")
+
+	setWorkingDir(tempDir + "/localother")
+	configuration.NextNote = ""
+	*output = ""
+	start(gitRunner, configuration)
+
+	assertOutputContains(t, output, "📝 note from local:")
+	assertOutputContains(t, output, "    I was about to rename X")
+}
+
+func TestNextWithNoteWithoutChanges(t *testing.T) {
+	_, configuration := setup(t)
+	start(gitRunner, configuration)
+	configuration.NextNote = "please review the naming"
+
+	next(gitRunner, configuration)
+
+	equals(t, "please review the naming", handoverNoteFromCommitMessage(gitOutput(t, "log", "-1", "--pretty=format:%B", "origin/mob-session")))
+}
+
+func TestNextWithNoteFromEditor(t *testing.T) {
+	_, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	configuration.NextNoteEdit = true
+	originalEditNote := editNote
+	defer func() { editNote = originalEditNote }()
+	editNote = func(runner GitRunner, path string) error {
+		content, _ := os.ReadFile(path)
+		return os.WriteFile(path, []byte("first line\nsecond line\n"+string(content)), 0644)
+	}
+
+	next(gitRunner, configuration)
+
+	assertCommitMessageContains(t, "origin/mob-session", `handoverNote:"first line\nsecond line"`)
+	equals(t, "first line\nsecond line", handoverNoteFromCommitMessage(gitOutput(t, "log", "-1", "--pretty=format:%B", "origin/mob-session")))
+}
+
+func TestStatusShowsNote(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.NextStay = true
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	configuration.NextNote = "I was about to rename X"
+	next(gitRunner, configuration)
+	*output = ""
+
+	execute(gitRunner, "status", []string{}, withOutputJson(configuration))
+
+	var statusOutput statusJson
+	assertNoError(t, json.Unmarshal([]byte(*output), &statusOutput))
+	equals(t, &noteJson{Author: "local", Text: "I was about to rename X"}, statusOutput.Note)
+
+	*output = ""
+	status(gitRunner, configuration)
+	assertOutputContains(t, output, "I was about to rename X")
+}
+
+func TestStashHandoverWithNote(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.Handover = config.HandoverStash
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	configuration.NextNote = "I was about to rename X"
+	next(gitRunner, configuration)
+
+	setWorkingDir(tempDir + "/localother")
+	start(gitRunner, configuration)
+
+	assertOutputContains(t, output, "📝 note from local:")
+	assertOutputContains(t, output, "    I was about to rename X")
+}
+
+func TestHandoverNoteStartingWithAQuote(t *testing.T) {
+	note := `"WIP" rename X`
+
+	equals(t, `handoverNote:"\"WIP\" rename X"`, handoverNoteCommitMessage(note))
+	equals(t, note, handoverNoteFromCommitMessage("mob next\n\n"+handoverNoteCommitMessage(note)))
+	equals(t, note, handoverNoteFromCommitMessage("mob next\n\nhandoverNote:"+note))
+}
+
+func TestNextWithNoteFromEditorWithoutTerminal(t *testing.T) {
+	_, configuration := setup(t)
+	stdin, _, err := os.Pipe() // like the JSON-RPC stream of mob serve
+	assertNoError(t, err)
+	originalStdin := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = originalStdin }()
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	configuration.NextNoteEdit = true
+
+	err = next(gitRunner, configuration)
+
+	assertError(t, err, "the editor for the handover note failed")
+	assertFix(t, err, "mob next --note \"<note>\"")
+	assertOnBranch(t, "mob-session")
+	assertGitStatus(t, GitStatus{
+		"example.txt": "??",
+	})
+}
+
+func TestEditorCommandLine(t *testing.T) {
+	if !posixShell {
+		t.Skip("the test expects sh quoting")
+	}
+	equals(t, "code --wait '/repo/.git/MOB_NOTE_EDITMSG'", editorCommandLine("code --wait", "/repo/.git/MOB_NOTE_EDITMSG"))
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/notify"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// MOB_NOTIFY_BACKEND chooses how mob notifies you. dbus talks to the notification server of your desktop directly and
+// shows a title, an urgency and the button "Start my turn", command runs MOB_NOTIFY_COMMAND. auto uses dbus on Linux
+// unless you changed MOB_NOTIFY_COMMAND. If D-Bus fails, mob falls back to the command.
+
+const startMyTurnAction = "Start my turn"
+
+// notificationActionTimeout is how long a notification with an action keeps the background process waiting
+const notificationActionTimeout = 15 * time.Minute
+
+var dbusNotifier notify.Notifier = notify.DBus{AppName: "mob", ActionTimeout: notificationActionTimeout}
+
+// commandNotifier runs MOB_NOTIFY_COMMAND, it shows neither the title nor the urgency nor the action
+type commandNotifier struct {
+	runner        GitRunner
+	configuration config.Configuration
+}
+
+func (n commandNotifier) Notify(notification notify.Notification) error {
+	commandLine, err := injectCommandWithMessage(n.runner, n.configuration.NotifyCommand, notification.Message, n.configuration)
+	if err != nil || commandLine == "" {
+		return err
+	}
+	name, args := shellCommand(commandLine)
+	_, output, err := runCommandSilent(n.runner.Dir(), nil, name, args...)
+	if err != nil {
+		return &MobError{Kind: err, Message: "could not notify you with MOB_NOTIFY_COMMAND", Details: []string{err.Error(), output}}
+	}
+	return nil
+}
+
+// fallbackNotifier tries one notifier after the other
+type fallbackNotifier []notify.Notifier
+
+func (notifiers fallbackNotifier) Notify(notification notify.Notification) (err error) {
+	for _, notifier := range notifiers {
+		if err = notifier.Notify(notification); err == nil {
+			return nil
+		}
+		say.Debug("notifying failed, trying the next way: " + err.Error())
+	}
+	return err
+}
+
+func useDBusNotifier(configuration config.Configuration) bool {
+	switch configuration.NotifyBackend {
+	case config.NotifyBackendDBus:
+		return true
+	case config.NotifyBackendCommand:
+		return false
+	}
+	return runtime.GOOS == "linux" && configuration.NotifyCommand == config.GetDefaultConfiguration().NotifyCommand && notify.SessionBusAvailable()
+}
+
+func newNotifier(runner GitRunner, configuration config.Configuration) notify.Notifier {
+	if useDBusNotifier(configuration) {
+		return fallbackNotifier{dbusNotifier, commandNotifier{runner, configuration}}
+	}
+	return commandNotifier{runner, configuration}
+}
+
+// getNotifyCommand returns the command line the background process of the timer runs to notify you. Only a running
+// process can receive the click on "Start my turn", so with D-Bus the background process runs 'mob notify'.
+func getNotifyCommand(runner GitRunner, message string, startAction bool, configuration config.Configuration) (string, error) {
+	if !useDBusNotifier(configuration) {
+		return injectCommandWithMessage(runner, configuration.NotifyCommand, message, configuration)
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		say.Debug("falling back to MOB_NOTIFY_COMMAND: " + err.Error())
+		return injectCommandWithMessage(runner, configuration.NotifyCommand, message, configuration)
+	}
+	commandLine := escapeForShell(executable, 0) + " notify " + escapeForShell(message, 0)
+	if startAction {
+		commandLine += " --start"
+	}
+	return commandLine, nil
+}
+
+// notifyYou shows the message, with --start the notification has the button "Start my turn" which runs mob start
+func notifyYou(runner GitRunner, parameter []string, configuration config.Configuration) error {
+	if len(parameter) != 1 {
+		return newMobError(ErrInvalidArgument, "use "+configuration.Mob("notify <message> [--start]"))
+	}
+	notification := notify.Notification{Title: configuration.CliName, Message: parameter[0], Urgency: notify.Normal}
+	if configuration.WaitStart {
+		notification.Urgency = notify.Critical
+		notification.Action = startMyTurnAction
+		notification.OnAction = func() {
+			configuration.WaitStart = false
+			if err := execute(runner, "start", []string{}, configuration); err != nil {
+				sayError(err)
+			}
+		}
+	}
+	if err := newNotifier(runner, configuration).Notify(notification); err != nil {
+		say.Warning("could not notify you: " + strings.TrimSpace(err.Error()))
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/notify"
+)
+
+type recordingNotifier struct {
+	notifications *[]notify.Notification
+	err           error
+	click         bool
+}
+
+func (n recordingNotifier) Notify(notification notify.Notification) error {
+	*n.notifications = append(*n.notifications, notification)
+	if n.click && notification.OnAction != nil {
+		notification.OnAction()
+	}
+	return n.err
+}
+
+func mockDBusNotifier(t *testing.T, notifier notify.Notifier) {
+	originalDBusNotifier := dbusNotifier
+	dbusNotifier = notifier
+	t.Cleanup(func() { dbusNotifier = originalDBusNotifier })
+}
+
+func TestNotifyWithStartMyTurnStartsTheSession(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.NotifyBackend = config.NotifyBackendDBus
+	configuration.WaitStart = true
+	var notifications []notify.Notification
+	mockDBusNotifier(t, recordingNotifier{notifications: &notifications, click: true})
+
+	err := execute(gitRunner, "notify", []string{"mob start"}, configuration)
+
+	assertNoError(t, err)
+	equals(t, 1, len(notifications))
+	equals(t, "mob", notifications[0].Title)
+	equals(t, "mob start", notifications[0].Message)
+	equals(t, notify.Critical, notifications[0].Urgency)
+	equals(t, "Start my turn", notifications[0].Action)
+	assertOnBranch(t, "mob-session")
+}
+
+func TestNotifyWithoutAction(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.NotifyBackend = config.NotifyBackendDBus
+	var notifications []notify.Notification
+	mockDBusNotifier(t, recordingNotifier{notifications: &notifications})
+
+	err := execute(gitRunner, "notify", []string{"mob next"}, configuration)
+
+	assertNoError(t, err)
+	equals(t, notify.Normal, notifications[0].Urgency)
+	equals(t, "", notifications[0].Action)
+	assertOnBranch(t, "master")
+}
+
+func TestNotifyFallsBackToTheCommand(t *testing.T) {
+	if !posixShell {
+		t.Skip("the notify command in the test uses sh")
+	}
+	output, configuration := setup(t)
+	configuration.NotifyBackend = config.NotifyBackendDBus
+	configuration.NotifyCommand = "touch notified-{message}"
+	var notifications []notify.Notification
+	mockDBusNotifier(t, recordingNotifier{notifications: &notifications, err: errors.New("no session bus")})
+
+	err := execute(gitRunner, "notify", []string{"next"}, configuration)
+
+	assertNoError(t, err)
+	equals(t, 1, len(notifications))
+	assertFileExist(t, "notified-next")
+	assertOutputNotContains(t, output, "could not notify you")
+}
+
+func TestNotifyWithCommandBackend(t *testing.T) {
+	if !posixShell {
+		t.Skip("the notify command in the test uses sh")
+	}
+	_, configuration := setup(t)
+	configuration.NotifyBackend = config.NotifyBackendCommand
+	configuration.NotifyCommand = "touch notified"
+	var notifications []notify.Notification
+	mockDBusNotifier(t, recordingNotifier{notifications: &notifications})
+
+	err := execute(gitRunner, "notify", []string{"mob next"}, configuration)
+
+	assertNoError(t, err)
+	equals(t, 0, len(notifications))
+	assertFileExist(t, "notified")
+}
+
+func TestGetNotifyCommand(t *testing.T) {
+	configuration := config.GetDefaultConfiguration()
+	configuration.NotifyBackend = config.NotifyBackendCommand
+	configuration.NotifyCommand = "notify-send {message}"
+	command, err := getNotifyCommand(gitRunner, "mob start", true, configuration)
+	assertNoError(t, err)
+	equals(t, "notify-send 'mob start'", command)
+
+	configuration.NotifyBackend = config.NotifyBackendDBus
+	command, err = getNotifyCommand(gitRunner, "mob start", true, configuration)
+	assertNoError(t, err)
+	if !strings.HasSuffix(command, " notify 'mob start' --start") {
+		t.Error("expected the background process to run mob notify, got " + command)
+	}
+	command, err = getNotifyCommand(gitRunner, "mob next", false, configuration)
+	assertNoError(t, err)
+	if !strings.HasSuffix(command, " notify 'mob next'") {
+		t.Error("expected the background process to run mob notify, got " + command)
+	}
+}
+
+func TestUseDBusNotifier(t *testing.T) {
+	configuration := config.GetDefaultConfiguration()
+	configuration.NotifyBackend = config.NotifyBackendDBus
+	equals(t, true, useDBusNotifier(configuration))
+	configuration.NotifyBackend = config.NotifyBackendCommand
+	equals(t, false, useDBusNotifier(configuration))
+
+	configuration.NotifyBackend = config.NotifyBackendAuto
+	configuration.NotifyCommand = "my-notifier %s"
+	equals(t, false, useDBusNotifier(configuration))
+}
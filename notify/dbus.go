@@ -0,0 +1,475 @@
+package notify
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// DBus shows notifications via org.freedesktop.Notifications on the session bus, see
+// https://specifications.freedesktop.org/notification-spec/latest/ and https://dbus.freedesktop.org/doc/dbus-specification.html.
+// It speaks just enough of the D-Bus wire protocol for that, so mob needs neither a library nor notify-send.
+type DBus struct {
+	AppName string
+	// ActionTimeout is how long Notify waits for a click on the action
+	ActionTimeout time.Duration
+}
+
+const (
+	dbusMethodCall   = 1
+	dbusMethodReturn = 2
+	dbusError        = 3
+	dbusSignal       = 4
+)
+
+const (
+	dbusFieldPath        = 1
+	dbusFieldInterface   = 2
+	dbusFieldMember      = 3
+	dbusFieldErrorName   = 4
+	dbusFieldReplySerial = 5
+	dbusFieldDestination = 6
+	dbusFieldSignature   = 8
+)
+
+const (
+	notificationsName      = "org.freedesktop.Notifications"
+	notificationsPath      = "/org/freedesktop/Notifications"
+	notificationsInterface = "org.freedesktop.Notifications"
+	notificationsAction    = "mob-action"
+)
+
+// SessionBusAvailable tells whether there is a session bus to connect to
+func SessionBusAvailable() bool {
+	_, err := sessionBusAddress()
+	return err == nil
+}
+
+func (d DBus) Notify(notification Notification) error {
+	address, err := sessionBusAddress()
+	if err != nil {
+		return err
+	}
+	conn, err := dialDBus(address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if notification.Action != "" {
+		match := "type='signal',interface='" + notificationsInterface + "'"
+		if _, err := conn.call("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "AddMatch", "s", new(dbusEncoder).string(match)); err != nil {
+			return err
+		}
+	}
+
+	var actions []string
+	if notification.Action != "" {
+		actions = []string{notificationsAction, notification.Action}
+	}
+	body := new(dbusEncoder).
+		string(d.AppName).
+		uint32(0).  // replaces_id
+		string(""). // app_icon
+		string(notification.Title).
+		string(notification.Message).
+		stringArray(actions).
+		hints(map[string]byte{"urgency": byte(notification.Urgency)}).
+		int32(-1) // expire_timeout, the server decides
+	reply, err := conn.call(notificationsName, notificationsPath, notificationsInterface, "Notify", "susssasa{sv}i", body)
+	if err != nil {
+		return err
+	}
+	id, err := newDBusDecoder(reply.body, reply.order).uint32()
+	if err != nil {
+		return err
+	}
+	say.Debug("showed notification " + strconv.FormatUint(uint64(id), 10) + " via D-Bus")
+
+	if notification.Action == "" {
+		return nil
+	}
+	if d.ActionTimeout > 0 {
+		conn.conn.SetReadDeadline(time.Now().Add(d.ActionTimeout))
+	}
+	invoked, err := conn.waitForAction(id)
+	if err != nil {
+		return err
+	}
+	if invoked && notification.OnAction != nil {
+		notification.OnAction()
+	}
+	return nil
+}
+
+// sessionBusAddress returns the socket of the session bus, abstract sockets start with @
+func sessionBusAddress() (string, error) {
+	for _, address := range strings.Split(os.Getenv("DBUS_SESSION_BUS_ADDRESS"), ";") {
+		transport, parameters, _ := strings.Cut(address, ":")
+		if transport != "unix" {
+			continue
+		}
+		for _, parameter := range strings.Split(parameters, ",") {
+			key, value, _ := strings.Cut(parameter, "=")
+			value, err := url.PathUnescape(value)
+			if err != nil {
+				continue
+			}
+			switch key {
+			case "path":
+				return value, nil
+			case "abstract":
+				return "@" + value, nil
+			}
+		}
+	}
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		if _, err := os.Stat(runtimeDir + "/bus"); err == nil {
+			return runtimeDir + "/bus", nil
+		}
+	}
+	return "", errors.New("no D-Bus session bus found, DBUS_SESSION_BUS_ADDRESS isn't set")
+}
+
+type dbusConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	serial uint32
+}
+
+type dbusMessage struct {
+	kind        byte
+	order       binary.ByteOrder
+	serial      uint32
+	replySerial uint32
+	iface       string
+	member      string
+	errorName   string
+	signature   string
+	body        []byte
+}
+
+func dialDBus(address string) (*dbusConn, error) {
+	conn, err := net.Dial("unix", address)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to the D-Bus session bus: %w", err)
+	}
+	c := &dbusConn{conn: conn, reader: bufio.NewReader(conn)}
+	if err := c.authenticate(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if _, err := c.call("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "Hello", "", nil); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *dbusConn) Close() error {
+	return c.conn.Close()
+}
+
+// authenticate with the EXTERNAL mechanism, the bus checks our uid with the credentials of the socket
+func (c *dbusConn) authenticate() error {
+	uid := hex.EncodeToString([]byte(strconv.Itoa(os.Getuid())))
+	if _, err := c.conn.Write([]byte("\x00AUTH EXTERNAL " + uid + "\r\n")); err != nil {
+		return err
+	}
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("D-Bus authentication failed: %w", err)
+	}
+	if !strings.HasPrefix(line, "OK ") {
+		return errors.New("D-Bus authentication failed: " + strings.TrimSpace(line))
+	}
+	_, err = c.conn.Write([]byte("BEGIN\r\n"))
+	return err
+}
+
+// call sends a method call and returns its reply, skipping signals that arrive in between
+func (c *dbusConn) call(destination string, path string, iface string, member string, signature string, body *dbusEncoder) (*dbusMessage, error) {
+	c.serial++
+	var bodyBytes []byte
+	if body != nil {
+		bodyBytes = body.bytes
+	}
+	header := new(dbusEncoder)
+	header.byte('l').byte(dbusMethodCall).byte(0).byte(1).uint32(uint32(len(bodyBytes))).uint32(c.serial)
+	header.headerFields(func(fields *dbusEncoder) {
+		fields.headerField(dbusFieldPath, "o", path)
+		fields.headerField(dbusFieldDestination, "s", destination)
+		fields.headerField(dbusFieldInterface, "s", iface)
+		fields.headerField(dbusFieldMember, "s", member)
+		if signature != "" {
+			fields.headerField(dbusFieldSignature, "g", signature)
+		}
+	})
+	header.align(8)
+	if _, err := c.conn.Write(append(header.bytes, bodyBytes...)); err != nil {
+		return nil, err
+	}
+
+	for {
+		message, err := c.read()
+		if err != nil {
+			return nil, err
+		}
+		if message.replySerial != c.serial {
+			continue
+		}
+		if message.kind == dbusError {
+			text, _ := newDBusDecoder(message.body, message.order).string()
+			return nil, errors.New(member + " failed: " + message.errorName + " " + text)
+		}
+		return message, nil
+	}
+}
+
+// waitForAction returns true when the action of the notification was clicked and false when it was closed
+func (c *dbusConn) waitForAction(id uint32) (bool, error) {
+	for {
+		message, err := c.read()
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if message.kind != dbusSignal || message.iface != notificationsInterface {
+			continue
+		}
+		decoder := newDBusDecoder(message.body, message.order)
+		signalId, err := decoder.uint32()
+		if err != nil || signalId != id {
+			continue
+		}
+		switch message.member {
+		case "ActionInvoked":
+			key, err := decoder.string()
+			if err == nil && key == notificationsAction {
+				return true, nil
+			}
+		case "NotificationClosed":
+			return false, nil
+		}
+	}
+}
+
+func (c *dbusConn) read() (*dbusMessage, error) {
+	fixed := make([]byte, 16)
+	if _, err := io.ReadFull(c.reader, fixed); err != nil {
+		return nil, err
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if fixed[0] == 'B' {
+		order = binary.BigEndian
+	}
+	bodyLength := order.Uint32(fixed[4:8])
+	fieldsLength := order.Uint32(fixed[12:16])
+	headerLength := 16 + fieldsLength
+	headerLength += (8 - headerLength%8) % 8
+	rest := make([]byte, headerLength-16+bodyLength)
+	if _, err := io.ReadFull(c.reader, rest); err != nil {
+		return nil, err
+	}
+	data := append(fixed, rest...)
+
+	message := &dbusMessage{kind: fixed[1], order: order, serial: order.Uint32(fixed[8:12]), body: data[headerLength:]}
+	decoder := newDBusDecoder(data[:16+fieldsLength], order)
+	decoder.position = 16
+	for decoder.position < len(decoder.data) {
+		decoder.align(8)
+		code, err := decoder.byte()
+		if err != nil {
+			return nil, err
+		}
+		value, err := decoder.variant()
+		if err != nil {
+			return nil, err
+		}
+		switch code {
+		case dbusFieldInterface:
+			message.iface, _ = value.(string)
+		case dbusFieldMember:
+			message.member, _ = value.(string)
+		case dbusFieldErrorName:
+			message.errorName, _ = value.(string)
+		case dbusFieldReplySerial:
+			message.replySerial, _ = value.(uint32)
+		case dbusFieldSignature:
+			message.signature, _ = value.(string)
+		}
+	}
+	return message, nil
+}
+
+// dbusEncoder writes little endian values aligned as the D-Bus wire format requires
+type dbusEncoder struct {
+	bytes []byte
+}
+
+func (e *dbusEncoder) align(n int) *dbusEncoder {
+	for len(e.bytes)%n != 0 {
+		e.bytes = append(e.bytes, 0)
+	}
+	return e
+}
+
+func (e *dbusEncoder) byte(b byte) *dbusEncoder {
+	e.bytes = append(e.bytes, b)
+	return e
+}
+
+func (e *dbusEncoder) uint32(u uint32) *dbusEncoder {
+	e.align(4)
+	e.bytes = binary.LittleEndian.AppendUint32(e.bytes, u)
+	return e
+}
+
+func (e *dbusEncoder) int32(i int32) *dbusEncoder {
+	return e.uint32(uint32(i))
+}
+
+func (e *dbusEncoder) string(s string) *dbusEncoder {
+	e.uint32(uint32(len(s)))
+	e.bytes = append(append(e.bytes, s...), 0)
+	return e
+}
+
+func (e *dbusEncoder) signature(s string) *dbusEncoder {
+	e.bytes = append(append(append(e.bytes, byte(len(s))), s...), 0)
+	return e
+}
+
+// array writes the length, the padding to the alignment of the elements and the elements
+func (e *dbusEncoder) array(elementAlignment int, elements func()) *dbusEncoder {
+	e.uint32(0)
+	lengthPosition := len(e.bytes) - 4
+	e.align(elementAlignment)
+	start := len(e.bytes)
+	elements()
+	binary.LittleEndian.PutUint32(e.bytes[lengthPosition:], uint32(len(e.bytes)-start))
+	return e
+}
+
+func (e *dbusEncoder) stringArray(values []string) *dbusEncoder {
+	return e.array(4, func() {
+		for _, value := range values {
+			e.string(value)
+		}
+	})
+}
+
+// hints writes an a{sv} whose values are all bytes
+func (e *dbusEncoder) hints(hints map[string]byte) *dbusEncoder {
+	return e.array(8, func() {
+		for key, value := range hints {
+			e.align(8).string(key).signature("y").byte(value)
+		}
+	})
+}
+
+func (e *dbusEncoder) headerFields(fields func(fields *dbusEncoder)) *dbusEncoder {
+	return e.array(8, func() {
+		fields(e)
+	})
+}
+
+func (e *dbusEncoder) headerField(code byte, signature string, value string) {
+	e.align(8).byte(code).signature(signature)
+	if signature == "g" {
+		e.signature(value)
+	} else {
+		e.string(value)
+	}
+}
+
+type dbusDecoder struct {
+	data     []byte
+	position int
+	order    binary.ByteOrder
+}
+
+func newDBusDecoder(data []byte, order binary.ByteOrder) *dbusDecoder {
+	return &dbusDecoder{data: data, order: order}
+}
+
+var errDBusMessageTooShort = errors.New("D-Bus message too short")
+
+func (d *dbusDecoder) align(n int) {
+	d.position += (n - d.position%n) % n
+}
+
+func (d *dbusDecoder) byte() (byte, error) {
+	if d.position >= len(d.data) {
+		return 0, errDBusMessageTooShort
+	}
+	d.position++
+	return d.data[d.position-1], nil
+}
+
+func (d *dbusDecoder) uint32() (uint32, error) {
+	d.align(4)
+	if d.position+4 > len(d.data) {
+		return 0, errDBusMessageTooShort
+	}
+	d.position += 4
+	return d.order.Uint32(d.data[d.position-4:]), nil
+}
+
+func (d *dbusDecoder) string() (string, error) {
+	length, err := d.uint32()
+	if err != nil {
+		return "", err
+	}
+	return d.text(int(length))
+}
+
+func (d *dbusDecoder) signature() (string, error) {
+	length, err := d.byte()
+	if err != nil {
+		return "", err
+	}
+	return d.text(int(length))
+}
+
+// text reads length bytes and the terminating nul byte
+func (d *dbusDecoder) text(length int) (string, error) {
+	if d.position+length+1 > len(d.data) {
+		return "", errDBusMessageTooShort
+	}
+	text := string(d.data[d.position : d.position+length])
+	d.position += length + 1
+	return text, nil
+}
+
+// variant reads the basic types of header fields
+func (d *dbusDecoder) variant() (interface{}, error) {
+	signature, err := d.signature()
+	if err != nil {
+		return nil, err
+	}
+	switch signature {
+	case "s", "o":
+		return d.string()
+	case "g":
+		return d.signature()
+	case "u":
+		return d.uint32()
+	case "y":
+		return d.byte()
+	}
+	return nil, errors.New("unsupported D-Bus variant of type " + signature)
+}
@@ -0,0 +1,246 @@
+package notify
+
+import (
+	"bufio"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/remotemobprogramming/mob/v5/test"
+)
+
+func TestNotify(t *testing.T) {
+	bus := startFakeBus(t, "")
+
+	err := DBus{AppName: "mob"}.Notify(Notification{Title: "mob", Message: "mob next", Urgency: Critical})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	notification := <-bus.notifications
+	test.Equals(t, "mob", notification.appName)
+	test.Equals(t, "mob", notification.title)
+	test.Equals(t, "mob next", notification.message)
+	test.Equals(t, byte(Critical), notification.urgency)
+	test.Equals(t, 0, len(notification.actions))
+	test.Equals(t, []string{"Hello", "Notify"}, bus.members())
+}
+
+func TestNotifyRunsTheClickedAction(t *testing.T) {
+	bus := startFakeBus(t, "ActionInvoked")
+	clicked := false
+
+	err := DBus{AppName: "mob"}.Notify(Notification{Title: "mob", Message: "mob start", Action: "Start my turn", OnAction: func() { clicked = true }})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Equals(t, true, clicked)
+	test.Equals(t, []string{notificationsAction, "Start my turn"}, (<-bus.notifications).actions)
+	test.Equals(t, []string{"Hello", "AddMatch", "Notify"}, bus.members())
+}
+
+func TestNotifyDoesNotRunTheActionOfAClosedNotification(t *testing.T) {
+	startFakeBus(t, "NotificationClosed")
+	clicked := false
+
+	err := DBus{AppName: "mob"}.Notify(Notification{Message: "mob start", Action: "Start my turn", OnAction: func() { clicked = true }})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Equals(t, false, clicked)
+}
+
+func TestNotifyStopsWaitingForTheActionAfterTheTimeout(t *testing.T) {
+	startFakeBus(t, "")
+	clicked := false
+
+	err := DBus{AppName: "mob", ActionTimeout: 50 * time.Millisecond}.Notify(Notification{Message: "mob start", Action: "Start my turn", OnAction: func() { clicked = true }})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Equals(t, false, clicked)
+}
+
+func TestNotifyWithoutNotificationServer(t *testing.T) {
+	bus := startFakeBus(t, "")
+	bus.notifyError = "org.freedesktop.DBus.Error.ServiceUnknown"
+
+	err := DBus{AppName: "mob"}.Notify(Notification{Message: "mob next"})
+
+	if err == nil || !strings.Contains(err.Error(), "Notify failed: org.freedesktop.DBus.Error.ServiceUnknown") {
+		t.Error("expected the error of the bus, got", err)
+	}
+}
+
+func TestNotifyWithoutSessionBus(t *testing.T) {
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "")
+	t.Setenv("XDG_RUNTIME_DIR", "")
+
+	err := DBus{AppName: "mob"}.Notify(Notification{Message: "mob next"})
+
+	if err == nil {
+		t.Error("expected an error without a session bus")
+	}
+	test.Equals(t, false, SessionBusAvailable())
+}
+
+func TestSessionBusAddress(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	for address, expected := range map[string]string{
+		"unix:path=/run/user/1000/bus":                      "/run/user/1000/bus",
+		"unix:abstract=/tmp/dbus-XYZ,guid=0123":             "@/tmp/dbus-XYZ",
+		"tcp:host=localhost,port=1;unix:path=/tmp/my%20bus": "/tmp/my bus",
+	} {
+		t.Setenv("DBUS_SESSION_BUS_ADDRESS", address)
+		actual, err := sessionBusAddress()
+		if err != nil {
+			t.Fatal(err)
+		}
+		test.Equals(t, expected, actual)
+	}
+}
+
+type fakeNotification struct {
+	appName string
+	title   string
+	message string
+	actions []string
+	urgency byte
+}
+
+type fakeBus struct {
+	t             *testing.T
+	signal        string // the signal the bus sends after Notify
+	notifyError   string
+	notifications chan fakeNotification
+	calls         chan string
+}
+
+// startFakeBus serves one connection like the session bus with a notification server
+func startFakeBus(t *testing.T, signal string) *fakeBus {
+	path := t.TempDir() + "/bus"
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "unix:path="+path+",guid=0123")
+
+	bus := &fakeBus{t: t, signal: signal, notifications: make(chan fakeNotification, 1), calls: make(chan string, 10)}
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		bus.serve(&dbusConn{conn: conn, reader: bufio.NewReader(conn)})
+	}()
+	return bus
+}
+
+func (b *fakeBus) members() []string {
+	var members []string
+	for {
+		select {
+		case member := <-b.calls:
+			members = append(members, member)
+		case <-time.After(100 * time.Millisecond):
+			return members
+		}
+	}
+}
+
+func (b *fakeBus) serve(c *dbusConn) {
+	auth, _ := c.reader.ReadString('\n')
+	if !strings.HasPrefix(auth, "\x00AUTH EXTERNAL ") {
+		b.t.Error("unexpected authentication", auth)
+		return
+	}
+	c.conn.Write([]byte("OK 0123\r\n"))
+	if begin, _ := c.reader.ReadString('\n'); begin != "BEGIN\r\n" {
+		b.t.Error("unexpected begin", begin)
+		return
+	}
+
+	for {
+		message, err := c.read()
+		if err != nil {
+			return
+		}
+		b.calls <- message.member
+		switch message.member {
+		case "Hello":
+			b.send(c, dbusMethodReturn, message.serial, "", "", "s", new(dbusEncoder).string(":1.42"))
+		case "AddMatch":
+			b.send(c, dbusMethodReturn, message.serial, "", "", "", new(dbusEncoder))
+		case "Notify":
+			if b.notifyError != "" {
+				b.send(c, dbusError, message.serial, b.notifyError, "", "s", new(dbusEncoder).string("no notification server"))
+				continue
+			}
+			b.notifications <- decodeNotification(message)
+			b.send(c, dbusMethodReturn, message.serial, "", "", "u", new(dbusEncoder).uint32(7))
+			switch b.signal {
+			case "ActionInvoked":
+				b.send(c, dbusSignal, 0, "", "ActionInvoked", "us", new(dbusEncoder).uint32(7).string(notificationsAction))
+			case "NotificationClosed":
+				b.send(c, dbusSignal, 0, "", "NotificationClosed", "uu", new(dbusEncoder).uint32(7).uint32(2))
+			}
+		}
+	}
+}
+
+func (b *fakeBus) send(c *dbusConn, kind byte, replySerial uint32, errorName string, member string, signature string, body *dbusEncoder) {
+	header := new(dbusEncoder)
+	header.byte('l').byte(kind).byte(0).byte(1).uint32(uint32(len(body.bytes))).uint32(100)
+	header.headerFields(func(fields *dbusEncoder) {
+		if replySerial != 0 {
+			fields.align(8).byte(dbusFieldReplySerial).signature("u").uint32(replySerial)
+		}
+		if errorName != "" {
+			fields.headerField(dbusFieldErrorName, "s", errorName)
+		}
+		if member != "" {
+			fields.headerField(dbusFieldPath, "o", notificationsPath)
+			fields.headerField(dbusFieldInterface, "s", notificationsInterface)
+			fields.headerField(dbusFieldMember, "s", member)
+		}
+		if signature != "" {
+			fields.headerField(dbusFieldSignature, "g", signature)
+		}
+	})
+	header.align(8)
+	c.conn.Write(append(header.bytes, body.bytes...))
+}
+
+// decodeNotification reads the arguments susssasa{sv}i of Notify
+func decodeNotification(message *dbusMessage) fakeNotification {
+	decoder := newDBusDecoder(message.body, binary.LittleEndian)
+	var notification fakeNotification
+	notification.appName, _ = decoder.string()
+	decoder.uint32()
+	decoder.string()
+	notification.title, _ = decoder.string()
+	notification.message, _ = decoder.string()
+	actionsLength, _ := decoder.uint32()
+	for end := decoder.position + int(actionsLength); decoder.position < end; {
+		action, _ := decoder.string()
+		notification.actions = append(notification.actions, action)
+	}
+	hintsLength, _ := decoder.uint32()
+	decoder.align(8)
+	for end := decoder.position + int(hintsLength); decoder.position < end; {
+		decoder.align(8)
+		key, _ := decoder.string()
+		value, _ := decoder.variant()
+		if key == "urgency" {
+			notification.urgency, _ = value.(byte)
+		}
+	}
+	return notification
+}
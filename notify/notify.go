@@ -0,0 +1,23 @@
+package notify
+
+// Urgency tells the notification server how important a notification is, critical ones usually stay until dismissed
+type Urgency byte
+
+const (
+	Low      Urgency = 0
+	Normal   Urgency = 1
+	Critical Urgency = 2
+)
+
+type Notification struct {
+	Title   string
+	Message string
+	Urgency Urgency
+	// Action is the label of a button, OnAction runs when it is clicked. Without an action Notify returns right away.
+	Action   string
+	OnAction func()
+}
+
+type Notifier interface {
+	Notify(notification Notification) error
+}
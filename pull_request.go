@@ -0,0 +1,249 @@
+package main
+
+import (
+	"errors"
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/goal"
+	"github.com/remotemobprogramming/mob/v5/pullrequest"
+	"github.com/remotemobprogramming/mob/v5/say"
+	"strings"
+)
+
+var newPullRequestProvider = func(runner GitRunner, configuration config.Configuration) (pullrequest.Provider, error) {
+	remoteUrl, _ := silentgitignorefailure(runner, "remote", "get-url", configuration.RemoteName)
+	return pullrequest.New(configuration, remoteUrl, runner.Dir())
+}
+
+// pullRequestStep is one step of done --pull-request. A dry-run prints the descriptions of the steps instead of
+// running them, so it shows exactly what a real run would do.
+type pullRequestStep struct {
+	description string
+	run         func() error
+}
+
+func gitStep(runner GitRunner, args ...string) pullRequestStep {
+	args = deleteEmptyStrings(args)
+	return pullRequestStep{"git " + strings.Join(args, " "), func() error {
+		return git(runner, args...)
+	}}
+}
+
+func donePullRequest(runner GitRunner, configuration config.Configuration) error {
+	provider, err := newPullRequestProvider(runner, configuration)
+	if err != nil {
+		return err
+	}
+
+	if err := git(runner, "fetch", configuration.RemoteName, "--prune"); err != nil {
+		return err
+	}
+
+	baseBranch, wipBranch, err := currentBranches(runner, configuration)
+	if err != nil {
+		return err
+	}
+	featureBranch := pullRequestBranch(wipBranch, configuration)
+	if configuration.DoneSquash == config.Squash {
+		sessionChanges, err := hasSessionChanges(runner, baseBranch, wipBranch, configuration)
+		if err != nil {
+			return err
+		}
+		if !sessionChanges {
+			say.Info("nothing was done, so nothing to create a pull request for")
+			return nil
+		}
+	}
+
+	steps, url, err := pullRequestSteps(runner, provider, baseBranch, wipBranch, featureBranch, configuration)
+	if err != nil {
+		return err
+	}
+	for _, step := range steps {
+		if DryRun {
+			say.Indented(step.description)
+			continue
+		}
+		if err := step.run(); err != nil {
+			return err
+		}
+	}
+	if DryRun {
+		return nil
+	}
+
+	say.Info("created pull request from " + featureBranch.Name + " into " + baseBranch.Name)
+	if *url != "" {
+		say.Next("To review and merge, open", *url)
+	}
+	return nil
+}
+
+// pullRequestSteps returns the steps from the wip branch to the pull request, url is set once the pull request exists
+func pullRequestSteps(runner GitRunner, provider pullrequest.Provider, baseBranch Branch, wipBranch Branch, featureBranch Branch, configuration config.Configuration) (steps []pullRequestStep, url *string, err error) {
+	coauthors := sessionCoauthors(runner, baseBranch, wipBranch, configuration)
+	title, body := pullRequestTitleAndBody(configuration, featureBranch, coauthors)
+	commitMessage := finalCommitMessage(title, coauthors)
+	url = new(string)
+	hasRemoteWipBranch, err := wipBranch.hasRemoteBranch(runner, configuration)
+	if err != nil {
+		return nil, nil, err
+	}
+	uncommittedChanges, err := hasUncommittedChanges(runner)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if hasRemoteWipBranch {
+		steps = append(steps, gitStep(runner, "merge", wipBranch.remote(configuration).Name, "--ff-only"))
+	}
+	switch configuration.DoneSquash {
+	case config.Squash:
+		if uncommittedChanges {
+			steps = append(steps, wipCommitStep(runner, configuration))
+		}
+		featureHead := gitRefHash(runner, "refs/heads/"+featureBranch.Name)
+		steps = append(steps,
+			gitStep(runner, "checkout", "-B", featureBranch.Name, baseBranch.remote(configuration).Name),
+			pullRequestStep{"git merge --squash " + wipBranch.Name, func() error {
+				err := git(runner, "merge", "--squash", wipBranch.Name)
+				if errors.Is(err, ErrMergeConflict) {
+					return abortSquashMerge(runner, configuration, baseBranch, wipBranch, featureBranch, featureHead)
+				}
+				return err
+			}},
+			gitStep(runner, "commit", "--message", commitMessage, gitHooksOption(configuration)))
+	case config.SquashWip:
+		steps = append(steps,
+			pullRequestStep{"squash the wip commits of " + wipBranch.Name + " and commit the remaining changes as '" + title + "'", func() error {
+				if err := squashWip(runner, configuration); err != nil {
+					return err
+				}
+				if uncommittedChanges, err := hasUncommittedChanges(runner); err != nil || !uncommittedChanges {
+					return err
+				}
+				return gitWithoutEmptyStrings(runner, "commit", "--message", commitMessage, gitHooksOption(configuration))
+			}},
+			gitStep(runner, "checkout", "-B", featureBranch.Name, wipBranch.Name))
+	default:
+		if uncommittedChanges {
+			steps = append(steps, wipCommitStep(runner, configuration))
+		}
+		steps = append(steps, gitStep(runner, "checkout", "-B", featureBranch.Name, wipBranch.Name))
+	}
+	steps = append(steps,
+		gitStep(runner, "push", gitHooksOption(configuration), "--set-upstream", configuration.RemoteName, featureBranch.Name),
+		pullRequestStep{"create pull request '" + title + "' from " + featureBranch.Name + " into " + baseBranch.Name, func() error {
+			created, err := provider.Create(pullrequest.PullRequest{
+				Title: title,
+				Body:  body,
+				Head:  featureBranch.Name,
+				Base:  baseBranch.Name,
+			})
+			if err != nil {
+				say.Warning("All changes are on branch " + featureBranch.Name + " which was pushed to " + configuration.RemoteName + ". The wip branch " + wipBranch.Name + " was kept.")
+				say.Warning("To finish, create a pull request from " + featureBranch.Name + " into " + baseBranch.Name + " manually.")
+				return &MobError{Kind: err, Message: "could not create pull request: " + err.Error()}
+			}
+			*url = created
+			return nil
+		}},
+		gitStep(runner, "checkout", baseBranch.Name),
+		gitStep(runner, "branch", "-D", wipBranch.Name))
+	if hasRemoteWipBranch {
+		steps = append(steps, gitStep(runner, "push", gitHooksOption(configuration), configuration.RemoteName, "--delete", wipBranch.Name))
+	}
+	return steps, url, nil
+}
+
+// abortSquashMerge drops the half-merged feature branch and takes you back to the wip branch to resolve the conflicts there
+func abortSquashMerge(runner GitRunner, configuration config.Configuration, baseBranch Branch, wipBranch Branch, featureBranch Branch, featureHead string) error {
+	if err := git(runner, "reset", "--hard"); err != nil { // merge --squash leaves no MERGE_HEAD, so merge --abort doesn't work
+		return err
+	}
+	if err := git(runner, "checkout", wipBranch.Name); err != nil {
+		return err
+	}
+	var err error
+	if featureHead == "" {
+		err = git(runner, "branch", "-D", featureBranch.Name)
+	} else {
+		err = git(runner, "branch", "--force", featureBranch.Name, featureHead)
+	}
+	if err != nil {
+		return err
+	}
+	return newMobError(ErrMergeConflict, "cannot create the pull request; "+wipBranch.Name+" conflicts with "+baseBranch.remote(configuration).Name+", you are back on the wip branch",
+		Fix{"To resolve the conflicts on the wip branch, merge the base branch and try again", "git merge " + baseBranch.remote(configuration).Name})
+}
+
+func wipCommitStep(runner GitRunner, configuration config.Configuration) pullRequestStep {
+	return pullRequestStep{"commit your uncommitted changes as '" + configuration.WipCommitMessage + "'", func() error {
+		return makeWipCommit(runner, configuration)
+	}}
+}
+
+// hasSessionChanges tells whether the wip branch and the uncommitted changes change anything on the base branch
+func hasSessionChanges(runner GitRunner, baseBranch Branch, wipBranch Branch, configuration config.Configuration) (bool, error) {
+	if uncommittedChanges, err := hasUncommittedChanges(runner); err != nil || uncommittedChanges {
+		return uncommittedChanges, err
+	}
+	heads := []string{wipBranch.Name}
+	hasRemoteWipBranch, err := wipBranch.hasRemoteBranch(runner, configuration)
+	if err != nil {
+		return false, err
+	}
+	if hasRemoteWipBranch {
+		heads = append(heads, wipBranch.remote(configuration).Name)
+	}
+	for _, head := range heads {
+		if _, _, err := runner.Query("diff", "--quiet", baseBranch.remote(configuration).Name+"..."+head, "--"); err != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func pullRequestBranch(wipBranch Branch, configuration config.Configuration) Branch {
+	return newBranch(configuration.PullRequestBranchPrefix + wipBranch.removeWipPrefix(configuration).Name)
+}
+
+func sessionCoauthors(runner GitRunner, baseBranch Branch, wipBranch Branch, configuration config.Configuration) []Author {
+	args := []string{"--no-pager", "log", "--reverse", "--pretty=format:%an <%ae>", "^" + baseBranch.remote(configuration).Name, wipBranch.Name}
+	if hasRemoteBranch, _ := wipBranch.hasRemoteBranch(runner, configuration); hasRemoteBranch {
+		args = append(args, wipBranch.remote(configuration).Name) // the local wip branch may be behind before done merges it
+	}
+	authors, err := silentgitignorefailure(runner, args...)
+	if err != nil || authors == "" {
+		return nil
+	}
+	userEmail, _ := gitUserEmail(runner)
+	coauthors := removeElementsContaining(strings.Split(authors, "\n"), userEmail)
+	return removeDuplicateValues(coauthors)
+}
+
+func pullRequestTitleAndBody(configuration config.Configuration, featureBranch Branch, coauthors []Author) (title string, body string) {
+	currentGoal, err := goal.CurrentGoal(configuration)
+	if err != nil {
+		say.Debug("could not get goal: " + err.Error())
+	}
+
+	title = "Mob session " + featureBranch.Name
+	if configuration.DoneCommitMessage != "" {
+		title = strings.SplitN(configuration.DoneCommitMessage, "\n", 2)[0]
+	} else if currentGoal != "" {
+		title = currentGoal
+	}
+
+	if currentGoal != "" {
+		body += "Goal: " + currentGoal + "\n\n"
+	}
+	body += coauthorTrailers(coauthors)
+	return title, strings.TrimSpace(body)
+}
+
+func finalCommitMessage(title string, coauthors []Author) string {
+	if len(coauthors) == 0 {
+		return title
+	}
+	return title + "\n\n" + coauthorTrailers(coauthors)
+}
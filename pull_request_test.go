@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/pullrequest"
+	"testing"
+)
+
+type fakePullRequestProvider struct {
+	created []pullrequest.PullRequest
+	err     error
+}
+
+func (p *fakePullRequestProvider) Create(pullRequest pullrequest.PullRequest) (string, error) {
+	if p.err != nil {
+		return "", p.err
+	}
+	p.created = append(p.created, pullRequest)
+	return "https://example.com/pulls/1", nil
+}
+
+func mockPullRequestProvider(t *testing.T) *fakePullRequestProvider {
+	provider := &fakePullRequestProvider{}
+	originalPullRequestProvider := newPullRequestProvider
+	newPullRequestProvider = func(runner GitRunner, configuration config.Configuration) (pullrequest.Provider, error) {
+		return provider, nil
+	}
+	t.Cleanup(func() {
+		newPullRequestProvider = originalPullRequestProvider
+	})
+	return provider
+}
+
+func TestDonePullRequestSquash(t *testing.T) {
+	output, configuration := setup(t)
+	provider := mockPullRequestProvider(t)
+
+	setWorkingDir(tempDir + "/alice")
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+
+	setWorkingDir(tempDir + "/bob")
+	start(gitRunner, configuration)
+	createFile(t, "example2.txt", "contentIrrelevant")
+	configuration.DonePullRequest = true
+	assertNoError(t, done(gitRunner, configuration))
+
+	assertOnBranch(t, "master")
+	assertCleanGitStatus(t)
+	assertCommitsOnBranch(t, 1, "master")
+	assertCommitsOnBranch(t, 2, "origin/feature/mob-session")
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+	equals(t, []pullrequest.PullRequest{{
+		Title: "Mob session feature/mob-session",
+		Body:  "Co-authored-by: alice <alice@example.com>",
+		Head:  "feature/mob-session",
+		Base:  "master",
+	}}, provider.created)
+	equals(t, "Mob session feature/mob-session\n\nCo-authored-by: alice <alice@example.com>", gitOutput(t, "log", "-1", "--pretty=format:%B", "origin/feature/mob-session"))
+	assertOutputContains(t, output, "https://example.com/pulls/1")
+}
+
+func TestDonePullRequestNoSquash(t *testing.T) {
+	_, configuration := setup(t)
+	provider := mockPullRequestProvider(t)
+	configuration.DoneSquash = config.NoSquash
+	configuration.PullRequestBranchPrefix = "mob-done/"
+	configuration.DoneCommitMessage = "create examples"
+
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+	start(gitRunner, configuration)
+	createFile(t, "example2.txt", "contentIrrelevant")
+	configuration.DonePullRequest = true
+	assertNoError(t, done(gitRunner, configuration))
+
+	assertOnBranch(t, "master")
+	assertCommitsOnBranch(t, 3, "origin/mob-done/mob-session")
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+	equals(t, "create examples", provider.created[0].Title)
+	equals(t, "mob-done/mob-session", provider.created[0].Head)
+}
+
+func TestDonePullRequestKeepsWipBranchIfProviderFails(t *testing.T) {
+	output, configuration := setup(t)
+	provider := mockPullRequestProvider(t)
+	provider.err = errors.New("unauthorized")
+
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+	start(gitRunner, configuration)
+	configuration.DonePullRequest = true
+
+	assertError(t, done(gitRunner, configuration), "could not create pull request: unauthorized")
+	assertOutputContains(t, output, "create a pull request from feature/mob-session into master manually")
+	assertMobSessionBranches(t, configuration, "mob-session")
+	assertCommitsOnBranch(t, 2, "origin/feature/mob-session")
+}
+
+func TestDonePullRequestSquashMergeConflict(t *testing.T) {
+	_, configuration := setupDoneMergeConflict(t)
+	provider := mockPullRequestProvider(t)
+	wipHead := gitCommitHash(gitRunner)
+	configuration.DonePullRequest = true
+
+	err := done(gitRunner, configuration)
+
+	assertErrorIs(t, err, ErrMergeConflict)
+	assertFix(t, err, "git merge origin/master")
+	assertOnBranch(t, "mob-session")
+	assertCleanGitStatus(t)
+	equals(t, wipHead, gitCommitHash(gitRunner))
+	equals(t, false, hasLocalBranch(t, newBranch("feature/mob-session")))
+	equals(t, 0, len(provider.created))
+}
+
+func TestDonePullRequestDryRun(t *testing.T) {
+	output, configuration := setup(t)
+	provider := mockPullRequestProvider(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+	start(gitRunner, configuration)
+	configuration.DonePullRequest = true
+	enableDryRun(t)
+
+	assertNoError(t, done(gitRunner, configuration))
+
+	assertOutputContains(t, output, "git merge --squash mob-session")
+	assertOutputContains(t, output, "git push --no-verify --set-upstream origin feature/mob-session")
+	assertOutputContains(t, output, "create pull request 'Mob session feature/mob-session' from feature/mob-session into master")
+	assertOutputContains(t, output, "git push --no-verify origin --delete mob-session")
+	assertOutputNotContains(t, output, "nothing was done")
+	equals(t, 0, len(provider.created))
+	assertOnBranch(t, "mob-session")
+	assertMobSessionBranches(t, configuration, "mob-session")
+}
+
+func TestDonePullRequestNothingDone(t *testing.T) {
+	output, configuration := setup(t)
+	provider := mockPullRequestProvider(t)
+	start(gitRunner, configuration)
+	configuration.DonePullRequest = true
+
+	assertNoError(t, done(gitRunner, configuration))
+
+	assertOutputContains(t, output, "nothing was done, so nothing to create a pull request for")
+	equals(t, 0, len(provider.created))
+	assertOnBranch(t, "mob-session")
+}
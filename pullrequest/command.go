@@ -0,0 +1,41 @@
+package pullrequest
+
+import (
+	"errors"
+	"github.com/remotemobprogramming/mob/v5/say"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// CommandProvider runs a user defined command, e.g. 'gh pr create --fill', and passes the pull request via
+// environment variables. The last line of its output is taken as the url of the pull request.
+type CommandProvider struct {
+	Command string
+	Dir     string
+}
+
+func (p CommandProvider) Create(pullRequest PullRequest) (string, error) {
+	var command *exec.Cmd
+	if runtime.GOOS == "windows" {
+		command = exec.Command("powershell", "-command", p.Command)
+	} else {
+		command = exec.Command("sh", "-c", p.Command)
+	}
+	command.Dir = p.Dir
+	command.Env = append(os.Environ(),
+		"MOB_PULL_REQUEST_TITLE="+pullRequest.Title,
+		"MOB_PULL_REQUEST_BODY="+pullRequest.Body,
+		"MOB_PULL_REQUEST_HEAD="+pullRequest.Head,
+		"MOB_PULL_REQUEST_BASE="+pullRequest.Base,
+	)
+	say.Debug("Running pull request command <" + p.Command + ">")
+	output, err := command.CombinedOutput()
+	say.Debug(string(output))
+	if err != nil {
+		return "", errors.New("pull request command failed: " + err.Error() + "\n" + string(output))
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1]), nil
+}
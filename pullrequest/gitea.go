@@ -0,0 +1,21 @@
+package pullrequest
+
+type GiteaProvider struct {
+	ApiUrl     string
+	Token      string
+	Repository Repository
+}
+
+func (p GiteaProvider) Create(pullRequest PullRequest) (string, error) {
+	// the gitea api is compatible with the github api for creating pull requests
+	var response gitHubPullRequestResponse
+	err := postJson(p.ApiUrl+"/repos/"+p.Repository.Path()+"/pulls", map[string]string{
+		"Authorization": "token " + p.Token,
+	}, gitHubPullRequest{
+		Title: pullRequest.Title,
+		Body:  pullRequest.Body,
+		Head:  pullRequest.Head,
+		Base:  pullRequest.Base,
+	}, &response)
+	return response.HtmlUrl, err
+}
@@ -0,0 +1,32 @@
+package pullrequest
+
+type GitHubProvider struct {
+	ApiUrl     string
+	Token      string
+	Repository Repository
+}
+
+type gitHubPullRequest struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	Head  string `json:"head"`
+	Base  string `json:"base"`
+}
+
+type gitHubPullRequestResponse struct {
+	HtmlUrl string `json:"html_url"`
+}
+
+func (p GitHubProvider) Create(pullRequest PullRequest) (string, error) {
+	var response gitHubPullRequestResponse
+	err := postJson(p.ApiUrl+"/repos/"+p.Repository.Path()+"/pulls", map[string]string{
+		"Accept":        "application/vnd.github+json",
+		"Authorization": "Bearer " + p.Token,
+	}, gitHubPullRequest{
+		Title: pullRequest.Title,
+		Body:  pullRequest.Body,
+		Head:  pullRequest.Head,
+		Base:  pullRequest.Base,
+	}, &response)
+	return response.HtmlUrl, err
+}
@@ -0,0 +1,33 @@
+package pullrequest
+
+import "net/url"
+
+type GitLabProvider struct {
+	ApiUrl     string
+	Token      string
+	Repository Repository
+}
+
+type gitLabMergeRequest struct {
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+}
+
+type gitLabMergeRequestResponse struct {
+	WebUrl string `json:"web_url"`
+}
+
+func (p GitLabProvider) Create(pullRequest PullRequest) (string, error) {
+	var response gitLabMergeRequestResponse
+	err := postJson(p.ApiUrl+"/projects/"+url.PathEscape(p.Repository.Path())+"/merge_requests", map[string]string{
+		"PRIVATE-TOKEN": p.Token,
+	}, gitLabMergeRequest{
+		Title:        pullRequest.Title,
+		Description:  pullRequest.Body,
+		SourceBranch: pullRequest.Head,
+		TargetBranch: pullRequest.Base,
+	}, &response)
+	return response.WebUrl, err
+}
@@ -0,0 +1,149 @@
+package pullrequest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/httpclient"
+	"github.com/remotemobprogramming/mob/v5/say"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	GitHub  = "github"
+	GitLab  = "gitlab"
+	Gitea   = "gitea"
+	Command = "command"
+)
+
+type PullRequest struct {
+	Title string
+	Body  string
+	Head  string
+	Base  string
+}
+
+type Provider interface {
+	Create(pullRequest PullRequest) (url string, err error)
+}
+
+type Repository struct {
+	Host  string
+	Owner string
+	Name  string
+}
+
+func (r Repository) Path() string {
+	return r.Owner + "/" + r.Name
+}
+
+// New creates the provider configured with MOB_PULL_REQUEST_PROVIDER. If no provider is configured, it is
+// guessed from the host of the remote url.
+func New(configuration config.Configuration, remoteUrl string, workingDir string) (Provider, error) {
+	if configuration.PullRequestProvider == Command {
+		if strings.TrimSpace(configuration.PullRequestCommand) == "" {
+			return nil, errors.New("no pull request command configured. Set MOB_PULL_REQUEST_COMMAND in your ~/.mob file.")
+		}
+		return CommandProvider{Command: configuration.PullRequestCommand, Dir: workingDir}, nil
+	}
+
+	repository, err := ParseRemoteUrl(remoteUrl)
+	if err != nil {
+		return nil, err
+	}
+	provider := configuration.PullRequestProvider
+	if provider == "" {
+		provider = guessProvider(repository.Host)
+	}
+	token := configuration.PullRequestToken
+	switch provider {
+	case GitHub:
+		return GitHubProvider{ApiUrl: apiUrl(configuration, "https://"+repository.Host+"/api/v3", repository), Token: token, Repository: repository}, nil
+	case GitLab:
+		return GitLabProvider{ApiUrl: apiUrl(configuration, "https://"+repository.Host+"/api/v4", repository), Token: token, Repository: repository}, nil
+	case Gitea:
+		return GiteaProvider{ApiUrl: apiUrl(configuration, "https://"+repository.Host+"/api/v1", repository), Token: token, Repository: repository}, nil
+	case "":
+		return nil, errors.New("could not detect the pull request provider for " + repository.Host + ". Set MOB_PULL_REQUEST_PROVIDER to one of github, gitlab, gitea or command.")
+	default:
+		return nil, errors.New("unknown pull request provider '" + provider + "'. Set MOB_PULL_REQUEST_PROVIDER to one of github, gitlab, gitea or command.")
+	}
+}
+
+func guessProvider(host string) string {
+	switch {
+	case host == "github.com":
+		return GitHub
+	case strings.Contains(host, "gitlab"):
+		return GitLab
+	case strings.Contains(host, "gitea"), host == "codeberg.org":
+		return Gitea
+	default:
+		return ""
+	}
+}
+
+func apiUrl(configuration config.Configuration, defaultUrl string, repository Repository) string {
+	if configuration.PullRequestUrl != "" {
+		return strings.TrimSuffix(configuration.PullRequestUrl, "/")
+	}
+	if repository.Host == "github.com" {
+		return "https://api.github.com"
+	}
+	return defaultUrl
+}
+
+// ParseRemoteUrl supports scp-like ssh urls (git@host:owner/name.git) and ssh/http(s) urls. Nested groups
+// (GitLab) end up in Owner.
+func ParseRemoteUrl(remoteUrl string) (Repository, error) {
+	r := regexp.MustCompile(`^(?:(?:ssh|git|https?)://)?(?:[^@/]+@)?(?P<host>[^:/]+)(?::\d+)?[:/](?P<path>.+?)(?:\.git)?/?$`)
+	matches := r.FindStringSubmatch(strings.TrimSpace(remoteUrl))
+	if matches == nil || strings.HasPrefix(remoteUrl, "file://") {
+		return Repository{}, errors.New("could not determine repository from remote url '" + remoteUrl + "'")
+	}
+	path := matches[r.SubexpIndex("path")]
+	separator := strings.LastIndex(path, "/")
+	if separator <= 0 {
+		return Repository{}, errors.New("could not determine repository from remote url '" + remoteUrl + "'")
+	}
+	return Repository{
+		Host:  matches[r.SubexpIndex("host")],
+		Owner: path[:separator],
+		Name:  path[separator+1:],
+	}, nil
+}
+
+func postJson(url string, headers map[string]string, requestBody interface{}, response interface{}) error {
+	body, err := json.Marshal(requestBody)
+	if err != nil {
+		return err
+	}
+	say.Debug("POST " + url + " " + string(body))
+	request, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to create the http request object: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+	httpResponse, err := httpclient.GetNetHttpClient(false).Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to make the http request: %w", err)
+	}
+	defer httpResponse.Body.Close()
+	responseBody, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read the http response: %w", err)
+	}
+	say.Debug(string(responseBody))
+	if httpResponse.StatusCode >= 300 {
+		return errors.New("got an error from the server: " + url + " " + httpResponse.Status + " " + string(responseBody))
+	}
+	return json.Unmarshal(responseBody, response)
+}
@@ -0,0 +1,117 @@
+package pullrequest
+
+import (
+	"encoding/json"
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/test"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+func TestParseRemoteUrl(t *testing.T) {
+	assertRepository(t, "git@github.com:remotemobprogramming/mob.git", Repository{"github.com", "remotemobprogramming", "mob"})
+	assertRepository(t, "https://github.com/remotemobprogramming/mob.git", Repository{"github.com", "remotemobprogramming", "mob"})
+	assertRepository(t, "https://github.com/remotemobprogramming/mob", Repository{"github.com", "remotemobprogramming", "mob"})
+	assertRepository(t, "ssh://git@gitlab.example.com:2222/group/subgroup/project.git", Repository{"gitlab.example.com", "group/subgroup", "project"})
+	assertRepository(t, "https://user@gitea.example.com/team/project", Repository{"gitea.example.com", "team", "project"})
+
+	_, err := ParseRemoteUrl("file:///tmp/remote")
+	test.NotEquals(t, nil, err)
+	_, err = ParseRemoteUrl("")
+	test.NotEquals(t, nil, err)
+}
+
+func assertRepository(t *testing.T, remoteUrl string, expected Repository) {
+	repository, err := ParseRemoteUrl(remoteUrl)
+	test.Equals(t, nil, err)
+	test.Equals(t, expected, repository)
+}
+
+func TestNewGuessesProviderFromRemoteUrl(t *testing.T) {
+	configuration := config.GetDefaultConfiguration()
+
+	provider, _ := New(configuration, "git@github.com:remotemobprogramming/mob.git", "")
+	test.Equals(t, "https://api.github.com", provider.(GitHubProvider).ApiUrl)
+
+	provider, _ = New(configuration, "git@gitlab.com:group/project.git", "")
+	test.Equals(t, "https://gitlab.com/api/v4", provider.(GitLabProvider).ApiUrl)
+
+	_, err := New(configuration, "git@example.com:group/project.git", "")
+	test.NotEquals(t, nil, err)
+
+	configuration.PullRequestProvider = Gitea
+	configuration.PullRequestUrl = "https://git.example.com/api/v1/"
+	provider, _ = New(configuration, "git@example.com:group/project.git", "")
+	test.Equals(t, "https://git.example.com/api/v1", provider.(GiteaProvider).ApiUrl)
+}
+
+func TestGitHubProviderCreate(t *testing.T) {
+	var request *http.Request
+	var requestBody map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &requestBody)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"html_url": "https://github.com/remotemobprogramming/mob/pull/1"}`))
+	}))
+	defer server.Close()
+
+	provider := GitHubProvider{ApiUrl: server.URL, Token: "secret", Repository: Repository{"github.com", "remotemobprogramming", "mob"}}
+	url, err := provider.Create(PullRequest{Title: "title", Body: "body", Head: "feature/main", Base: "main"})
+
+	test.Equals(t, nil, err)
+	test.Equals(t, "https://github.com/remotemobprogramming/mob/pull/1", url)
+	test.Equals(t, "/repos/remotemobprogramming/mob/pulls", request.URL.Path)
+	test.Equals(t, "Bearer secret", request.Header.Get("Authorization"))
+	test.Equals(t, map[string]string{"title": "title", "body": "body", "head": "feature/main", "base": "main"}, requestBody)
+}
+
+func TestGitLabProviderCreate(t *testing.T) {
+	var request *http.Request
+	var requestBody map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &requestBody)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"web_url": "https://gitlab.com/group/sub/project/-/merge_requests/1"}`))
+	}))
+	defer server.Close()
+
+	provider := GitLabProvider{ApiUrl: server.URL, Token: "secret", Repository: Repository{"gitlab.com", "group/sub", "project"}}
+	url, err := provider.Create(PullRequest{Title: "title", Body: "body", Head: "feature/main", Base: "main"})
+
+	test.Equals(t, nil, err)
+	test.Equals(t, "https://gitlab.com/group/sub/project/-/merge_requests/1", url)
+	test.Equals(t, "/projects/group%2Fsub%2Fproject/merge_requests", request.URL.EscapedPath())
+	test.Equals(t, "secret", request.Header.Get("PRIVATE-TOKEN"))
+	test.Equals(t, map[string]string{"title": "title", "description": "body", "source_branch": "feature/main", "target_branch": "main"}, requestBody)
+}
+
+func TestProviderCreateFailsOnServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}))
+	defer server.Close()
+
+	provider := GiteaProvider{ApiUrl: server.URL, Repository: Repository{"gitea.com", "team", "project"}}
+	_, err := provider.Create(PullRequest{Title: "title", Head: "feature/main", Base: "main"})
+
+	test.NotEquals(t, nil, err)
+}
+
+func TestCommandProviderCreate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a posix shell")
+	}
+	provider := CommandProvider{Command: "echo creating $MOB_PULL_REQUEST_HEAD into $MOB_PULL_REQUEST_BASE && echo https://example.com/pulls/2"}
+
+	url, err := provider.Create(PullRequest{Title: "title", Head: "feature/main", Base: "main"})
+
+	test.Equals(t, nil, err)
+	test.Equals(t, "https://example.com/pulls/2", url)
+}
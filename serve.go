@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// mob serve --stdio speaks JSON-RPC 2.0 on stdin and stdout, one message per line.
+// Requests run the same command functions as the command line. Events are sent as notifications.
+
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcCommandFailed  = -32000
+)
+
+var serveWatchInterval = 30 * time.Second
+
+type rpcRequest struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  rpcParams       `json:"params"`
+}
+
+// rpcParams holds the command line arguments after the command, e.g. ["10", "--include-uncommitted-changes"],
+// and for next optionally the editor state to hand over
+type rpcParams struct {
+	Args  []string       `json:"args"`
+	State *handoverState `json:"state,omitempty"`
+}
+
+type rpcResponse struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int           `json:"code"`
+	Message string        `json:"message"`
+	Data    *rpcErrorData `json:"data,omitempty"`
+}
+
+type rpcErrorData struct {
+	ExitCode int      `json:"exitCode"`
+	Details  []string `json:"details,omitempty"`
+	Fixes    []Fix    `json:"fixes,omitempty"`
+}
+
+type rpcNotification struct {
+	Jsonrpc string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type commandResult struct {
+	Output string `json:"output"`
+}
+
+type server struct {
+	runner        GitRunner
+	configuration config.Configuration
+	out           io.Writer
+	writeLock     sync.Mutex
+	commandLock   sync.Mutex  // commands and the watcher share git and say
+	timer         *time.Timer // only used by the goroutine reading the requests
+	remoteWipHead string
+	nextTypist    string
+}
+
+func newServer(runner GitRunner, configuration config.Configuration, out io.Writer) *server {
+	return &server{runner: runner, configuration: configuration, out: out}
+}
+
+func serve(runner GitRunner, configuration config.Configuration, parameter []string) error {
+	if len(parameter) == 0 || parameter[0] != "--stdio" {
+		return newMobError(ErrInvalidArgument, "mob serve only supports --stdio",
+			Fix{"To drive mob from your editor via JSON-RPC on stdin and stdout, use", configuration.Mob("serve --stdio")})
+	}
+	if DryRun { // the fetch is skipped in a dry run, so it would never see the remote wip branch change
+		return newMobError(ErrInvalidArgument, "mob serve doesn't support --dry-run")
+	}
+	// git output must not end up between the JSON-RPC messages
+	GitPassthroughStderrStdout = false
+
+	s := newServer(runner, configuration, os.Stdout)
+	s.poll(false)
+	stop := make(chan struct{})
+	defer close(stop)
+	go s.watch(serveWatchInterval, stop)
+	return s.serve(os.Stdin)
+}
+
+func (s *server) serve(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var request rpcRequest
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			s.write(rpcResponse{Jsonrpc: "2.0", Id: json.RawMessage("null"), Error: &rpcError{Code: rpcParseError, Message: err.Error()}})
+			continue
+		}
+		response := s.handle(request)
+		if request.Id != nil {
+			s.write(response)
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *server) handle(request rpcRequest) rpcResponse {
+	response := rpcResponse{Jsonrpc: "2.0", Id: request.Id}
+	if request.Jsonrpc != "2.0" || request.Method == "" {
+		response.Error = &rpcError{Code: rpcInvalidRequest, Message: "expecting a JSON-RPC 2.0 request with a method"}
+		return response
+	}
+
+	var result interface{}
+	var err error
+	switch request.Method {
+	case "start", "next", "done", "timer", "goal":
+		_, parameter, configuration := config.ParseArgs(append([]string{"mob", request.Method}, request.Params.Args...), s.configuration)
+		var output string
+		output, err = s.capture(func() error {
+			if request.Method == "next" && request.Params.State != nil {
+				if err := writeHandoverState(s.runner, configuration, *request.Params.State); err != nil {
+					return &MobError{Kind: err, Message: "could not write the handover state", Details: []string{err.Error()}}
+				}
+			}
+			return execute(s.runner, request.Method, parameter, configuration)
+		})
+		if err == nil && (request.Method == "start" || request.Method == "timer") {
+			s.scheduleTimerExpired(timerMinutes(parameter, configuration), time.Minute)
+		}
+		result = commandResult{Output: output}
+	case "status":
+		_, err = s.capture(func() (err error) {
+			result, err = getStatus(s.runner, s.configuration)
+			return err
+		})
+	default:
+		response.Error = &rpcError{Code: rpcMethodNotFound, Message: "method '" + request.Method + "' not found"}
+		return response
+	}
+
+	if err != nil {
+		response.Error = toRpcError(err)
+		return response
+	}
+	response.Result = result
+	return response
+}
+
+func toRpcError(err error) *rpcError {
+	data := &rpcErrorData{ExitCode: exitCode(err)}
+	var mobError *MobError
+	if errors.As(err, &mobError) {
+		data.Details = deleteEmptyStrings(mobError.Details)
+		data.Fixes = mobError.Fixes
+	}
+	return &rpcError{Code: rpcCommandFailed, Message: err.Error(), Data: data}
+}
+
+// capture runs action and returns what it said instead of printing it to stdout
+func (s *server) capture(action func() error) (output string, err error) {
+	s.commandLock.Lock()
+	defer s.commandLock.Unlock()
+	originalPrintToConsole := say.PrintToConsole
+	say.PrintToConsole = func(text string) {
+		output += text
+	}
+	defer func() { say.PrintToConsole = originalPrintToConsole }()
+	err = action()
+	return
+}
+
+func timerMinutes(parameter []string, configuration config.Configuration) int {
+	timer := configuration.Timer
+	if len(parameter) > 0 {
+		timer = parameter[0]
+	}
+	err, minutes := toMinutes(timer)
+	if err != nil {
+		return 0
+	}
+	return minutes
+}
+
+func (s *server) scheduleTimerExpired(minutes int, unit time.Duration) {
+	if minutes == 0 {
+		return
+	}
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.timer = time.AfterFunc(time.Duration(minutes)*unit, func() {
+		s.notify("timerExpired", map[string]int{"minutes": minutes})
+	})
+}
+
+func (s *server) watch(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.poll(true)
+		}
+	}
+}
+
+// poll fetches and tells the client when the remote wip branch or the next typist changed
+func (s *server) poll(notify bool) {
+	var remoteWipHead, nextTypist string
+	var previousCommitters []string
+	var wipBranch Branch
+	_, err := s.capture(func() (err error) {
+		if _, err := silentgitChange(s.runner, "fetch", s.configuration.RemoteName, "--prune"); err != nil {
+			return err
+		}
+		var baseBranch Branch
+		baseBranch, wipBranch, err = currentBranches(s.runner, s.configuration)
+		if err != nil {
+			return err
+		}
+		remoteWipHead = gitRefHash(s.runner, wipBranch.remote(s.configuration).Name)
+		if remoteWipHead != "" {
+			nextTypist, previousCommitters, err = determineNextTypist(s.runner, baseBranch.remote(s.configuration), wipBranch.remote(s.configuration), gitUserName(s.runner))
+		}
+		return err
+	})
+	if err != nil {
+		return
+	}
+
+	if notify && remoteWipHead != s.remoteWipHead {
+		s.notify("remoteWipBranchUpdated", map[string]string{
+			"branch":       wipBranch.remote(s.configuration).Name,
+			"head":         remoteWipHead,
+			"previousHead": s.remoteWipHead,
+		})
+	}
+	if notify && nextTypist != s.nextTypist {
+		s.notify("nextTypistChanged", map[string]interface{}{
+			"nextTypist":         nextTypist,
+			"previousCommitters": previousCommitters,
+		})
+	}
+	s.remoteWipHead = remoteWipHead
+	s.nextTypist = nextTypist
+}
+
+func (s *server) notify(method string, params interface{}) {
+	s.write(rpcNotification{Jsonrpc: "2.0", Method: method, Params: params})
+}
+
+func (s *server) write(message interface{}) {
+	output, _ := json.Marshal(message)
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+	s.out.Write(append(output, '\n'))
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeStatus(t *testing.T) {
+	_, configuration := setup(t)
+
+	responses := serveRequests(t, newServer(gitRunner, configuration, &bytes.Buffer{}),
+		`{"jsonrpc":"2.0","id":1,"method":"status"}`)
+
+	equals(t, "1", string(responses[0].Id))
+	equals(t, (*rpcError)(nil), responses[0].Error)
+	var status statusJson
+	assertNoError(t, json.Unmarshal(responses[0].Result, &status))
+	equals(t, "master", status.CurrentBranch)
+	equals(t, false, status.MobProgramming)
+}
+
+func TestServeStartAndNext(t *testing.T) {
+	output, configuration := setup(t)
+
+	responses := serveRequests(t, newServer(gitRunner, configuration, &bytes.Buffer{}),
+		`{"jsonrpc":"2.0","id":1,"method":"start"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"next","params":{"args":["--stay"]}}`)
+
+	equals(t, 2, len(responses))
+	equals(t, (*rpcError)(nil), responses[0].Error)
+	assertRpcOutputContains(t, responses[0], "git checkout -B mob-session origin/master")
+	assertRpcOutputContains(t, responses[1], "nothing was done, so nothing to commit")
+	assertOnBranch(t, "mob-session")
+	assertOutputNotContains(t, output, "git checkout -B mob-session origin/master")
+}
+
+func TestServeCommandError(t *testing.T) {
+	_, configuration := setup(t)
+
+	responses := serveRequests(t, newServer(gitRunner, configuration, &bytes.Buffer{}),
+		`{"jsonrpc":"2.0","id":"a","method":"next"}`)
+
+	equals(t, `"a"`, string(responses[0].Id))
+	equals(t, rpcCommandFailed, responses[0].Error.Code)
+	equals(t, "you are not in a mob session", responses[0].Error.Message)
+	equals(t, 7, responses[0].Error.Data.ExitCode)
+	equals(t, []Fix{{"to start working together, use", "mob start"}}, responses[0].Error.Data.Fixes)
+}
+
+func TestServeDoesNotSupportDryRun(t *testing.T) {
+	_, configuration := setup(t)
+	enableDryRun(t)
+
+	err := serve(gitRunner, configuration, []string{"--stdio"})
+
+	assertErrorIs(t, err, ErrInvalidArgument)
+}
+
+func TestServeInvalidRequests(t *testing.T) {
+	_, configuration := setup(t)
+
+	responses := serveRequests(t, newServer(gitRunner, configuration, &bytes.Buffer{}),
+		`{"jsonrpc":"2.0","id":1,"method":"reset"}`,
+		`not json`,
+		`{"id":3,"method":"status"}`,
+		`{"jsonrpc":"2.0","method":"status"}`)
+
+	equals(t, 3, len(responses))
+	equals(t, rpcMethodNotFound, responses[0].Error.Code)
+	equals(t, rpcParseError, responses[1].Error.Code)
+	equals(t, rpcInvalidRequest, responses[2].Error.Code)
+}
+
+func TestServeWithoutStdio(t *testing.T) {
+	_, configuration := setup(t)
+
+	err := serve(gitRunner, configuration, []string{})
+
+	assertErrorIs(t, err, ErrInvalidArgument)
+	assertFix(t, err, "mob serve --stdio")
+}
+
+func TestServeNotifiesRemoteWipBranchUpdatedAndNextTypistChanged(t *testing.T) {
+	_, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+	out := &bytes.Buffer{}
+	s := newServer(gitRunner, configuration, out)
+	s.poll(false)
+
+	setWorkingDir(tempDir + "/localother")
+	start(gitRunner, configuration)
+	createFile(t, "other.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+	setWorkingDir(tempDir + "/local")
+	s.poll(true)
+
+	notifications := strings.Split(strings.TrimSpace(out.String()), "\n")
+	equals(t, 2, len(notifications))
+	var updated, typist rpcNotification
+	assertNoError(t, json.Unmarshal([]byte(notifications[0]), &updated))
+	assertNoError(t, json.Unmarshal([]byte(notifications[1]), &typist))
+	equals(t, "remoteWipBranchUpdated", updated.Method)
+	equals(t, "origin/mob-session", updated.Params.(map[string]interface{})["branch"])
+	equals(t, "nextTypistChanged", typist.Method)
+	equals(t, "local", typist.Params.(map[string]interface{})["nextTypist"])
+
+	out.Reset()
+	s.poll(true)
+
+	equals(t, "", out.String())
+}
+
+func TestServeNotifiesTimerExpired(t *testing.T) {
+	_, configuration := setup(t)
+	out := &bytes.Buffer{}
+	s := newServer(gitRunner, configuration, out)
+
+	s.scheduleTimerExpired(1, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+	equals(t, `{"jsonrpc":"2.0","method":"timerExpired","params":{"minutes":1}}`+"\n", out.String())
+}
+
+type testRpcResponse struct {
+	Id     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+func serveRequests(t *testing.T, s *server, requests ...string) []testRpcResponse {
+	out := &bytes.Buffer{}
+	s.out = out
+	assertNoError(t, s.serve(strings.NewReader(strings.Join(requests, "\n"))))
+
+	var responses []testRpcResponse
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var response testRpcResponse
+		assertNoError(t, json.Unmarshal([]byte(line), &response))
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+func assertRpcOutputContains(t *testing.T, response testRpcResponse, contains string) {
+	var result commandResult
+	assertNoError(t, json.Unmarshal(response.Result, &result))
+	assertOutputContains(t, &result.Output, contains)
+}
@@ -12,12 +12,22 @@ import (
 
 type Replacer func(string) string
 
-func squashWip(configuration config.Configuration) {
-	if hasUncommittedChanges() {
-		makeWipCommit(configuration)
+func squashWip(runner GitRunner, configuration config.Configuration) error {
+	if uncommittedChanges, err := hasUncommittedChanges(runner); err != nil {
+		return err
+	} else if uncommittedChanges {
+		if err := makeWipCommit(runner, configuration); err != nil {
+			return err
+		}
+	}
+	currentBaseBranch, currentWipBranch, err := currentBranches(runner, configuration)
+	if err != nil {
+		return err
+	}
+	mergeBase, err := silentgit(runner, "merge-base", currentWipBranch.String(), currentBaseBranch.remote(configuration).String())
+	if err != nil {
+		return err
 	}
-	currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
-	mergeBase := silentgit("merge-base", currentWipBranch.String(), currentBaseBranch.remote(configuration).String())
 
 	originalGitEditor, originalGitSequenceEditor := getEnvGitEditor()
 	setEnvGitEditor(
@@ -25,29 +35,42 @@ func squashWip(configuration config.Configuration) {
 		mobExecutable()+" squash-wip --git-sequence-editor",
 	)
 	say.Info("rewriting history of '" + currentWipBranch.String() + "': squashing wip commits while keeping manual commits.")
-	git("rebase", "--interactive", "--keep-empty", mergeBase)
+	err = git(runner, "rebase", "--interactive", "--keep-empty", mergeBase)
 	setEnvGitEditor(originalGitEditor, originalGitSequenceEditor)
+	if err != nil {
+		return err
+	}
 	say.Info("resulting history is:")
-	sayLastCommitsWithMessage(currentBaseBranch.remote(configuration).String(), currentWipBranch.String())
-	if lastCommitIsWipCommit(configuration) { // last commit is wip commit
+	if err := sayLastCommitsWithMessage(runner, currentBaseBranch.remote(configuration).String(), currentWipBranch.String()); err != nil {
+		return err
+	}
+	if wipCommit, err := lastCommitIsWipCommit(runner, configuration); err != nil {
+		return err
+	} else if wipCommit { // last commit is wip commit
 		say.Info("undoing the final wip commit and staging its changes:")
-		git("reset", "--soft", "HEAD^")
+		if err := git(runner, "reset", "--soft", "HEAD^"); err != nil {
+			return err
+		}
 	}
 
-	git("push", "--force", gitHooksOption(configuration))
+	return gitWithoutEmptyStrings(runner, "push", "--force", gitHooksOption(configuration))
 }
 
-func lastCommitIsWipCommit(configuration config.Configuration) bool {
-	return strings.HasPrefix(lastCommitMessage(), configuration.WipCommitMessage)
+func lastCommitIsWipCommit(runner GitRunner, configuration config.Configuration) (bool, error) {
+	lastCommitMessage, err := lastCommitMessage(runner)
+	return configuration.IsWipCommitMessage(lastCommitMessage), err
 }
 
-func lastCommitMessage() string {
-	return silentgit("log", "-1", "--pretty=format:%B")
+func lastCommitMessage(runner GitRunner) (string, error) {
+	return silentgit(runner, "log", "-1", "--pretty=format:%B")
 }
 
-func sayLastCommitsWithMessage(currentBaseBranch string, currentWipBranch string) {
+func sayLastCommitsWithMessage(runner GitRunner, currentBaseBranch string, currentWipBranch string) error {
 	commitsBaseWipBranch := currentBaseBranch + ".." + currentWipBranch
-	log := silentgit("--no-pager", "log", commitsBaseWipBranch, "--pretty=oneline", "--abbrev-commit")
+	log, err := silentgit(runner, "--no-pager", "log", commitsBaseWipBranch, "--pretty=oneline", "--abbrev-commit")
+	if err != nil {
+		return err
+	}
 	lines := strings.Split(log, "\n")
 	if len(lines) > 10 {
 		say.Info("wip branch '" + currentWipBranch + "' contains " + strconv.Itoa(len(lines)) + " commits. The last 10 were:")
@@ -55,6 +78,7 @@ func sayLastCommitsWithMessage(currentBaseBranch string, currentWipBranch string
 	}
 	output := strings.Join(lines, "\n")
 	say.Say(output)
+	return nil
 }
 
 func setEnvGitEditor(gitEditor string, gitSequenceEditor string) {
@@ -14,24 +14,24 @@ func TestSquashWipCommits_acceptance(t *testing.T) {
 	manualCommit(t, configuration, "file2.txt", "first manual commit")
 
 	// manual commit followed by a wip commit
-	start(configuration)
+	start(gitRunner, configuration)
 	createFileAndCommitIt(t, "file3.txt", "contentIrrelevant", "second manual commit")
 	createFile(t, "file4.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	// final manual commit
-	start(configuration)
+	start(gitRunner, configuration)
 	createFileAndCommitIt(t, "file5.txt", "contentIrrelevant", "third manual commit")
 
-	squashWip(configuration)
+	squashWip(gitRunner, configuration)
 
 	assertOnBranch(t, "mob-session")
 	equals(t, []string{
 		"third manual commit",
 		"second manual commit",
 		"first manual commit",
-	}, commitsOnCurrentBranch(configuration))
-	equals(t, commitsOnCurrentBranch(configuration), commitsOnRemoteBranch(configuration))
+	}, commitsOnCurrentBranch(t, configuration))
+	equals(t, commitsOnCurrentBranch(t, configuration), commitsOnRemoteBranch(t, configuration))
 }
 
 func TestSquashWipCommits_withFinalWipCommit(t *testing.T) {
@@ -39,9 +39,9 @@ func TestSquashWipCommits_withFinalWipCommit(t *testing.T) {
 	wipCommit(t, configuration, "file1.txt")
 	manualCommit(t, configuration, "file2.txt", "first manual commit")
 	wipCommit(t, configuration, "file3.txt")
-	start(configuration)
+	start(gitRunner, configuration)
 
-	squashWip(configuration)
+	squashWip(gitRunner, configuration)
 
 	assertOnBranch(t, "mob-session")
 	assertGitStatus(t, GitStatus{
@@ -49,7 +49,7 @@ func TestSquashWipCommits_withFinalWipCommit(t *testing.T) {
 	})
 	equals(t, []string{
 		"first manual commit",
-	}, commitsOnCurrentBranch(configuration))
+	}, commitsOnCurrentBranch(t, configuration))
 }
 
 func TestSquashWipCommits_withManyFinalWipCommits(t *testing.T) {
@@ -58,9 +58,9 @@ func TestSquashWipCommits_withManyFinalWipCommits(t *testing.T) {
 	manualCommit(t, configuration, "file2.txt", "first manual commit")
 	wipCommit(t, configuration, "file3.txt")
 	wipCommit(t, configuration, "file4.txt")
-	start(configuration)
+	start(gitRunner, configuration)
 
-	squashWip(configuration)
+	squashWip(gitRunner, configuration)
 
 	assertOnBranch(t, "mob-session")
 	assertGitStatus(t, GitStatus{
@@ -69,7 +69,7 @@ func TestSquashWipCommits_withManyFinalWipCommits(t *testing.T) {
 	})
 	equals(t, []string{
 		"first manual commit",
-	}, commitsOnCurrentBranch(configuration))
+	}, commitsOnCurrentBranch(t, configuration))
 }
 
 func TestSquashWipCommits_onlyWipCommits(t *testing.T) {
@@ -77,9 +77,9 @@ func TestSquashWipCommits_onlyWipCommits(t *testing.T) {
 	wipCommit(t, configuration, "file1.txt")
 	wipCommit(t, configuration, "file2.txt")
 	wipCommit(t, configuration, "file3.txt")
-	start(configuration)
+	start(gitRunner, configuration)
 
-	squashWip(configuration)
+	squashWip(gitRunner, configuration)
 
 	assertOnBranch(t, "mob-session")
 	assertGitStatus(t, GitStatus{
@@ -87,34 +87,34 @@ func TestSquashWipCommits_onlyWipCommits(t *testing.T) {
 		"file2.txt": "A",
 		"file3.txt": "A",
 	})
-	equals(t, []string{""}, commitsOnCurrentBranch(configuration))
+	equals(t, []string{""}, commitsOnCurrentBranch(t, configuration))
 }
 
 func TestSquashWipCommits_uncommittedModificationOfCommittedFile(t *testing.T) {
 	_, configuration := setup(t)
 	manualCommit(t, configuration, "file1.txt", "first manual commit")
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "file1.txt", "change")
 
-	squashWip(configuration)
+	squashWip(gitRunner, configuration)
 
 	assertOnBranch(t, "mob-session")
 	assertGitStatus(t, GitStatus{
 		"file1.txt": "M",
 	})
-	equals(t, []string{"first manual commit"}, commitsOnCurrentBranch(configuration))
+	equals(t, []string{"first manual commit"}, commitsOnCurrentBranch(t, configuration))
 }
 
 func TestSquashWipCommits_resetsEnv(t *testing.T) {
 	_, configuration := setup(t)
-	start(configuration)
+	start(gitRunner, configuration)
 	createFileAndCommitIt(t, "file1.txt", "contentIrrelevant", "new file")
 	originalGitEditor := "irrelevant"
 	originalGitSequenceEditor := "irrelevant, too"
 	os.Setenv("GIT_EDITOR", originalGitEditor)
 	os.Setenv("GIT_SEQUENCE_EDITOR", originalGitSequenceEditor)
 
-	squashWip(configuration)
+	squashWip(gitRunner, configuration)
 
 	equals(t, originalGitEditor, os.Getenv("GIT_EDITOR"))
 	equals(t, originalGitSequenceEditor, os.Getenv("GIT_SEQUENCE_EDITOR"))
@@ -124,15 +124,15 @@ func TestSquashWipCommits_worksWithEmptyCommits(t *testing.T) {
 	_, configuration := setup(t)
 	wipCommit(t, configuration, "file1.txt")
 
-	start(configuration)
-	silentgit("commit", "--allow-empty", "-m ok")
+	start(gitRunner, configuration)
+	silentgit(gitRunner, "commit", "--allow-empty", "-m ok")
 
-	squashWip(configuration)
+	squashWip(gitRunner, configuration)
 
 	assertOnBranch(t, "mob-session")
 	equals(t, []string{
 		"ok",
-	}, commitsOnCurrentBranch(configuration))
+	}, commitsOnCurrentBranch(t, configuration))
 }
 
 func TestSquashWipCommits_acceptanceWithDroppingStartCommit(t *testing.T) {
@@ -141,13 +141,13 @@ func TestSquashWipCommits_acceptanceWithDroppingStartCommit(t *testing.T) {
 	manualCommit(t, configuration, "file2.txt", "first manual commit")
 
 	// manual commit followed by a wip commit
-	start(configuration)
+	start(gitRunner, configuration)
 	createFileAndCommitIt(t, "file3.txt", "contentIrrelevant", "second manual commit")
 	createFile(t, "file4.txt", "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 
 	// final manual commit
-	start(configuration)
+	start(gitRunner, configuration)
 	createFileAndCommitIt(t, "file5.txt", "contentIrrelevant", "third manual commit")
 
 	// Check if the initial commit for ci skip exists
@@ -157,30 +157,30 @@ func TestSquashWipCommits_acceptanceWithDroppingStartCommit(t *testing.T) {
 		"second manual commit",
 		"first manual commit",
 		configuration.WipCommitMessage,
-	}, commitsOnCurrentBranch(configuration))
+	}, commitsOnCurrentBranch(t, configuration))
 
-	squashWip(configuration)
+	squashWip(gitRunner, configuration)
 
 	assertOnBranch(t, "mob-session")
 	equals(t, []string{
 		"third manual commit",
 		"second manual commit",
 		"first manual commit",
-	}, commitsOnCurrentBranch(configuration))
-	equals(t, commitsOnCurrentBranch(configuration), commitsOnRemoteBranch(configuration))
+	}, commitsOnCurrentBranch(t, configuration))
+	equals(t, commitsOnCurrentBranch(t, configuration), commitsOnRemoteBranch(t, configuration))
 }
 
 func TestCommitsOnCurrentBranch(t *testing.T) {
 	_, configuration := setup(t)
 	createFileAndCommitIt(t, "file1.txt", "contentIrrelevant", "not on branch")
-	silentgit("push")
-	start(configuration)
+	silentgit(gitRunner, "push")
+	start(gitRunner, configuration)
 	createFileAndCommitIt(t, "file2.txt", "contentIrrelevant", "on branch")
 	createFile(t, "file3.txt", "contentIrrelevant")
-	next(configuration)
-	start(configuration)
+	next(gitRunner, configuration)
+	start(gitRunner, configuration)
 
-	commits := commitsOnCurrentBranch(configuration)
+	commits := commitsOnCurrentBranch(t, configuration)
 
 	equals(t, []string{
 		configuration.WipCommitMessage,
@@ -433,29 +433,31 @@ squash c51a56d manual commit
 }
 
 func wipCommit(t *testing.T, configuration config.Configuration, filename string) {
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, filename, "contentIrrelevant")
-	next(configuration)
+	next(gitRunner, configuration)
 }
 
 func manualCommit(t *testing.T, configuration config.Configuration, filename string, message string) {
-	start(configuration)
+	start(gitRunner, configuration)
 	createFileAndCommitIt(t, filename, "contentIrrelevant", message)
-	next(configuration)
+	next(gitRunner, configuration)
 }
 
-func commitsOnCurrentBranch(configuration config.Configuration) []string {
-	currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+func commitsOnCurrentBranch(t *testing.T, configuration config.Configuration) []string {
+	currentBaseBranch, currentWipBranch, err := currentBranches(gitRunner, configuration)
+	assertNoError(t, err)
 	commitsBaseWipBranch := currentBaseBranch.String() + ".." + currentWipBranch.String()
-	log := silentgit("--no-pager", "log", commitsBaseWipBranch, "--pretty=format:%s")
+	log := gitOutput(t, "--no-pager", "log", commitsBaseWipBranch, "--pretty=format:%s")
 	lines := strings.Split(log, "\n")
 	return lines
 }
 
-func commitsOnRemoteBranch(configuration config.Configuration) []string {
-	currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+func commitsOnRemoteBranch(t *testing.T, configuration config.Configuration) []string {
+	currentBaseBranch, currentWipBranch, err := currentBranches(gitRunner, configuration)
+	assertNoError(t, err)
 	commitsBaseWipBranch := currentBaseBranch.String() + ".." + configuration.RemoteName + "/" + currentWipBranch.String()
-	log := silentgit("--no-pager", "log", commitsBaseWipBranch, "--pretty=format:%s")
+	log := gitOutput(t, "--no-pager", "log", commitsBaseWipBranch, "--pretty=format:%s")
 	lines := strings.Split(log, "\n")
 	return lines
 }
@@ -1,32 +1,184 @@
 package main
 
 import (
+	"encoding/json"
+	"strings"
+
 	config "github.com/remotemobprogramming/mob/v5/configuration"
 	"github.com/remotemobprogramming/mob/v5/say"
 )
 
-func status(configuration config.Configuration) {
-	if isMobProgramming(configuration) {
-		currentBaseBranch, currentWipBranch := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
-		say.Info("you are on wip branch " + currentWipBranch.String() + " (base branch " + currentBaseBranch.String() + ")")
-
-		sayLastCommitsList(currentBaseBranch, currentWipBranch, configuration)
-	} else {
-		currentBaseBranch, _ := determineBranches(gitCurrentBranch(), gitBranches(), configuration)
+func status(runner GitRunner, configuration config.Configuration) error {
+	mobProgramming, err := isMobProgramming(runner, configuration)
+	if err != nil {
+		return err
+	}
+	currentBaseBranch, currentWipBranch, err := currentBranches(runner, configuration)
+	if err != nil {
+		return err
+	}
+	if !mobProgramming {
 		say.Info("you are on base branch '" + currentBaseBranch.String() + "'")
-		showActiveMobSessions(configuration, currentBaseBranch)
+		return showActiveMobSessions(runner, configuration, currentBaseBranch)
+	}
+
+	say.Info("you are on wip branch " + currentWipBranch.String() + " (base branch " + currentBaseBranch.String() + ")")
+	if err := sayLastCommitsList(runner, currentBaseBranch, currentWipBranch, configuration); err != nil {
+		return err
 	}
+	sayHandoverNote(lastHandoverNote(runner, configuration, currentWipBranch.String()))
+	return nil
 }
 
-func showActiveMobSessions(configuration config.Configuration, currentBaseBranch Branch) {
-	existingWipBranches := getWipBranchesForBaseBranch(currentBaseBranch, configuration)
-	if len(existingWipBranches) > 0 {
-		say.Info("remote wip branches detected:")
-		for _, wipBranch := range existingWipBranches {
-			time := silentgit("log", "-1", "--pretty=format:(%ar)", wipBranch)
-			say.WithPrefix(wipBranch+" "+time, "  - ")
-		}
-	} else {
+func showActiveMobSessions(runner GitRunner, configuration config.Configuration, currentBaseBranch Branch) error {
+	existingWipBranches, err := getWipBranchesForBaseBranch(runner, currentBaseBranch, configuration)
+	if err != nil {
+		return err
+	}
+	if len(existingWipBranches) == 0 {
 		say.Info("no remote wip branches detected!")
+		return nil
+	}
+	say.Info("remote wip branches detected:")
+	for _, wipBranch := range existingWipBranches {
+		time, err := silentgit(runner, "log", "-1", "--pretty=format:(%ar)", wipBranch)
+		if err != nil {
+			return err
+		}
+		say.WithPrefix(wipBranch+" "+time, "  - ")
+	}
+	return nil
+}
+
+type statusJson struct {
+	CurrentBranch     string          `json:"currentBranch"`
+	BaseBranch        string          `json:"baseBranch"`
+	WipBranch         string          `json:"wipBranch"`
+	MobProgramming    bool            `json:"mobProgramming"`
+	WipCommits        []wipCommitJson `json:"wipCommits"`
+	ActiveWipBranches []wipBranchJson `json:"activeWipBranches"`
+	Timer             timerJson       `json:"timer"`
+	Note              *noteJson       `json:"note,omitempty"`
+}
+
+type noteJson struct {
+	Author string `json:"author"`
+	Text   string `json:"text"`
+}
+
+type wipCommitJson struct {
+	Hash         string `json:"hash"`
+	Author       string `json:"author"`
+	Date         string `json:"date"`
+	RelativeTime string `json:"relativeTime"`
+	Subject      string `json:"subject"`
+}
+
+type wipBranchJson struct {
+	Name         string `json:"name"`
+	RelativeTime string `json:"relativeTime"`
+}
+
+type timerJson struct {
+	Timer string `json:"timer"`
+	Room  string `json:"room"`
+	Url   string `json:"url"`
+	Local bool   `json:"local"`
+}
+
+func statusAsJson(runner GitRunner, configuration config.Configuration) error {
+	status, err := getStatus(runner, configuration)
+	if err != nil {
+		return err
 	}
+	sayJson(status)
+	return nil
+}
+
+func getStatus(runner GitRunner, configuration config.Configuration) (statusJson, error) {
+	currentBranch, err := gitCurrentBranch(runner)
+	if err != nil {
+		return statusJson{}, err
+	}
+	localBranches, err := gitBranches(runner)
+	if err != nil {
+		return statusJson{}, err
+	}
+	currentBaseBranch, currentWipBranch := determineBranches(currentBranch, localBranches, configuration)
+	mobProgramming := currentBranch == currentWipBranch
+
+	wipCommits := []wipCommitJson{}
+	var note *noteJson
+	if mobProgramming {
+		if wipCommits, err = getWipCommits(runner, currentBaseBranch, currentWipBranch, configuration); err != nil {
+			return statusJson{}, err
+		}
+		if author, text := lastHandoverNote(runner, configuration, currentWipBranch.String()); text != "" {
+			note = &noteJson{Author: author, Text: text}
+		}
+	}
+
+	wipBranches, err := getWipBranchesForBaseBranch(runner, currentBaseBranch, configuration)
+	if err != nil {
+		return statusJson{}, err
+	}
+	activeWipBranches := []wipBranchJson{}
+	for _, wipBranch := range wipBranches {
+		relativeTime, err := silentgit(runner, "log", "-1", "--pretty=format:%ar", wipBranch)
+		if err != nil {
+			return statusJson{}, err
+		}
+		activeWipBranches = append(activeWipBranches, wipBranchJson{
+			Name:         wipBranch,
+			RelativeTime: relativeTime,
+		})
+	}
+
+	return statusJson{
+		CurrentBranch:     currentBranch.String(),
+		BaseBranch:        currentBaseBranch.String(),
+		WipBranch:         currentWipBranch.String(),
+		MobProgramming:    mobProgramming,
+		WipCommits:        wipCommits,
+		ActiveWipBranches: activeWipBranches,
+		Timer: timerJson{
+			Timer: configuration.Timer,
+			Room:  getMobTimerRoom(runner, configuration),
+			Url:   configuration.TimerUrl,
+			Local: configuration.TimerLocal,
+		},
+		Note: note,
+	}, nil
+}
+
+// getWipCommits returns the commits of the wip branch that are not on the base branch, newest first
+func getWipCommits(runner GitRunner, currentBaseBranch Branch, currentWipBranch Branch, configuration config.Configuration) ([]wipCommitJson, error) {
+	format := "--pretty=format:%h%x1f%an%x1f%cI%x1f%cr%x1f%s"
+	log, err := silentgitignorefailure(runner, "--no-pager", "log", currentBaseBranch.String()+".."+currentWipBranch.String(), format)
+	if err != nil {
+		if log, err = silentgit(runner, "--no-pager", "log", currentBaseBranch.remote(configuration).String()+".."+currentWipBranch.String(), format); err != nil {
+			return nil, err
+		}
+	}
+
+	commits := []wipCommitJson{}
+	for _, line := range strings.Split(log, "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 5 {
+			continue
+		}
+		commits = append(commits, wipCommitJson{
+			Hash:         fields[0],
+			Author:       fields[1],
+			Date:         fields[2],
+			RelativeTime: fields[3],
+			Subject:      fields[4],
+		})
+	}
+	return commits, nil
+}
+
+func sayJson(value interface{}) {
+	output, _ := json.MarshalIndent(value, "", "  ")
+	say.Say(string(output))
 }
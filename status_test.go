@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	config "github.com/remotemobprogramming/mob/v5/configuration"
 	"strconv"
 	"testing"
@@ -9,16 +10,16 @@ import (
 func TestExecuteKicksOffStatus(t *testing.T) {
 	output, _ := setup(t)
 
-	execute("status", []string{}, config.GetDefaultConfiguration())
+	execute(gitRunner, "status", []string{}, config.GetDefaultConfiguration())
 
 	assertOutputContains(t, output, "you are on base branch 'master'")
 }
 
 func TestStatusMobProgramming(t *testing.T) {
 	output, configuration := setup(t)
-	start(configuration)
+	start(gitRunner, configuration)
 
-	status(configuration)
+	status(gitRunner, configuration)
 
 	assertOutputContains(t, output, "you are on wip branch mob-session")
 }
@@ -26,27 +27,73 @@ func TestStatusMobProgramming(t *testing.T) {
 func TestStatusWithMoreThan5LinesOfLog(t *testing.T) {
 	output, configuration := setup(t)
 	configuration.NextStay = true
-	start(configuration)
+	start(gitRunner, configuration)
 
 	for i := 0; i < 6; i++ {
 		createFile(t, "test"+strconv.Itoa(i)+".txt", "contentIrrelevant")
-		next(configuration)
+		next(gitRunner, configuration)
 	}
 
-	status(configuration)
+	status(gitRunner, configuration)
 	assertOutputContains(t, output, "wip branch 'mob-session' contains 6 commits.")
 }
 
 func TestStatusDetectsWipBranches(t *testing.T) {
 	output, configuration := setup(t)
-	start(configuration)
+	start(gitRunner, configuration)
 	createFile(t, "test.txt", "contentIrrelevant")
-	next(configuration)
-	git("checkout", "master")
+	next(gitRunner, configuration)
+	git(gitRunner, "checkout", "master")
 
-	status(configuration)
+	status(gitRunner, configuration)
 
 	assertOutputContains(t, output, "remote wip branches detected:\n  - origin/mob-session")
 	assertOutputContains(t, output, " second")
 	assertOutputContains(t, output, " ago)")
 }
+
+func TestStatusJsonMobProgramming(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.NextStay = true
+	start(gitRunner, configuration)
+	createFile(t, "test.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+	*output = ""
+
+	statusAsJson(gitRunner, configuration)
+
+	var status statusJson
+	assertNoError(t, json.Unmarshal([]byte(*output), &status))
+	equals(t, "mob-session", status.CurrentBranch)
+	equals(t, "master", status.BaseBranch)
+	equals(t, "mob-session", status.WipBranch)
+	equals(t, true, status.MobProgramming)
+	equals(t, 1, len(status.WipCommits))
+	equals(t, "local", status.WipCommits[0].Author)
+	equals(t, configuration.WipCommitMessage, status.WipCommits[0].Subject)
+	equals(t, []wipBranchJson{{Name: "origin/mob-session", RelativeTime: status.ActiveWipBranches[0].RelativeTime}}, status.ActiveWipBranches)
+}
+
+func TestStatusJsonDetectsWipBranches(t *testing.T) {
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "test.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+	git(gitRunner, "checkout", "master")
+	*output = ""
+
+	execute(gitRunner, "status", []string{}, withOutputJson(configuration))
+
+	var status statusJson
+	assertNoError(t, json.Unmarshal([]byte(*output), &status))
+	equals(t, "master", status.CurrentBranch)
+	equals(t, false, status.MobProgramming)
+	equals(t, []wipCommitJson{}, status.WipCommits)
+	equals(t, "origin/mob-session", status.ActiveWipBranches[0].Name)
+	assertOutputContains(t, output, " ago")
+}
+
+func withOutputJson(configuration config.Configuration) config.Configuration {
+	configuration.OutputJson = true
+	return configuration
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	config "github.com/remotemobprogramming/mob/v5/configuration"
 	"github.com/remotemobprogramming/mob/v5/httpclient"
@@ -12,13 +11,7 @@ import (
 	"time"
 )
 
-func StartTimer(timerInMinutes string, configuration config.Configuration) {
-	if err := startTimer(timerInMinutes, configuration); err != nil {
-		Exit(1)
-	}
-}
-
-func startTimer(timerInMinutes string, configuration config.Configuration) error {
+func startTimer(runner GitRunner, timerInMinutes string, configuration config.Configuration) error {
 	err, timeoutInMinutes := toMinutes(timerInMinutes)
 	if err != nil {
 		return err
@@ -28,32 +21,31 @@ func startTimer(timerInMinutes string, configuration config.Configuration) error
 	timeOfTimeout := time.Now().Add(time.Minute * time.Duration(timeoutInMinutes)).Format("15:04")
 	say.Debug(fmt.Sprintf("Starting timer at %s for %d minutes = %d seconds (parsed from user input %s)", timeOfTimeout, timeoutInMinutes, timeoutInSeconds, timerInMinutes))
 
-	room := getMobTimerRoom(configuration)
+	room := getMobTimerRoom(runner, configuration)
 	startRemoteTimer := room != ""
 	startLocalTimer := configuration.TimerLocal
 
 	if !startRemoteTimer && !startLocalTimer {
-		say.Error("No timer configured, not starting timer")
-		Exit(1)
+		return newMobError(ErrTimerNotConfigured, "No timer configured, not starting timer")
 	}
 
 	if startRemoteTimer {
-		timerUser := getUserForMobTimer(configuration.TimerUser)
+		timerUser := getUserForMobTimer(runner, configuration.TimerUser)
 		err := httpPutTimer(timeoutInMinutes, room, timerUser, configuration.TimerUrl, configuration.TimerInsecure)
 		if err != nil {
-			say.Error("remote timer couldn't be started")
-			say.Error(err.Error())
-			Exit(1)
+			return &MobError{Kind: ErrTimerServiceUnavailable, Message: "remote timer couldn't be started", Details: []string{err.Error()}}
 		}
 	}
 
-	if startLocalTimer {
-		err := executeCommandsInBackgroundProcess(getSleepCommand(timeoutInSeconds), getVoiceCommand(configuration.VoiceMessage, configuration.VoiceCommand), getNotifyCommand(configuration.NotifyMessage, configuration.NotifyCommand), "echo \"mobTimer\"")
+	endCommands, err := timerEndCommands(runner, startLocalTimer, configuration)
+	if err != nil {
+		return err
+	}
+	if len(endCommands) > 0 {
+		err = executeCommandsInBackgroundProcess(runner, append([]string{getSleepCommand(timeoutInSeconds)}, endCommands...)...)
 
 		if err != nil {
-			say.Error(fmt.Sprintf("timer couldn't be started on your system (%s)", runtime.GOOS))
-			say.Error(err.Error())
-			Exit(1)
+			return &MobError{Kind: err, Message: fmt.Sprintf("timer couldn't be started on your system (%s)", runtime.GOOS), Details: []string{err.Error()}}
 		}
 	}
 
@@ -61,16 +53,42 @@ func startTimer(timerInMinutes string, configuration config.Configuration) error
 	return nil
 }
 
-func getMobTimerRoom(configuration config.Configuration) string {
-	if !isGit() {
+// timerEndCommands run in the background when the timer ends. The room of a remote timer only rings in the browser,
+// so without a local timer only the hook and the webhook run.
+func timerEndCommands(runner GitRunner, localTimer bool, configuration config.Configuration) ([]string, error) {
+	hookCommand, err := timerHookCommand(runner, configuration)
+	if err != nil {
+		return nil, err
+	}
+	if !localTimer {
+		return deleteEmptyStrings([]string{hookCommand, timerWebhookCommand(configuration)}), nil
+	}
+	voiceCommand, err := getVoiceCommand(runner, configuration.VoiceMessage, configuration)
+	if err != nil {
+		return nil, err
+	}
+	notifyCommand, err := getNotifyCommand(runner, configuration.NotifyMessage, false, configuration)
+	if err != nil {
+		return nil, err
+	}
+	return []string{voiceCommand, notifyCommand, hookCommand, timerWebhookCommand(configuration), "echo \"mobTimer\""}, nil
+}
+
+func getMobTimerRoom(runner GitRunner, configuration config.Configuration) string {
+	if !isGit(runner) {
 		say.Debug("timer not in git repository, using MOB_TIMER_ROOM for room name")
 		return configuration.TimerRoom
 	}
 
 	currentWipBranchQualifier := configuration.WipBranchQualifier
 	if currentWipBranchQualifier == "" {
-		currentBranch := gitCurrentBranch()
-		currentBaseBranch, _ := determineBranches(currentBranch, gitBranches(), configuration)
+		currentBranch, err := gitCurrentBranch(runner)
+		if err != nil {
+			say.Debug("timer not on a branch, using MOB_TIMER_ROOM for room name: " + err.Error())
+			return configuration.TimerRoom
+		}
+		localBranches, _ := gitBranches(runner)
+		currentBaseBranch, _ := determineBranches(currentBranch, localBranches, configuration)
 
 		if currentBranch.IsWipBranch(configuration) {
 			wipBranchWithoutWipPrefix := currentBranch.removeWipPrefix(configuration).Name
@@ -86,13 +104,7 @@ func getMobTimerRoom(configuration config.Configuration) string {
 	return configuration.TimerRoom
 }
 
-func StartBreakTimer(timerInMinutes string, configuration config.Configuration) {
-	if err := startBreakTimer(timerInMinutes, configuration); err != nil {
-		Exit(1)
-	}
-}
-
-func startBreakTimer(timerInMinutes string, configuration config.Configuration) error {
+func startBreakTimer(runner GitRunner, timerInMinutes string, configuration config.Configuration) error {
 	err, timeoutInMinutes := toMinutes(timerInMinutes)
 	if err != nil {
 		return err
@@ -102,33 +114,36 @@ func startBreakTimer(timerInMinutes string, configuration config.Configuration)
 	timeOfTimeout := time.Now().Add(time.Minute * time.Duration(timeoutInMinutes)).Format("15:04")
 	say.Debug(fmt.Sprintf("Starting break timer at %s for %d minutes = %d seconds (parsed from user input %s)", timeOfTimeout, timeoutInMinutes, timeoutInSeconds, timerInMinutes))
 
-	room := getMobTimerRoom(configuration)
+	room := getMobTimerRoom(runner, configuration)
 	startRemoteTimer := room != ""
 	startLocalTimer := configuration.TimerLocal
 
 	if !startRemoteTimer && !startLocalTimer {
-		say.Error("No break timer configured, not starting break timer")
-		Exit(1)
+		return newMobError(ErrTimerNotConfigured, "No break timer configured, not starting break timer")
 	}
 
 	if startRemoteTimer {
-		timerUser := getUserForMobTimer(configuration.TimerUser)
+		timerUser := getUserForMobTimer(runner, configuration.TimerUser)
 		err := httpPutBreakTimer(timeoutInMinutes, room, timerUser, configuration.TimerUrl, configuration.TimerInsecure)
 
 		if err != nil {
-			say.Error("remote break timer couldn't be started")
-			say.Error(err.Error())
-			Exit(1)
+			return &MobError{Kind: ErrTimerServiceUnavailable, Message: "remote break timer couldn't be started", Details: []string{err.Error()}}
 		}
 	}
 
 	if startLocalTimer {
-		err := executeCommandsInBackgroundProcess(getSleepCommand(timeoutInSeconds), getVoiceCommand("mob start", configuration.VoiceCommand), getNotifyCommand("mob start", configuration.NotifyCommand), "echo \"mobTimer\"")
+		voiceCommand, err := getVoiceCommand(runner, "mob start", configuration)
+		if err != nil {
+			return err
+		}
+		notifyCommand, err := getNotifyCommand(runner, "mob start", true, configuration)
+		if err != nil {
+			return err
+		}
+		err = executeCommandsInBackgroundProcess(runner, getSleepCommand(timeoutInSeconds), voiceCommand, notifyCommand, "echo \"mobTimer\"")
 
 		if err != nil {
-			say.Error(fmt.Sprintf("break timer couldn't be started on your system (%s)", runtime.GOOS))
-			say.Error(err.Error())
-			Exit(1)
+			return &MobError{Kind: err, Message: fmt.Sprintf("break timer couldn't be started on your system (%s)", runtime.GOOS), Details: []string{err.Error()}}
 		}
 	}
 
@@ -136,9 +151,9 @@ func startBreakTimer(timerInMinutes string, configuration config.Configuration)
 	return nil
 }
 
-func getUserForMobTimer(userOverride string) string {
+func getUserForMobTimer(runner GitRunner, userOverride string) string {
 	if userOverride == "" {
-		return gitUserName()
+		return gitUserName(runner)
 	}
 	return userOverride
 }
@@ -146,8 +161,7 @@ func getUserForMobTimer(userOverride string) string {
 func toMinutes(timerInMinutes string) (error, int) {
 	timeoutInMinutes, err := strconv.Atoi(timerInMinutes)
 	if err != nil || timeoutInMinutes < 1 {
-		say.Error(fmt.Sprintf("The parameter must be an integer number greater then zero"))
-		return errors.New("The parameter must be an integer number greater then zero"), 0
+		return newMobError(ErrInvalidArgument, "The parameter must be an integer number greater then zero"), 0
 	}
 	return nil, timeoutInMinutes
 }
@@ -176,16 +190,15 @@ func getSleepCommand(timeoutInSeconds int) string {
 	return fmt.Sprintf("sleep %d", timeoutInSeconds)
 }
 
-func getVoiceCommand(message string, voiceCommand string) string {
-	if len(voiceCommand) == 0 {
-		return ""
-	}
-	return injectCommandWithMessage(voiceCommand, message)
+func getVoiceCommand(runner GitRunner, message string, configuration config.Configuration) (string, error) {
+	return injectCommandWithMessage(runner, configuration.VoiceCommand, message, configuration)
 }
 
-func getNotifyCommand(message string, notifyCommand string) string {
-	if len(notifyCommand) == 0 {
-		return ""
+// injectCommandWithMessage renders a voice or notify command as a command line for the background process
+func injectCommandWithMessage(runner GitRunner, command string, message string, configuration config.Configuration) (string, error) {
+	if len(command) == 0 {
+		return "", nil
 	}
-	return injectCommandWithMessage(notifyCommand, message)
+	_, commandLine, err := renderCommand(runner, command, "message", map[string]string{"message": message}, configuration)
+	return commandLine, err
 }
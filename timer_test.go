@@ -34,21 +34,20 @@ func TestOpenTimerInBrowserError(t *testing.T) {
 }
 
 func TestTimerNumberLessThen1(t *testing.T) {
-	output, configuration := setup(t)
+	_, configuration := setup(t)
 
-	err := startTimer("0", configuration)
+	err := startTimer(gitRunner, "0", configuration)
 
 	assertError(t, err, "The parameter must be an integer number greater then zero")
-	assertOutputContains(t, output, "The parameter must be an integer number greater then zero")
+	assertErrorIs(t, err, ErrInvalidArgument)
 }
 
 func TestTimerNotANumber(t *testing.T) {
-	output, configuration := setup(t)
+	_, configuration := setup(t)
 
-	err := startTimer("NotANumber", configuration)
+	err := startTimer(gitRunner, "NotANumber", configuration)
 
 	assertError(t, err, "The parameter must be an integer number greater then zero")
-	assertOutputContains(t, output, "The parameter must be an integer number greater then zero")
 }
 
 func TestTimer(t *testing.T) {
@@ -56,7 +55,7 @@ func TestTimer(t *testing.T) {
 	configuration.NotifyCommand = ""
 	configuration.VoiceCommand = ""
 
-	err := startTimer("1", configuration)
+	err := startTimer(gitRunner, "1", configuration)
 
 	assertNoError(t, err)
 	assertOutputContains(t, output, "1 min timer ends at approx.")
@@ -68,28 +67,55 @@ func TestTimerExportFunction(t *testing.T) {
 	configuration.NotifyCommand = ""
 	configuration.VoiceCommand = ""
 
-	StartTimer("1", configuration)
+	err := startTimer(gitRunner, "1", configuration)
 
+	assertNoError(t, err)
 	assertOutputContains(t, output, "1 min timer ends at approx.")
 	assertOutputContains(t, output, "Happy collaborating! :)")
 }
 
+func TestTimerEndCommands(t *testing.T) {
+	_, configuration := setup(t)
+	configuration.VoiceCommand = "say \"%s\""
+	configuration.NotifyCommand = ""
+	configuration.HookTimer = "echo 'time is up'"
+	hookCommand, err := timerHookCommand(gitRunner, configuration)
+	assertNoError(t, err)
+
+	commands, err := timerEndCommands(gitRunner, true, configuration)
+	assertNoError(t, err)
+	equals(t, []string{"say \"mob next\"", "", hookCommand, "", "echo \"mobTimer\""}, commands)
+
+	commands, err = timerEndCommands(gitRunner, false, configuration)
+	assertNoError(t, err)
+	equals(t, []string{hookCommand}, commands)
+
+	configuration.WebhookUrl = "https://chat.example.com/hooks/secret"
+	commands, err = timerEndCommands(gitRunner, false, configuration)
+	assertNoError(t, err)
+	equals(t, []string{hookCommand, timerWebhookCommand(configuration)}, commands)
+	configuration.WebhookUrl = ""
+
+	configuration.HookTimer = ""
+	commands, err = timerEndCommands(gitRunner, false, configuration)
+	assertNoError(t, err)
+	equals(t, 0, len(commands))
+}
+
 func TestBreakTimerNumberLessThen1(t *testing.T) {
-	output, configuration := setup(t)
+	_, configuration := setup(t)
 
-	err := startBreakTimer("0", configuration)
+	err := startBreakTimer(gitRunner, "0", configuration)
 
 	assertError(t, err, "The parameter must be an integer number greater then zero")
-	assertOutputContains(t, output, "The parameter must be an integer number greater then zero")
 }
 
 func TestBreakTimerNotANumber(t *testing.T) {
-	output, configuration := setup(t)
+	_, configuration := setup(t)
 
-	err := startBreakTimer("NotANumber", configuration)
+	err := startBreakTimer(gitRunner, "NotANumber", configuration)
 
 	assertError(t, err, "The parameter must be an integer number greater then zero")
-	assertOutputContains(t, output, "The parameter must be an integer number greater then zero")
 }
 
 func TestBreakTimer(t *testing.T) {
@@ -97,7 +123,7 @@ func TestBreakTimer(t *testing.T) {
 	configuration.NotifyCommand = ""
 	configuration.VoiceCommand = ""
 
-	err := startBreakTimer("1", configuration)
+	err := startBreakTimer(gitRunner, "1", configuration)
 
 	assertNoError(t, err)
 	assertOutputContains(t, output, "1 min break timer ends at approx.")
@@ -109,8 +135,9 @@ func TestBreakTimerExportFunction(t *testing.T) {
 	configuration.NotifyCommand = ""
 	configuration.VoiceCommand = ""
 
-	StartBreakTimer("5", configuration)
+	err := startBreakTimer(gitRunner, "5", configuration)
 
+	assertNoError(t, err)
 	assertOutputContains(t, output, "5 min break timer ends at approx.")
 	assertOutputContains(t, output, "So take a break now! :)")
 }
@@ -0,0 +1,60 @@
+package main
+
+import (
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/say"
+	"os"
+)
+
+func undo(runner GitRunner, configuration config.Configuration) error {
+	if err := failIfOperationInProgress(runner, configuration); err != nil {
+		return err
+	}
+	undoPath, err := undoPath(runner)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(undoPath); err != nil {
+		say.Info("nothing to undo")
+		return nil
+	}
+	op, err := readOperation(undoPath)
+	if err != nil {
+		return errReadJournal(err)
+	}
+
+	if err := git(runner, "fetch", configuration.RemoteName, "--prune"); err != nil {
+		return err
+	}
+
+	_, wipBranch := op.branches()
+	if gitRefHash(runner, "refs/remotes/"+wipBranch.remote(configuration).Name) != op.After.RemoteWipHead {
+		return newMobError(ErrUndoNotPossible, "cannot undo 'mob "+op.Command+"'; "+wipBranch.remote(configuration).Name+" was changed by someone else in the meantime")
+	}
+	currentBranch, err := gitCurrentBranch(runner)
+	if err != nil {
+		return err
+	}
+	if !currentBranch.Is(op.After.Branch) {
+		return newMobError(ErrUndoNotPossible, "cannot undo 'mob "+op.Command+"'; you switched from branch '"+op.After.Branch+"' to '"+currentBranch.Name+"' in the meantime",
+			Fix{"To undo anyway, switch back and try again", "git checkout " + op.After.Branch})
+	}
+	if gitCommitHash(runner) != op.After.Head {
+		return newMobError(ErrUndoNotPossible, "cannot undo 'mob "+op.Command+"'; there are new commits on '"+op.After.Branch+"' since then")
+	}
+	workingTree, err := workingTreeState(runner)
+	if err != nil {
+		return err
+	}
+	if workingTree != op.After.WorkingTree {
+		return newMobError(ErrUndoNotPossible, "cannot undo 'mob "+op.Command+"'; your working tree changed since then",
+			Fix{"To undo anyway, put your changes aside and try again", "git stash --include-untracked"})
+	}
+
+	if err := restoreBefore(runner, configuration, op); err != nil {
+		return err
+	}
+	removeJournalFile(undoPath)
+	say.Info("undid 'mob " + op.Command + "', you are on branch '" + op.Before.Branch + "' again")
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestUndoNothingToUndo(t *testing.T) {
+	output, configuration := setup(t)
+
+	assertNoError(t, undo(gitRunner, configuration))
+
+	assertOutputContains(t, output, "nothing to undo")
+}
+
+func TestUndoStart(t *testing.T) {
+	_, configuration := setup(t)
+	start(gitRunner, configuration)
+
+	assertNoError(t, undo(gitRunner, configuration))
+
+	assertOnBranch(t, "master")
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+}
+
+func TestUndoNext(t *testing.T) {
+	_, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+
+	assertNoError(t, undo(gitRunner, configuration))
+
+	assertOnBranch(t, "mob-session")
+	assertCommitsOnBranch(t, 1, "mob-session")
+	assertCommitsOnBranch(t, 1, "origin/mob-session")
+	assertGitStatus(t, GitStatus{
+		"example.txt": "??",
+	})
+}
+
+func TestUndoDone(t *testing.T) {
+	_, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	done(gitRunner, configuration)
+	assertOnBranch(t, "master")
+
+	assertNoError(t, undo(gitRunner, configuration))
+
+	assertOnBranch(t, "mob-session")
+	assertMobSessionBranches(t, configuration, "mob-session")
+	assertCommitsOnBranch(t, 1, "master")
+	assertGitStatus(t, GitStatus{
+		"example.txt": "??",
+	})
+}
+
+func TestUndoResetDeleteRemoteWipBranch(t *testing.T) {
+	_, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+	configuration.ResetDeleteRemoteWipBranch = true
+	reset(gitRunner, configuration)
+	assertNoMobSessionBranches(t, configuration, "mob-session")
+
+	assertNoError(t, undo(gitRunner, configuration))
+
+	assertOnBranch(t, "master")
+	assertMobSessionBranches(t, configuration, "mob-session")
+	assertCommitsOnBranch(t, 2, "origin/mob-session")
+}
+
+func TestUndoOnlyOnce(t *testing.T) {
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+	assertNoError(t, undo(gitRunner, configuration))
+
+	assertNoError(t, undo(gitRunner, configuration))
+
+	assertOutputContains(t, output, "nothing to undo")
+}
+
+func TestUndoFailsIfRemoteWipBranchChangedByOthers(t *testing.T) {
+	_, configuration := setup(t)
+	start(gitRunner, configuration)
+	next(gitRunner, configuration)
+	setWorkingDir(tempDir + "/localother")
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+	setWorkingDir(tempDir + "/local")
+
+	err := undo(gitRunner, configuration)
+
+	assertError(t, err, "cannot undo 'mob next'; origin/mob-session was changed by someone else in the meantime")
+	assertErrorIs(t, err, ErrUndoNotPossible)
+}
+
+func TestUndoFailsIfThereAreNewCommits(t *testing.T) {
+	_, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFileAndCommitIt(t, "example.txt", "contentIrrelevant", "local commit")
+
+	err := undo(gitRunner, configuration)
+
+	assertError(t, err, "cannot undo 'mob start'; there are new commits on 'mob-session' since then")
+	assertErrorIs(t, err, ErrUndoNotPossible)
+	assertOnBranch(t, "mob-session")
+	assertCommitLogContainsMessage(t, "mob-session", "local commit")
+}
+
+func TestUndoFailsIfTheWorkingTreeChanged(t *testing.T) {
+	_, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+
+	err := undo(gitRunner, configuration)
+
+	assertError(t, err, "cannot undo 'mob start'; your working tree changed since then")
+	assertErrorIs(t, err, ErrUndoNotPossible)
+	assertFix(t, err, "git stash --include-untracked")
+	assertOnBranch(t, "mob-session")
+}
+
+func TestUndoAfterALaterCommand(t *testing.T) {
+	output, configuration := setup(t)
+	assertNoError(t, execute(gitRunner, "start", []string{}, configuration))
+	assertNoError(t, execute(gitRunner, "status", []string{}, configuration))
+	assertNoError(t, execute(gitRunner, "reset", []string{}, configuration))
+
+	assertNoError(t, undo(gitRunner, configuration))
+
+	assertOutputContains(t, output, "nothing to undo")
+	assertOnBranch(t, "mob-session")
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"time"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// MOB_NEXT_VERIFY_COMMAND, e.g. "go test ./...", runs before next makes the wip commit, so nobody hands over broken
+// code without knowing. The result goes into the wip commit message and start tells the next typist about it.
+
+const verifyRunPrefix = "verifyRun:"
+
+const (
+	verifyRunPassed  = "passed"
+	verifyRunFailed  = "failed"
+	verifyRunSkipped = "skipped"
+)
+
+// verifySummaryLines is how many of the last output lines a failed verification shows again
+const verifySummaryLines = 5
+
+// confirmHandover asks on the terminal, without a terminal (e.g. in mob serve) the answer is no
+var confirmHandover = func(question string) bool {
+	if !stdinIsTerminal() {
+		return false
+	}
+	say.PrintToConsole(question + " [y/N] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// withVerifyRun runs the verification and returns the configuration with its result for the wip commit message
+func withVerifyRun(runner GitRunner, configuration config.Configuration) (config.Configuration, error) {
+	command := strings.TrimSpace(configuration.NextVerifyCommand)
+	if command == "" {
+		return configuration, nil
+	}
+	nothingToCommit, err := isNothingToCommit(runner)
+	if err != nil {
+		return configuration, err
+	}
+	if nothingToCommit && configuration.NextNote == "" {
+		return configuration, nil
+	}
+	if configuration.NextNoVerifyRun {
+		say.Info("skipped the verification '" + command + "'")
+		configuration.NextVerifyResult = verifyRunSkipped
+		return configuration, nil
+	}
+	if DryRun {
+		say.Indented("run the verification <" + command + ">")
+		return configuration, nil
+	}
+
+	say.Info("verifying with '" + command + "'")
+	rootDir, err := gitRootDir(runner)
+	if err != nil {
+		return configuration, err
+	}
+	started := time.Now()
+	name, args := shellCommand(command)
+	_, output, err := runCommand(rootDir, nil, name, args...)
+	duration := time.Since(started).Round(time.Millisecond * 100).String()
+	if err == nil {
+		say.Info("✅ verification passed in " + duration)
+		configuration.NextVerifyResult = verifyRunPassed
+		return configuration, nil
+	}
+
+	say.Warning("❌ verification failed in " + duration + " (" + err.Error() + ")")
+	say.Indented(lastLines(output, verifySummaryLines))
+	if !confirmHandover("Hand over anyway?") {
+		return configuration, &MobError{Kind: ErrVerificationFailed, Message: "aborted '" + configuration.Mob("next") + "' because the verification failed",
+			Details: []string{"'" + command + "' failed with " + err.Error()},
+			Fixes:   []Fix{{"To hand over anyway, use", configuration.Mob("next --no-verify-run")}}}
+	}
+	configuration.NextVerifyResult = verifyRunFailed
+	return configuration, nil
+}
+
+func lastLines(text string, count int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) > count {
+		lines = lines[len(lines)-count:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func verifyRunCommitMessage(result string) string {
+	if result == "" {
+		return ""
+	}
+	return verifyRunPrefix + result
+}
+
+func verifyRunFromCommitMessage(message string) string {
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, verifyRunPrefix) {
+			return strings.TrimPrefix(line, verifyRunPrefix)
+		}
+	}
+	return ""
+}
+
+// sayVerifyRun tells the next typist whether the tree was green when it was handed over
+func sayVerifyRun(message string) {
+	switch verifyRunFromCommitMessage(message) {
+	case verifyRunPassed:
+		say.Info("✅ the verification passed before the handover")
+	case verifyRunFailed:
+		say.Warning("❌ the verification failed before the handover, the previous typist handed over anyway")
+	case verifyRunSkipped:
+		say.Info("the previous typist skipped the verification before the handover")
+	}
+}
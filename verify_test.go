@@ -0,0 +1,113 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestNextWithPassingVerification(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the verification in the tests uses sh")
+	}
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	configuration.NextVerifyCommand = "echo all tests passed"
+
+	err := next(gitRunner, configuration)
+
+	assertNoError(t, err)
+	assertOutputContains(t, output, "verifying with 'echo all tests passed'")
+	assertOutputContains(t, output, "  all tests passed")
+	assertOutputContains(t, output, "✅ verification passed in ")
+	assertCommitMessageContains(t, "origin/mob-session", "verifyRun:passed")
+
+	setWorkingDir(tempDir + "/localother")
+	*output = ""
+	start(gitRunner, configuration)
+
+	assertOutputContains(t, output, "✅ the verification passed before the handover")
+}
+
+func TestNextWithFailingVerificationAborts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the verification in the tests uses sh")
+	}
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	configuration.NextVerifyCommand = "echo 1 test failed; exit 1"
+	mockConfirmHandover(t, false)
+
+	err := next(gitRunner, configuration)
+
+	assertErrorIs(t, err, ErrVerificationFailed)
+	equals(t, 10, exitCode(err))
+	assertFix(t, err, "mob next --no-verify-run")
+	assertOutputContains(t, output, "❌ verification failed in ")
+	assertOnBranch(t, "mob-session")
+	assertGitStatus(t, GitStatus{"example.txt": "??"})
+	equals(t, false, hasRemoteCommit(t, "origin/mob-session", "example.txt"))
+}
+
+func TestNextWithFailingVerificationHandsOverAnyway(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the verification in the tests uses sh")
+	}
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	configuration.NextVerifyCommand = "exit 1"
+	mockConfirmHandover(t, true)
+
+	err := next(gitRunner, configuration)
+
+	assertNoError(t, err)
+	assertCommitMessageContains(t, "origin/mob-session", "verifyRun:failed")
+
+	setWorkingDir(tempDir + "/localother")
+	*output = ""
+	start(gitRunner, configuration)
+
+	assertOutputContains(t, output, "❌ the verification failed before the handover")
+}
+
+func TestNextWithoutVerifyRun(t *testing.T) {
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+	createFile(t, "example.txt", "contentIrrelevant")
+	configuration.NextVerifyCommand = "exit 1"
+	configuration.NextNoVerifyRun = true
+
+	err := next(gitRunner, configuration)
+
+	assertNoError(t, err)
+	assertOutputContains(t, output, "skipped the verification 'exit 1'")
+	assertCommitMessageContains(t, "origin/mob-session", "verifyRun:skipped")
+}
+
+func TestNextWithoutChangesDoesNotVerify(t *testing.T) {
+	output, configuration := setup(t)
+	start(gitRunner, configuration)
+	configuration.NextVerifyCommand = "exit 1"
+
+	err := next(gitRunner, configuration)
+
+	assertNoError(t, err)
+	assertOutputNotContains(t, output, "verifying")
+}
+
+func TestLastLines(t *testing.T) {
+	equals(t, "c\nd", lastLines("a\nb\nc\nd\n", 2))
+	equals(t, "a", lastLines("a", 2))
+}
+
+func mockConfirmHandover(t *testing.T, answer bool) {
+	originalConfirmHandover := confirmHandover
+	confirmHandover = func(string) bool { return answer }
+	t.Cleanup(func() { confirmHandover = originalConfirmHandover })
+}
+
+func hasRemoteCommit(t *testing.T, branch string, file string) bool {
+	return gitOutput(t, "log", "--pretty=format:%H", branch, "--", file) != ""
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"time"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+var (
+	waitInterval = 10 * time.Second
+	waitSleep    = time.Sleep
+)
+
+// wait returns as soon as someone else pushed to the remote wip branch and you are (probably) the next typist
+func wait(runner GitRunner, configuration config.Configuration) error {
+	if DryRun { // the fetch is skipped in a dry run, so it would never see the handover
+		return newMobError(ErrInvalidArgument, "mob wait doesn't support --dry-run")
+	}
+	if err := failIfOperationInProgress(runner, configuration); err != nil {
+		return err
+	}
+
+	currentBaseBranch, currentWipBranch, err := currentBranches(runner, configuration)
+	if err != nil {
+		return err
+	}
+	remoteBaseBranch := currentBaseBranch.remote(configuration)
+	remoteWipBranch := currentWipBranch.remote(configuration)
+	gitUserName := gitUserName(runner)
+
+	if _, err := silentgitChange(runner, "fetch", configuration.RemoteName, "--prune"); err != nil {
+		return err
+	}
+	lastHead := gitRefHash(runner, remoteWipBranch.Name)
+	say.Info("waiting for the handover on " + remoteWipBranch.Name + ", checking every " + waitInterval.String())
+
+	for {
+		waitSleep(waitInterval)
+		if _, err := silentgitChange(runner, "fetch", configuration.RemoteName, "--prune"); err != nil {
+			return err
+		}
+		head := gitRefHash(runner, remoteWipBranch.Name)
+		if head == lastHead {
+			continue
+		}
+		lastHead = head
+		if head == "" {
+			continue
+		}
+
+		author, err := silentgit(runner, "log", "-1", "--pretty=format:%an", head)
+		if err != nil {
+			return err
+		}
+		if author == gitUserName {
+			continue
+		}
+		nextTypist, _, err := determineNextTypist(runner, remoteBaseBranch, remoteWipBranch, gitUserName)
+		if err != nil {
+			return err
+		}
+		if nextTypist != "" && nextTypist != gitUserName {
+			say.Info(author + " pushed to " + remoteWipBranch.Name + ", ***" + nextTypist + "*** is (probably) next")
+			continue
+		}
+
+		say.Info(author + " pushed to " + remoteWipBranch.Name + ", it's your turn!")
+		voiceCommand, err := getVoiceCommand(runner, "mob start", configuration)
+		if err != nil {
+			return err
+		}
+		notifyCommand, err := getNotifyCommand(runner, "mob start", !configuration.WaitStart, configuration)
+		if err != nil {
+			return err
+		}
+		if err := executeCommandsInBackgroundProcess(runner, voiceCommand, notifyCommand); err != nil {
+			say.Warning("could not notify you: " + err.Error())
+		}
+		return nil
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"testing"
+	"time"
+)
+
+func TestWaitReturnsWhenItIsYourTurn(t *testing.T) {
+	output, configuration := setup(t)
+	handOver(t, configuration, "local", "local.txt")
+	sleeps := mockWaitSleep(t, func(call int) {
+		if call == 1 {
+			handOver(t, configuration, "localother", "localother.txt")
+		}
+	})
+
+	assertNoError(t, wait(gitRunner, configuration))
+
+	equals(t, 1, *sleeps)
+	assertOutputContains(t, output, "localother pushed to origin/mob-session, it's your turn!")
+	assertOnBranch(t, "master")
+}
+
+func TestWaitIgnoresOwnPushes(t *testing.T) {
+	output, configuration := setup(t)
+	sleeps := mockWaitSleep(t, func(call int) {
+		switch call {
+		case 1:
+			handOver(t, configuration, "local", "local.txt")
+		case 2:
+			handOver(t, configuration, "localother", "localother.txt")
+		}
+	})
+
+	assertNoError(t, wait(gitRunner, configuration))
+
+	equals(t, 2, *sleeps)
+	assertOutputNotContains(t, output, "local pushed to origin/mob-session")
+	assertOutputContains(t, output, "localother pushed to origin/mob-session, it's your turn!")
+}
+
+func TestWaitDoesNotSupportDryRun(t *testing.T) {
+	_, configuration := setup(t)
+	enableDryRun(t)
+
+	err := wait(gitRunner, configuration)
+
+	assertErrorIs(t, err, ErrInvalidArgument)
+}
+
+func TestWaitKeepsWaitingIfSomeoneElseIsNext(t *testing.T) {
+	output, configuration := setup(t)
+	handOver(t, configuration, "local", "local1.txt")
+	handOver(t, configuration, "alice", "alice1.txt")
+	handOver(t, configuration, "bob", "bob1.txt")
+	handOver(t, configuration, "local", "local2.txt")
+	sleeps := mockWaitSleep(t, func(call int) {
+		switch call {
+		case 1:
+			handOver(t, configuration, "alice", "alice2.txt")
+		case 3:
+			handOver(t, configuration, "bob", "bob2.txt")
+		}
+	})
+
+	assertNoError(t, wait(gitRunner, configuration))
+
+	equals(t, 3, *sleeps)
+	assertOutputContains(t, output, "alice pushed to origin/mob-session, ***bob*** is (probably) next")
+	assertOutputContains(t, output, "bob pushed to origin/mob-session, it's your turn!")
+}
+
+func TestWaitStart(t *testing.T) {
+	output, configuration := setup(t)
+	configuration.WaitStart = true
+	mockWaitSleep(t, func(call int) {
+		handOver(t, configuration, "localother", "localother.txt")
+	})
+
+	assertNoError(t, execute(gitRunner, "wait", []string{}, configuration))
+
+	assertOnBranch(t, "mob-session")
+	assertOutputContains(t, output, "it's your turn!")
+	assertOutputContains(t, output, "joining existing session from origin/mob-session")
+}
+
+func handOver(t *testing.T, configuration config.Configuration, clone string, file string) {
+	currentDir := gitRunner.Dir()
+	setWorkingDir(tempDir + "/" + clone)
+	assertNoError(t, start(gitRunner, configuration))
+	createFile(t, file, "contentIrrelevant")
+	assertNoError(t, next(gitRunner, configuration))
+	setWorkingDir(currentDir)
+}
+
+func mockWaitSleep(t *testing.T, action func(call int)) *int {
+	calls := 0
+	originalWaitSleep := waitSleep
+	waitSleep = func(time.Duration) {
+		calls++
+		action(calls)
+	}
+	t.Cleanup(func() { waitSleep = originalWaitSleep })
+	return &calls
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+	"github.com/remotemobprogramming/mob/v5/httpclient"
+	"github.com/remotemobprogramming/mob/v5/say"
+)
+
+// With MOB_WEBHOOK_URL, start, next, done and the end of the local timer post an event to a chat like Slack, Teams or
+// Mattermost, so the remote part of the mob sees "alice handed over, bob is (probably) next" without anyone typing it.
+// MOB_WEBHOOK_FORMAT chooses the payload: the full event as JSON, or a message for slack, mattermost or teams.
+
+const (
+	webhookFormatEvent      = "event"
+	webhookFormatSlack      = "slack"
+	webhookFormatMattermost = "mattermost"
+	webhookFormatTeams      = "teams"
+)
+
+var webhookEvents = []string{"start", "next", "done", "timer"}
+
+type webhookEvent struct {
+	Event      string `json:"event"`
+	Message    string `json:"message"`
+	Repository string `json:"repository"`
+	User       string `json:"user"`
+	NextTypist string `json:"nextTypist,omitempty"`
+	BaseBranch string `json:"baseBranch,omitempty"`
+	WipBranch  string `json:"wipBranch,omitempty"`
+	Commit     string `json:"commit,omitempty"`
+	Time       string `json:"time"`
+}
+
+func newWebhookEvent(runner GitRunner, name string, event hookEvent) webhookEvent {
+	repository := ""
+	if rootDir, err := gitRootDir(runner); err == nil {
+		repository = filepath.Base(rootDir)
+	}
+	user := event.User
+	if user == "" {
+		user = "someone"
+	}
+	return webhookEvent{
+		Event:      name,
+		Message:    webhookMessage(name, user, event),
+		Repository: repository,
+		User:       event.User,
+		NextTypist: event.NextTypist,
+		BaseBranch: event.BaseBranch.Name,
+		WipBranch:  event.WipBranch.Name,
+		Commit:     event.Commit,
+		Time:       time.Now().Format(time.RFC3339),
+	}
+}
+
+func webhookMessage(name string, user string, event hookEvent) string {
+	switch name {
+	case "start":
+		return user + " started typing on " + event.WipBranch.Name
+	case "next":
+		if event.NextTypist != "" {
+			return user + " handed over on " + event.WipBranch.Name + ", " + event.NextTypist + " is (probably) next"
+		}
+		return user + " handed over on " + event.WipBranch.Name
+	case "done":
+		return user + " finished " + event.WipBranch.Name + " into " + event.BaseBranch.Name
+	case "timer":
+		return "the timer of " + user + " ended"
+	}
+	return user + ": " + name
+}
+
+func webhookPayload(event webhookEvent, format string) ([]byte, error) {
+	switch format {
+	case webhookFormatSlack, webhookFormatMattermost:
+		return json.Marshal(map[string]string{"text": event.Message})
+	case webhookFormatTeams:
+		return json.Marshal(map[string]string{
+			"@type":    "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"summary":  event.Message,
+			"text":     event.Message,
+		})
+	case webhookFormatEvent, "":
+		return json.Marshal(event)
+	}
+	return nil, newMobError(ErrInvalidArgument, "unknown MOB_WEBHOOK_FORMAT '"+format+"', use event, slack, mattermost or teams")
+}
+
+// sendWebhook posts the event, a failing webhook only warns because the command already did its job
+func sendWebhook(runner GitRunner, name string, event hookEvent, configuration config.Configuration) {
+	if configuration.WebhookUrl == "" || !contains(webhookEvents, name) {
+		return
+	}
+	payload, err := webhookPayload(newWebhookEvent(runner, name, event), configuration.WebhookFormat)
+	if err != nil {
+		say.Warning(err.Error())
+		return
+	}
+	if DryRun {
+		say.Indented("post " + string(payload) + " to MOB_WEBHOOK_URL")
+		return
+	}
+	client := httpclient.CreateHttpClient(false).Silent()
+	if _, err := client.SendRequest(payload, "POST", configuration.WebhookUrl); err != nil {
+		// the url is a secret, like a token
+		say.Warning("the webhook for " + name + " failed: " + strings.ReplaceAll(err.Error(), configuration.WebhookUrl, "MOB_WEBHOOK_URL"))
+	}
+}
+
+// webhook sends the webhook of an event, the local timer runs 'mob webhook timer' when it ends
+func webhook(runner GitRunner, parameter []string, configuration config.Configuration) error {
+	if len(parameter) != 1 || !contains(webhookEvents, parameter[0]) {
+		return newMobError(ErrInvalidArgument, "use "+configuration.Mob("webhook <"+strings.Join(webhookEvents, "|")+">"))
+	}
+	event, err := newHookEvent(runner, parameter[0], configuration)
+	if err != nil {
+		return err
+	}
+	sendWebhook(runner, parameter[0], event, configuration)
+	return nil
+}
+
+// timerWebhookCommand returns the command that sends the timer webhook as one of the background commands of the
+// local timer, or "" if there is no webhook
+func timerWebhookCommand(configuration config.Configuration) string {
+	if configuration.WebhookUrl == "" {
+		return ""
+	}
+	if runtime.GOOS == "windows" {
+		return configuration.Mob("webhook timer")
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		say.Warning("the timer can't send the webhook: " + err.Error())
+		return ""
+	}
+	return escapeForShell(executable, 0) + " webhook timer"
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	config "github.com/remotemobprogramming/mob/v5/configuration"
+)
+
+func TestNextPostsWebhookEvent(t *testing.T) {
+	output, configuration := setup(t)
+	setWorkingDir(tempDir + "/alice")
+	start(gitRunner, configuration)
+	createFile(t, "alice.txt", "contentIrrelevant")
+	next(gitRunner, configuration)
+	setWorkingDir(tempDir + "/local")
+	start(gitRunner, configuration)
+	createFile(t, "local.txt", "contentIrrelevant")
+	requests := webhookServer(t, &configuration)
+
+	err := execute(gitRunner, "next", []string{}, configuration)
+
+	assertNoError(t, err)
+	equals(t, 1, len(*requests))
+	var event webhookEvent
+	if err := json.Unmarshal([]byte((*requests)[0]), &event); err != nil {
+		t.Fatal(err)
+	}
+	equals(t, "next", event.Event)
+	equals(t, "local handed over on mob-session, alice is (probably) next", event.Message)
+	equals(t, "local", event.Repository)
+	equals(t, "master", event.BaseBranch)
+	equals(t, "mob-session", event.WipBranch)
+	equals(t, gitRefHash(gitRunner, "origin/mob-session"), event.Commit)
+	assertOutputNotContains(t, output, configuration.WebhookUrl)
+}
+
+func TestStartAndDonePostSlackMessages(t *testing.T) {
+	_, configuration := setup(t)
+	requests := webhookServer(t, &configuration)
+	configuration.WebhookFormat = "slack"
+
+	assertNoError(t, execute(gitRunner, "start", []string{}, configuration))
+	assertNoError(t, execute(gitRunner, "done", []string{}, configuration))
+
+	equals(t, []string{
+		`{"text":"local started typing on mob-session"}`,
+		`{"text":"local finished mob-session into master"}`,
+	}, *requests)
+}
+
+func TestTimerWebhook(t *testing.T) {
+	_, configuration := setup(t)
+	requests := webhookServer(t, &configuration)
+	configuration.WebhookFormat = "mattermost"
+
+	err := execute(gitRunner, "webhook", []string{"timer"}, configuration)
+
+	assertNoError(t, err)
+	equals(t, []string{`{"text":"the timer of local ended"}`}, *requests)
+}
+
+func TestWebhookWithUnknownEvent(t *testing.T) {
+	_, configuration := setup(t)
+	requests := webhookServer(t, &configuration)
+
+	err := execute(gitRunner, "webhook", []string{"reset"}, configuration)
+
+	assertErrorIs(t, err, ErrInvalidArgument)
+	equals(t, 0, len(*requests))
+}
+
+func TestFailingWebhookOnlyWarnsWithoutTheUrl(t *testing.T) {
+	output, configuration := setup(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	configuration.WebhookUrl = server.URL + "/secret-token"
+
+	err := execute(gitRunner, "start", []string{}, configuration)
+
+	assertNoError(t, err)
+	assertOnBranch(t, "mob-session")
+	assertOutputContains(t, output, "the webhook for start failed: got an error from the server: MOB_WEBHOOK_URL 403 Forbidden")
+	assertOutputNotContains(t, output, "secret-token")
+}
+
+func TestWebhookPayloadForTeams(t *testing.T) {
+	payload, err := webhookPayload(webhookEvent{Message: "alice handed over"}, "teams")
+
+	assertNoError(t, err)
+	equals(t, `{"@context":"https://schema.org/extensions","@type":"MessageCard","summary":"alice handed over","text":"alice handed over"}`, string(payload))
+}
+
+func TestWebhookPayloadWithUnknownFormat(t *testing.T) {
+	_, err := webhookPayload(webhookEvent{}, "irc")
+
+	assertErrorIs(t, err, ErrInvalidArgument)
+}
+
+func TestTimerWebhookCommand(t *testing.T) {
+	_, configuration := setup(t)
+	equals(t, "", timerWebhookCommand(configuration))
+
+	configuration.WebhookUrl = "https://chat.example.com/hooks/secret"
+	command := timerWebhookCommand(configuration)
+
+	assertOutputContains(t, &command, " webhook timer")
+}
+
+func webhookServer(t *testing.T, configuration *config.Configuration) *[]string {
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, string(body))
+	}))
+	t.Cleanup(server.Close)
+	configuration.WebhookUrl = server.URL + "/hooks/secret"
+	return &requests
+}